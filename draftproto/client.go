@@ -0,0 +1,295 @@
+package draftproto
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Client is a headless draft client for bots, load simulations and integration tests.
+type Client struct {
+	// BaseURL is the server origin, e.g. "http://localhost:8080".
+	BaseURL string
+	// DeviceID identifies the client for room ownership.
+	DeviceID string
+	// HTTPClient is used for room requests; http.DefaultClient when nil.
+	HTTPClient *http.Client
+	// Dialer is used for websocket connections; websocket.DefaultDialer when nil.
+	Dialer *websocket.Dialer
+}
+
+// NewClient returns a client for the server at baseURL.
+func NewClient(baseURL, deviceID string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/"), DeviceID: deviceID}
+}
+
+// CreateRoom creates a draft room owned by the client's device.
+func (c *Client) CreateRoom(ctx context.Context, req CreateRoomRequest) (CreateRoomResponse, error) {
+	return c.postRoom(ctx, "/api/draft/rooms", req)
+}
+
+// StartOrJoinSharedRoom creates the shared room, or returns it when it already exists.
+func (c *Client) StartOrJoinSharedRoom(ctx context.Context, req CreateRoomRequest) (CreateRoomResponse, error) {
+	return c.postRoom(ctx, "/api/draft/shared", req)
+}
+
+func (c *Client) postRoom(ctx context.Context, path string, req CreateRoomRequest) (CreateRoomResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return CreateRoomResponse{}, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return CreateRoomResponse{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-Device-ID", c.DeviceID)
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return CreateRoomResponse{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return CreateRoomResponse{}, fmt.Errorf("create room: %s: %s", resp.Status, strings.TrimSpace(string(text)))
+	}
+	var out CreateRoomResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return CreateRoomResponse{}, fmt.Errorf("decode create room response: %w", err)
+	}
+	return out, nil
+}
+
+// Join connects to a seat in a room and completes the version handshake.
+// The returned session already holds the seat's initial state.
+func (c *Client) Join(ctx context.Context, roomID string, seat int) (*Session, error) {
+	wsURL, err := url.Parse(c.BaseURL + "/api/draft/ws")
+	if err != nil {
+		return nil, err
+	}
+	switch wsURL.Scheme {
+	case "http":
+		wsURL.Scheme = "ws"
+	case "https":
+		wsURL.Scheme = "wss"
+	}
+	q := wsURL.Query()
+	q.Set("room", roomID)
+	q.Set("seat", strconv.Itoa(seat))
+	wsURL.RawQuery = q.Encode()
+
+	dialer := c.Dialer
+	if dialer == nil {
+		dialer = websocket.DefaultDialer
+	}
+	conn, _, err := dialer.DialContext(ctx, wsURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Session{conn: conn}
+	// The server sends the seat state as soon as the seat is accepted.
+	if _, err := s.await(ctx, func(m Typed) bool {
+		_, ok := m.(State)
+		return ok
+	}); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if err := s.send(Hello{ProtocolVersion: ProtocolVersion}); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	reply, err := s.await(ctx, func(m Typed) bool {
+		_, ok := m.(Hello)
+		return ok
+	})
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	s.serverVersion = reply.(Hello).ProtocolVersion
+	return s, nil
+}
+
+// Session is one seat's websocket connection. A Session is not safe for concurrent use.
+type Session struct {
+	conn          *websocket.Conn
+	state         PlayerState
+	serverVersion int
+}
+
+// State returns the most recent seat state received from the server.
+func (s *Session) State() PlayerState {
+	return s.state
+}
+
+// ServerProtocolVersion returns the version the server announced in the handshake.
+func (s *Session) ServerProtocolVersion() int {
+	return s.serverVersion
+}
+
+// Close closes the websocket connection.
+func (s *Session) Close() error {
+	return s.conn.Close()
+}
+
+// Resync asks the server for a fresh seat state.
+func (s *Session) Resync(ctx context.Context) (PlayerState, error) {
+	if err := s.send(StateRequest{}); err != nil {
+		return PlayerState{}, err
+	}
+	if _, err := s.await(ctx, func(m Typed) bool {
+		_, ok := m.(State)
+		return ok
+	}); err != nil {
+		return PlayerState{}, err
+	}
+	return s.state, nil
+}
+
+// Pick takes cards from the seat's active pack using the next sequence number.
+func (s *Session) Pick(ctx context.Context, picks ...PickSelection) (PlayerState, error) {
+	if s.state.Active == nil {
+		return PlayerState{}, &Error{Code: CodeRejected, Message: "no active pack"}
+	}
+	return s.mutate(ctx, Pick{Seq: s.state.NextSeq, PackID: s.state.Active.PackID, Picks: picks}, TypePickAccepted)
+}
+
+// MovePick moves a picked card between the mainboard and sideboard.
+func (s *Session) MovePick(ctx context.Context, cardName, fromZone, toZone string) (PlayerState, error) {
+	return s.mutate(ctx, MovePick{Seq: s.state.NextSeq, CardName: cardName, FromZone: fromZone, ToZone: toZone}, TypeMoveAccepted)
+}
+
+// SetBasics replaces the seat's basic land counts.
+func (s *Session) SetBasics(ctx context.Context, basics map[string]int) (PlayerState, error) {
+	return s.mutate(ctx, SetBasics{Seq: s.state.NextSeq, Basics: basics}, TypeSetBasicsAccepted)
+}
+
+// RetractPick takes back the seat's pick from its active pack. The server
+// follows the acceptance with a PickRetracted message, which Next returns.
+func (s *Session) RetractPick(ctx context.Context) (PlayerState, error) {
+	if s.state.Active == nil {
+		return PlayerState{}, &Error{Code: CodeRejected, Message: "no active pack"}
+	}
+	return s.mutate(ctx, RetractPick{Seq: s.state.NextSeq, PackID: s.state.Active.PackID}, TypeRetractAccepted)
+}
+
+// SuggestBasics asks the server for a basic land split for the seat's mainboard.
+func (s *Session) SuggestBasics(ctx context.Context) (map[string]int, error) {
+	if err := s.send(SuggestBasicsRequest{}); err != nil {
+		return nil, err
+	}
+	m, err := s.await(ctx, func(m Typed) bool {
+		_, ok := m.(BasicsSuggested)
+		return ok
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m.(BasicsSuggested).Basics, nil
+}
+
+// RegisterDeck locks in the seat's final mainboard.
+func (s *Session) RegisterDeck(ctx context.Context, cards []string) (PlayerState, error) {
+	return s.mutate(ctx, RegisterDeck{Seq: s.state.NextSeq, Cards: cards}, TypeRegisterDeckAccepted)
+}
+
+// OverlayToken fetches the token that authorizes this seat's stream overlay feed.
+func (s *Session) OverlayToken(ctx context.Context) (string, error) {
+	if err := s.send(OverlayTokenRequest{}); err != nil {
+		return "", err
+	}
+	m, err := s.await(ctx, func(m Typed) bool {
+		_, ok := m.(OverlayToken)
+		return ok
+	})
+	if err != nil {
+		return "", err
+	}
+	return m.(OverlayToken).Token, nil
+}
+
+// Next blocks for the next message from the server, keeping State current.
+// Bots use it to wait for round_advanced or draft_completed between picks.
+// Once ctx is cancelled the session cannot read again and should be closed.
+func (s *Session) Next(ctx context.Context) (Typed, error) {
+	return s.await(ctx, func(Typed) bool { return true })
+}
+
+func (s *Session) mutate(ctx context.Context, m Typed, ackType string) (PlayerState, error) {
+	if err := s.send(m); err != nil {
+		return PlayerState{}, err
+	}
+	if _, err := s.await(ctx, func(m Typed) bool {
+		ack, ok := m.(Accepted)
+		return ok && ack.Type == ackType
+	}); err != nil {
+		return PlayerState{}, err
+	}
+	return s.state, nil
+}
+
+func (s *Session) send(m Typed) error {
+	return s.conn.WriteJSON(m.Message())
+}
+
+// await reads messages until match returns true. Error messages end the wait.
+// Cancelling ctx interrupts a blocked read by expiring the read deadline, which
+// leaves the connection unusable, so the session must then be closed.
+func (s *Session) await(ctx context.Context, match func(Typed) bool) (Typed, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Time{}
+	}
+	if err := s.conn.SetReadDeadline(deadline); err != nil {
+		return nil, err
+	}
+	stop := context.AfterFunc(ctx, func() {
+		_ = s.conn.SetReadDeadline(time.Now())
+	})
+	defer stop()
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var raw Message
+		if err := s.conn.ReadJSON(&raw); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			return nil, err
+		}
+		m, err := Decode(raw)
+		if err != nil {
+			// Skip message types newer than this client.
+			continue
+		}
+		switch typed := m.(type) {
+		case State:
+			s.state = typed.State
+		case Accepted:
+			s.state = typed.State
+		case ErrorMessage:
+			protoErr := typed.Err
+			return nil, &protoErr
+		}
+		if match(m) {
+			return m, nil
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package draftproto
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode is a stable, machine-readable reason carried on error messages.
+// Error text may change between releases; codes do not.
+type ErrorCode string
+
+const (
+	CodeInvalidMessage      ErrorCode = "invalid_message"
+	CodeUnsupportedProtocol ErrorCode = "unsupported_protocol"
+	CodeRoomMissing         ErrorCode = "room_missing"
+	CodeSeatOccupied        ErrorCode = "seat_occupied"
+	CodeInvalidSeat         ErrorCode = "invalid_seat"
+	CodeInvalidSeq          ErrorCode = "invalid_seq"
+	CodeStaleSeq            ErrorCode = "stale_seq"
+	CodeSeqGap              ErrorCode = "seq_gap"
+	CodePackMismatch        ErrorCode = "pack_mismatch"
+	CodeAlreadyPicked       ErrorCode = "already_picked"
+	CodeDraftComplete       ErrorCode = "draft_complete"
+	CodeDraftNotComplete    ErrorCode = "draft_not_complete"
+	CodeDraftNotStarted     ErrorCode = "draft_not_started"
+	CodeDeckLocked          ErrorCode = "deck_locked"
+	// CodeRejected covers any other invalid command, e.g. a card not in the pack.
+	CodeRejected ErrorCode = "rejected"
+)
+
+// Error is a server-reported protocol error.
+type Error struct {
+	Code    ErrorCode
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return string(e.Code)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// IsCode reports whether err is a protocol *Error with the given code.
+func IsCode(err error, code ErrorCode) bool {
+	var protoErr *Error
+	return errors.As(err, &protoErr) && protoErr.Code == code
+}
@@ -0,0 +1,246 @@
+package draftproto
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Typed is implemented by every typed protocol message.
+type Typed interface {
+	// Message converts the typed message to its wire envelope.
+	Message() Message
+}
+
+// Hello opens the version handshake; the server answers with its own Hello.
+type Hello struct {
+	ProtocolVersion int
+}
+
+// StateRequest asks the server to resend the seat's state.
+type StateRequest struct{}
+
+// Pick takes one or more cards from the active pack.
+type Pick struct {
+	Seq    uint64
+	PackID string
+	Picks  []PickSelection
+}
+
+// MovePick moves a picked card between zones.
+type MovePick struct {
+	Seq      uint64
+	CardName string
+	FromZone string
+	ToZone   string
+}
+
+// SetBasics replaces the seat's basic land counts.
+type SetBasics struct {
+	Seq    uint64
+	Basics map[string]int
+}
+
+// RetractPick undoes the seat's pick for the current pass.
+type RetractPick struct {
+	Seq    uint64
+	PackID string
+}
+
+// RegisterDeck locks in the seat's final mainboard after the draft.
+type RegisterDeck struct {
+	Seq   uint64
+	Cards []string
+}
+
+// SuggestBasicsRequest asks for a basic land split for the current mainboard.
+type SuggestBasicsRequest struct{}
+
+// BotPick asks the server to pick randomly for every unoccupied seat.
+type BotPick struct{}
+
+// OverlayTokenRequest asks for the token that authorizes the seat's stream overlay.
+type OverlayTokenRequest struct{}
+
+// State carries a seat-local snapshot, either on request or after a round advances.
+type State struct {
+	State PlayerState
+}
+
+// Accepted acknowledges a seat mutation. Type is one of the *Accepted message types.
+type Accepted struct {
+	Type      string
+	State     PlayerState
+	Duplicate bool
+}
+
+// BasicsSuggested is the server's basic land suggestion.
+type BasicsSuggested struct {
+	Basics map[string]int
+}
+
+// RoundAdvanced is broadcast when every seat has picked.
+type RoundAdvanced struct {
+	PackNo int
+	PickNo int
+}
+
+// PickRetracted is sent to a seat when it takes back its pick.
+type PickRetracted struct {
+	PackID string
+	Cards  []string
+}
+
+// OverlayToken is the seat's stream overlay token, sent only to that seat.
+type OverlayToken struct {
+	Token string
+}
+
+// DraftCompleted is broadcast once the last pick is made.
+type DraftCompleted struct{}
+
+// ErrorMessage reports a rejected command or connection. Type is TypeError,
+// TypeRoomMissing or TypeSeatOccupied.
+type ErrorMessage struct {
+	Type     string
+	Err      Error
+	Redirect string
+}
+
+func (m Hello) Message() Message {
+	return Message{Type: TypeHello, ProtocolVersion: m.ProtocolVersion}
+}
+
+func (StateRequest) Message() Message { return Message{Type: TypeState} }
+
+func (m Pick) Message() Message {
+	return Message{Type: TypePick, Seq: m.Seq, PackID: m.PackID, Picks: m.Picks}
+}
+
+func (m MovePick) Message() Message {
+	return Message{Type: TypeMovePick, Seq: m.Seq, CardName: m.CardName, FromZone: m.FromZone, ToZone: m.ToZone}
+}
+
+func (m SetBasics) Message() Message {
+	return Message{Type: TypeSetBasics, Seq: m.Seq, Basics: m.Basics}
+}
+
+func (m RetractPick) Message() Message {
+	return Message{Type: TypeRetractPick, Seq: m.Seq, PackID: m.PackID}
+}
+
+func (m RegisterDeck) Message() Message {
+	return Message{Type: TypeRegisterDeck, Seq: m.Seq, Cards: m.Cards}
+}
+
+func (SuggestBasicsRequest) Message() Message { return Message{Type: TypeSuggestBasics} }
+
+func (BotPick) Message() Message { return Message{Type: TypeBotPick} }
+
+func (OverlayTokenRequest) Message() Message { return Message{Type: TypeOverlayToken} }
+
+func (m OverlayToken) Message() Message {
+	return Message{Type: TypeOverlayToken, Token: m.Token}
+}
+
+func (m State) Message() Message {
+	state := m.State
+	return Message{Type: TypeState, State: &state}
+}
+
+func (m Accepted) Message() Message {
+	state := m.State
+	return Message{Type: m.Type, State: &state, Duplicate: m.Duplicate}
+}
+
+func (m BasicsSuggested) Message() Message {
+	return Message{Type: TypeBasicsSuggested, Basics: m.Basics}
+}
+
+func (m RoundAdvanced) Message() Message {
+	return Message{Type: TypeRoundAdvanced, PackNo: m.PackNo, PickNo: m.PickNo}
+}
+
+func (m PickRetracted) Message() Message {
+	return Message{Type: TypePickRetracted, PackID: m.PackID, Cards: m.Cards}
+}
+
+func (DraftCompleted) Message() Message { return Message{Type: TypeDraftCompleted} }
+
+func (m ErrorMessage) Message() Message {
+	msgType := m.Type
+	if msgType == "" {
+		msgType = TypeError
+	}
+	return Message{Type: msgType, Error: m.Err.Message, Code: m.Err.Code, Redirect: m.Redirect}
+}
+
+// Encode marshals a typed message to JSON.
+func Encode(m Typed) ([]byte, error) {
+	return json.Marshal(m.Message())
+}
+
+// Decode converts a wire envelope to its typed message.
+// States are required on state and acknowledgement messages.
+func Decode(msg Message) (Typed, error) {
+	switch msg.Type {
+	case TypeHello:
+		return Hello{ProtocolVersion: msg.ProtocolVersion}, nil
+	case TypeState:
+		if msg.State == nil {
+			// A state frame without a snapshot is the client's resync request.
+			return StateRequest{}, nil
+		}
+		return State{State: *msg.State}, nil
+	case TypePick:
+		return Pick{Seq: msg.Seq, PackID: msg.PackID, Picks: msg.Picks}, nil
+	case TypeMovePick:
+		return MovePick{Seq: msg.Seq, CardName: msg.CardName, FromZone: msg.FromZone, ToZone: msg.ToZone}, nil
+	case TypeSetBasics:
+		return SetBasics{Seq: msg.Seq, Basics: msg.Basics}, nil
+	case TypeRetractPick:
+		return RetractPick{Seq: msg.Seq, PackID: msg.PackID}, nil
+	case TypeRegisterDeck:
+		return RegisterDeck{Seq: msg.Seq, Cards: msg.Cards}, nil
+	case TypeSuggestBasics:
+		return SuggestBasicsRequest{}, nil
+	case TypeBotPick:
+		return BotPick{}, nil
+	case TypeOverlayToken:
+		if msg.Token == "" {
+			return OverlayTokenRequest{}, nil
+		}
+		return OverlayToken{Token: msg.Token}, nil
+	case TypePickAccepted, TypeMoveAccepted, TypeSetBasicsAccepted, TypeRetractAccepted, TypeRegisterDeckAccepted:
+		if msg.State == nil {
+			return nil, fmt.Errorf("%s message missing state", msg.Type)
+		}
+		return Accepted{Type: msg.Type, State: *msg.State, Duplicate: msg.Duplicate}, nil
+	case TypeBasicsSuggested:
+		return BasicsSuggested{Basics: msg.Basics}, nil
+	case TypeRoundAdvanced:
+		return RoundAdvanced{PackNo: msg.PackNo, PickNo: msg.PickNo}, nil
+	case TypePickRetracted:
+		return PickRetracted{PackID: msg.PackID, Cards: msg.Cards}, nil
+	case TypeDraftCompleted:
+		return DraftCompleted{}, nil
+	case TypeError, TypeRoomMissing, TypeSeatOccupied:
+		code := msg.Code
+		if code == "" {
+			code = defaultErrorCode(msg.Type)
+		}
+		return ErrorMessage{Type: msg.Type, Err: Error{Code: code, Message: msg.Error}, Redirect: msg.Redirect}, nil
+	default:
+		return nil, fmt.Errorf("unknown message type %q", msg.Type)
+	}
+}
+
+func defaultErrorCode(msgType string) ErrorCode {
+	switch msgType {
+	case TypeRoomMissing:
+		return CodeRoomMissing
+	case TypeSeatOccupied:
+		return CodeSeatOccupied
+	default:
+		return CodeRejected
+	}
+}
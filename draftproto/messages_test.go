@@ -0,0 +1,52 @@
+package draftproto
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypedMessagesRoundTrip(t *testing.T) {
+	messages := []Typed{
+		Hello{ProtocolVersion: ProtocolVersion},
+		StateRequest{},
+		Pick{Seq: 3, PackID: "p0_s1", Picks: []PickSelection{{CardName: "Opt", Zone: ZoneMainboard}}},
+		MovePick{Seq: 4, CardName: "Opt", FromZone: ZoneMainboard, ToZone: ZoneSideboard},
+		SetBasics{Seq: 5, Basics: map[string]int{"island": 8}},
+		RetractPick{Seq: 6, PackID: "p0_s1"},
+		RegisterDeck{Seq: 7, Cards: []string{"Opt", "Island"}},
+		SuggestBasicsRequest{},
+		BotPick{},
+		OverlayTokenRequest{},
+		OverlayToken{Token: "abc123"},
+		State{State: PlayerState{SeatID: 1, NextSeq: 2}},
+		Accepted{Type: TypePickAccepted, State: PlayerState{SeatID: 1}, Duplicate: true},
+		BasicsSuggested{Basics: map[string]int{"forest": 9}},
+		RoundAdvanced{PackNo: 1, PickNo: 2},
+		PickRetracted{PackID: "p0_s1", Cards: []string{"Opt"}},
+		DraftCompleted{},
+		ErrorMessage{Type: TypeError, Err: Error{Code: CodeSeqGap, Message: "seq gap"}},
+	}
+	for _, original := range messages {
+		data, err := Encode(original)
+		require.NoErrorf(t, err, "encode %T", original)
+		var wire Message
+		require.NoErrorf(t, json.Unmarshal(data, &wire), "unmarshal %T", original)
+		decoded, err := Decode(wire)
+		require.NoErrorf(t, err, "decode %T", original)
+		assert.Equalf(t, original, decoded, "%T should round-trip", original)
+	}
+}
+
+func TestDecodeDefaultsErrorCodes(t *testing.T) {
+	decoded, err := Decode(Message{Type: TypeRoomMissing, Error: "Room not found"})
+	require.NoError(t, err, "decode room_missing")
+	msg, ok := decoded.(ErrorMessage)
+	require.True(t, ok, "room_missing should decode as ErrorMessage")
+	assert.Equal(t, CodeRoomMissing, msg.Err.Code, "legacy frames without a code get one from the type")
+
+	_, err = Decode(Message{Type: "from_the_future"})
+	assert.Error(t, err, "unknown types should not decode")
+}
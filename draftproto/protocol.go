@@ -0,0 +1,143 @@
+// Package draftproto defines the draft websocket protocol shared by the server and
+// headless clients: the wire envelope, typed messages, error codes and version handshake.
+package draftproto
+
+// ProtocolVersion is the draft protocol version spoken by this package.
+// Clients announce it in a Hello message; the server rejects mismatched versions.
+const ProtocolVersion = 1
+
+// Message types carried in Message.Type.
+const (
+	// Client -> server.
+	TypeHello         = "hello"
+	TypeState         = "state"
+	TypePick          = "pick"
+	TypeMovePick      = "move_pick"
+	TypeSetBasics     = "set_basics"
+	TypeRetractPick   = "retract_pick"
+	TypeRegisterDeck  = "register_deck"
+	TypeSuggestBasics = "suggest_basics"
+	TypeBotPick       = "bot_pick"
+	// TypeOverlayToken asks for the seat's stream overlay token; the server
+	// answers with the same type and Token set.
+	TypeOverlayToken = "overlay_token"
+
+	// Server -> client. TypeHello and TypeState are also sent by the server.
+	TypePickAccepted         = "pick_accepted"
+	TypeMoveAccepted         = "move_accepted"
+	TypeSetBasicsAccepted    = "set_basics_accepted"
+	TypeRetractAccepted      = "retract_accepted"
+	TypeRegisterDeckAccepted = "register_deck_accepted"
+	TypeBasicsSuggested      = "basics_suggested"
+	TypeRoundAdvanced        = "round_advanced"
+	TypePickRetracted        = "pick_retracted"
+	TypeDraftCompleted       = "draft_completed"
+	TypeSeatOccupied         = "seat_occupied"
+	TypeRoomMissing          = "room_missing"
+	TypeError                = "error"
+)
+
+// Pick zones.
+const (
+	ZoneMainboard = "mainboard"
+	ZoneSideboard = "sideboard"
+)
+
+// Message is the wire envelope for every websocket frame. Only the fields relevant
+// to Type are set; use Decode and the typed messages instead of reading it directly.
+type Message struct {
+	Type            string          `json:"type"`
+	ProtocolVersion int             `json:"protocol_version,omitempty"`
+	Seq             uint64          `json:"seq,omitempty"`
+	PackID          string          `json:"pack_id,omitempty"`
+	CardName        string          `json:"card_name,omitempty"`
+	Zone            string          `json:"zone,omitempty"`
+	FromZone        string          `json:"from_zone,omitempty"`
+	ToZone          string          `json:"to_zone,omitempty"`
+	Basics          map[string]int  `json:"basics,omitempty"`
+	Picks           []PickSelection `json:"picks,omitempty"`
+	Error           string          `json:"error,omitempty"`
+	Code            ErrorCode       `json:"code,omitempty"`
+	Redirect        string          `json:"redirect,omitempty"`
+	Cards           []string        `json:"cards,omitempty"`
+	Token           string          `json:"token,omitempty"`
+
+	State     *PlayerState `json:"state,omitempty"`
+	Duplicate bool         `json:"duplicate,omitempty"`
+	PackNo    int          `json:"pack_no,omitempty"`
+	PickNo    int          `json:"pick_no,omitempty"`
+}
+
+// SeatPicks is a seat's pool split by destination zone.
+type SeatPicks struct {
+	Mainboard []string `json:"mainboard"`
+	Sideboard []string `json:"sideboard"`
+}
+
+// PickSelection is one card taken from the active pack.
+type PickSelection struct {
+	CardName string `json:"card_name"`
+	Zone     string `json:"zone"`
+}
+
+// PackView is the seat-local view of an active pack.
+type PackView struct {
+	PackID string   `json:"pack_id"`
+	Cards  []string `json:"cards"`
+}
+
+// TeammateView is a teammate's pool as seen by another member of the same team.
+type TeammateView struct {
+	SeatID int        `json:"seat_id"`
+	Name   string     `json:"name"`
+	Picks  SeatPicks  `json:"picks"`
+	Deck   *SeatPicks `json:"deck,omitempty"`
+}
+
+// DraftPairing is one post-draft match between two seats.
+type DraftPairing struct {
+	Round int    `json:"round"`
+	Seats [2]int `json:"seats"`
+}
+
+// PlayerState is a seat-local snapshot.
+type PlayerState struct {
+	SeatID        int       `json:"seat_id"`
+	SeatCount     int       `json:"seat_count"`
+	State         string    `json:"state"`
+	Picks         SeatPicks `json:"picks"`
+	Active        *PackView `json:"active_pack,omitempty"`
+	PackNo        int       `json:"pack_no"`
+	PickNo        int       `json:"pick_no"`
+	ExpectedPicks int       `json:"expected_picks"`
+	CanPick       bool      `json:"can_pick"`
+	CanRetract    bool      `json:"can_retract"`
+	NextSeq       uint64    `json:"next_seq"`
+
+	DeckSize int        `json:"deck_size"`
+	Deck     *SeatPicks `json:"deck,omitempty"` // registered deck once locked in
+
+	Team      int            `json:"team,omitempty"` // 1-based team number; 0 outside team drafts
+	Teammates []TeammateView `json:"teammates,omitempty"`
+	Pairings  []DraftPairing `json:"pairings,omitempty"`
+}
+
+// CreateRoomRequest is the JSON body for POST /api/draft/rooms.
+type CreateRoomRequest struct {
+	Deck        []string `json:"deck"`
+	DeckSlug    string   `json:"deck_slug,omitempty"`
+	SeatCount   int      `json:"seat_count"`
+	PackCount   int      `json:"pack_count"`
+	PackSize    int      `json:"pack_size"`
+	PassPattern []int    `json:"pass_pattern,omitempty"`
+	Seed        *uint64  `json:"seed,omitempty"`
+	TeamDraft   bool     `json:"team_draft,omitempty"`
+	DeckSize    int      `json:"deck_size,omitempty"`
+	HostName    string   `json:"host_name,omitempty"`
+}
+
+// CreateRoomResponse is returned when creating or joining a room over HTTP.
+type CreateRoomResponse struct {
+	RoomID  string `json:"room_id"`
+	Created bool   `json:"created"`
+}
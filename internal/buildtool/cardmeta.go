@@ -128,10 +128,15 @@ func fetchMissingCardMeta(cards []Card) error {
 				manaCost = strings.TrimSpace(card.CardFaces[0].ManaCost)
 			}
 			meta := cardMeta{
-				Type:        resolveCardType(printing, card.TypeLine),
-				ManaCost:    manaCost,
-				ManaValue:   parseManaValue(manaCost),
-				DoubleFaced: &isDouble,
+				Type:           resolveCardType(printing, card.TypeLine),
+				ManaCost:       manaCost,
+				ManaValue:      parseManaValue(manaCost),
+				DoubleFaced:    &isDouble,
+				Rarity:         card.Rarity,
+				PauperLegality: card.Legalities["pauper"],
+			}
+			if meta.Type == "land" {
+				meta.ProducedMana = normalizeProducedMana(card.ProducedMana)
 			}
 			cardCache[printing] = meta
 		}
@@ -145,6 +150,20 @@ func fetchMissingCardMeta(cards []Card) error {
 	return nil
 }
 
+// normalizeProducedMana orders Scryfall's produced_mana symbols as WUBRG then C.
+func normalizeProducedMana(symbols []string) string {
+	var out strings.Builder
+	for _, color := range "WUBRGC" {
+		for _, symbol := range symbols {
+			if strings.EqualFold(symbol, string(color)) {
+				out.WriteRune(color)
+				break
+			}
+		}
+	}
+	return out.String()
+}
+
 func resolveCardType(printing, scryfallTypeLine string) string {
 	if override, ok := cardTypeOverrideByPrinting[printing]; ok {
 		return classifyType(override)
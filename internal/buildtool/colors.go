@@ -0,0 +1,135 @@
+package buildtool
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const deckColorOrder = "wubrg"
+
+// costColors returns the colours a mana cost needs: colours of single-colour
+// symbols, and one entry per hybrid symbol listing the colours that pay it.
+// Phyrexian symbols can be paid with life, so they need no colour.
+func costColors(manaCost string) (required string, hybrids []string) {
+	for _, token := range manaSymbolRE.FindAllStringSubmatch(manaCost, -1) {
+		symbol := strings.ToLower(token[1])
+		if strings.Contains(symbol, "p") {
+			continue
+		}
+		colors := ""
+		for _, ch := range symbol {
+			if strings.ContainsRune(deckColorOrder, ch) && !strings.ContainsRune(colors, ch) {
+				colors += string(ch)
+			}
+		}
+		switch {
+		case len(colors) == 1 && !strings.ContainsAny(symbol, "0123456789"):
+			if !strings.Contains(required, colors) {
+				required += colors
+			}
+		case len(colors) > 1:
+			hybrids = append(hybrids, colors)
+		}
+	}
+	return required, hybrids
+}
+
+// checkDeckColors compares a deck's declared colours with the mana costs of
+// its mainboard and sideboard. A colour is missing when a card needs it (or a
+// hybrid symbol has no declared colour); a declared colour is unused when no
+// cost mentions it. Every card must carry its mana cost.
+func checkDeckColors(battleboxSlug, deckSlug, declared string, cards []Card) []ValidationWarning {
+	declared = strings.ToLower(declared)
+	missing := map[string][]string{}
+	used := map[rune]bool{}
+	for _, card := range cards {
+		if card.Qty <= 0 {
+			continue
+		}
+		required, hybrids := costColors(card.ManaCost)
+		for _, ch := range required {
+			used[ch] = true
+			if !strings.ContainsRune(declared, ch) {
+				missing[string(ch)] = append(missing[string(ch)], card.Name)
+			}
+		}
+		for _, colors := range hybrids {
+			for _, ch := range colors {
+				used[ch] = true
+			}
+			if !strings.ContainsAny(declared, colors) {
+				missing[colors] = append(missing[colors], card.Name)
+			}
+		}
+	}
+
+	var warnings []ValidationWarning
+	for colors, names := range missing {
+		sort.Strings(names)
+		warnings = append(warnings, ValidationWarning{
+			Kind:      ValidationWarningDeckColorsMissing,
+			Battlebox: battleboxSlug,
+			Deck:      deckSlug,
+			Card:      strings.Join(compactStrings(names), ", "),
+			Detail:    colors,
+		})
+	}
+	for _, ch := range declared {
+		if strings.ContainsRune(deckColorOrder, ch) && !used[ch] {
+			warnings = append(warnings, ValidationWarning{
+				Kind:      ValidationWarningDeckColorsUnused,
+				Battlebox: battleboxSlug,
+				Deck:      deckSlug,
+				Detail:    string(ch),
+			})
+		}
+	}
+	return warnings
+}
+
+func compactStrings(values []string) []string {
+	out := values[:0]
+	for i, value := range values {
+		if i == 0 || value != values[i-1] {
+			out = append(out, value)
+		}
+	}
+	return out
+}
+
+// validateDeckColors checks every deck that declares colours against its
+// enriched cards. Decks with empty colours, such as cubes, are skipped. Dev
+// validation runs before the build fetches card metadata, so a deck with
+// uncached cards is reported as not checked rather than checked against
+// partial costs; the next build checks it.
+func validateDeckColors(sources BuildSources) []ValidationWarning {
+	var warnings []ValidationWarning
+	for _, bbSource := range sources.Battleboxes {
+		for _, deckSource := range bbSource.Decks {
+			if deckSource.ManifestErr != nil || strings.TrimSpace(deckSource.Manifest.Colors) == "" {
+				continue
+			}
+			manifest := cloneManifest(deckSource.Manifest)
+			enrichManifestCards(&manifest, bbSource.Slug, deckSource.Slug, deckSource.MergedPrintings, bbSource.Manifest.LandSubtypes, nil)
+			cards := append(append([]Card(nil), manifest.Cards...), manifest.Sideboard...)
+			uncached := 0
+			for _, card := range cards {
+				if _, ok := cardCache[card.Printing]; !ok {
+					uncached++
+				}
+			}
+			if uncached > 0 {
+				warnings = append(warnings, ValidationWarning{
+					Kind:      ValidationWarningDeckColorsSkipped,
+					Battlebox: bbSource.Slug,
+					Deck:      deckSource.Slug,
+					Detail:    fmt.Sprintf("%d cards lack cached metadata", uncached),
+				})
+				continue
+			}
+			warnings = append(warnings, checkDeckColors(bbSource.Slug, deckSource.Slug, manifest.Colors, cards)...)
+		}
+	}
+	return warnings
+}
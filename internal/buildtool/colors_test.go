@@ -0,0 +1,52 @@
+package buildtool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckDeckColors(t *testing.T) {
+	cards := []Card{
+		{Name: "Island", Qty: 10, Type: "land"},
+		{Name: "Counterspell", Qty: 4, ManaCost: "{U}{U}"},
+		{Name: "Lightning Bolt", Qty: 4, ManaCost: "{R}"},
+		{Name: "Fireblast", Qty: 2, ManaCost: "{4}{R}{R}"},
+		{Name: "Gut Shot", Qty: 4, ManaCost: "{R/P}"},
+		{Name: "Kitchen Finks", Qty: 2, ManaCost: "{1}{G/W}{G/W}"},
+		{Name: "Spectral Procession", Qty: 1, ManaCost: "{2/W}{2/W}{2/W}"},
+	}
+
+	got := sortedValidationWarningStrings(checkDeckColors("pauper", "delver", "UB", cards))
+	want := []string{
+		"Deck colors declare unused b (pauper/delver)",
+		"Deck colors missing gw (pauper/delver): Kitchen Finks",
+		"Deck colors missing r (pauper/delver): Fireblast, Lightning Bolt",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("warnings = %v, want %v", got, want)
+	}
+
+	if got := checkDeckColors("pauper", "delver", "wur", cards); len(got) != 0 {
+		t.Fatalf("hybrid and twobrid symbols are covered by W, got %v", sortedValidationWarningStrings(got))
+	}
+}
+
+func TestValidateDeckColorsSkipsUncachedDecks(t *testing.T) {
+	previous := cardCache
+	cardCache = map[string]cardMeta{"ice/61": {Type: "spell", ManaCost: "{U}", ManaValue: 1}}
+	t.Cleanup(func() { cardCache = previous })
+
+	sources := BuildSources{Battleboxes: []BattleboxSource{{
+		Slug: "pauper",
+		Decks: []DeckSource{{
+			Slug:            "delver",
+			Manifest:        Manifest{Colors: "UR", Cards: []Card{{Name: "Brainstorm", Qty: 4}, {Name: "Lightning Bolt", Qty: 4}}},
+			MergedPrintings: map[string]string{"brainstorm": "ice/61", "lightning bolt": "m10/146"},
+		}},
+	}}}
+	got := sortedValidationWarningStrings(validateDeckColors(sources))
+	want := []string{"Deck colors not checked (pauper/delver): 1 cards lack cached metadata"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("warnings = %v, want %v", got, want)
+	}
+}
@@ -44,6 +44,7 @@ func processDeck(bbSource BattleboxSource, deckSource DeckSource, annotations ma
 		Sideboard:      manifest.Sideboard,
 		Maybeboard:     manifest.Maybeboard,
 		Guides:         make(map[string]MatchupGuide),
+		Stats:          computeDeckStats(manifest.Cards, manifest.Sideboard),
 	}
 
 	for _, card := range manifest.Cards {
@@ -96,6 +97,20 @@ func processDeck(bbSource BattleboxSource, deckSource DeckSource, annotations ma
 		deck.Guides[opponentSlug] = guide
 	}
 
+	if deckSource.DraftStatsPath != "" && fileExists(deckSource.DraftStatsPath) {
+		stats, err := loadDraftStats(deckSource.DraftStatsPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading draft stats %s: %w", deckSource.DraftStatsPath, err)
+		}
+		deck.DraftStats = stats
+	}
+
+	if uiProfile.Sample.Mode == "hand" {
+		deck.Odds = computeDeckOdds(manifest.Cards, uiProfile.Sample.Size)
+		seed := *goldfishSeed ^ goldfishDeckSeed(bbSource.Slug, deckSource.Slug)
+		deck.Goldfish = simulateGoldfish(manifest.Cards, uiProfile.Sample.Size, *goldfishGames, seed)
+	}
+
 	applyDeckWarningAnnotations(deck, bbSource.Slug, annotations)
 
 	return deck, nil
@@ -114,6 +129,10 @@ func applyDeckWarningAnnotations(deck *Deck, battleboxSlug string, annotations m
 		return
 	}
 	deck.PrimerWarnings = append([]string(nil), deckWarnings.Primer...)
+	deck.RuleWarnings = append([]string(nil), deckWarnings.Rules...)
+	if deck.Diff != nil {
+		deck.Diff.RuleWarnings = append([]string(nil), deckWarnings.StagedRules...)
+	}
 	for opponentSlug, guideWarnings := range deckWarnings.Guides {
 		guide, ok := deck.Guides[opponentSlug]
 		if !ok {
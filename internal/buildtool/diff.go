@@ -47,6 +47,9 @@ func enrichManifestCards(manifest *Manifest, battlebox, slug string, printings m
 			if meta.DoubleFaced != nil {
 				cards[i].DoubleFaced = *meta.DoubleFaced
 			}
+			if cards[i].Type == "land" {
+				cards[i].ProducedMana = meta.ProducedMana
+			}
 		}
 	}
 	applyMeta(manifest.Cards)
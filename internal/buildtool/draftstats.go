@@ -0,0 +1,28 @@
+package buildtool
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// loadDraftStats reads a deck's draft-stats.json, as served by the draft server's
+// /api/draft/stats endpoint.
+func loadDraftStats(path string) (*DraftStats, error) {
+	data, err := buildFiles.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var stats DraftStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, err
+	}
+	for _, card := range stats.Cards {
+		if card.Name == "" {
+			return nil, errors.New("card stats entry without a name")
+		}
+	}
+	if stats.Cards == nil {
+		stats.Cards = []DraftCardStats{}
+	}
+	return &stats, nil
+}
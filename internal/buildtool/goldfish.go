@@ -0,0 +1,293 @@
+package buildtool
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// The goldfish simulator plays a deck alone on the play to see how quickly its
+// mana base casts each mana value on colour. Each game:
+//   - draws an opening hand and London-mulligans hands with fewer than two lands
+//     or fewer than two spells, keeping any hand after goldfishMaxMulligans;
+//   - plays one land a turn, picking the land that adds a colour the hand needs;
+//   - records, per mana value, the first turn a card of that value in hand could
+//     be paid for with colours matched, then casts the biggest spells it can.
+//
+// Generic, X, colourless, snow and Phyrexian symbols count as generic mana.
+// Lands without produced-mana metadata produce colourless mana.
+
+const (
+	goldfishTurns        = 10
+	goldfishMaxMulligans = 2
+)
+
+func validateGoldfishFlags() error {
+	if *goldfishGames < 0 {
+		return errors.New("-goldfish-games must not be negative")
+	}
+	return nil
+}
+
+// goldfishSettingsHash folds the simulation settings into a battlebox input
+// hash, since deck output depends on them.
+func goldfishSettingsHash(bbHash string, games int, seed uint64) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d\x00%d", bbHash, games, seed)))
+	return fmt.Sprintf("%x", sum)
+}
+
+// goldfishDeckSeed derives a deck's PRNG stream so a deck's results do not
+// change when other decks are added or edited.
+func goldfishDeckSeed(battleboxSlug, deckSlug string) uint64 {
+	sum := sha256.Sum256([]byte(battleboxSlug + "/" + deckSlug))
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+type goldfishCard struct {
+	land      bool
+	produces  string // WUBRG subset a land can produce
+	manaValue int
+	// pips lists each coloured symbol as the colours that can pay it.
+	pips []string
+}
+
+func newGoldfishCard(card Card) goldfishCard {
+	if card.Type == "land" {
+		return goldfishCard{land: true, produces: strings.TrimRight(card.ProducedMana, "C")}
+	}
+	gc := goldfishCard{manaValue: card.ManaValue}
+	for _, token := range manaSymbolRE.FindAllStringSubmatch(card.ManaCost, -1) {
+		symbol := strings.ToUpper(token[1])
+		if strings.Contains(symbol, "P") {
+			continue
+		}
+		var colors strings.Builder
+		for _, part := range strings.Split(symbol, "/") {
+			if len(part) == 1 && strings.Contains("WUBRG", part) {
+				colors.WriteString(part)
+			}
+		}
+		// Hybrid symbols with a generic half ({2/W}) can always be paid generically.
+		if colors.Len() > 0 && !strings.ContainsAny(symbol, "0123456789") {
+			gc.pips = append(gc.pips, colors.String())
+		}
+	}
+	return gc
+}
+
+// canPay reports whether lands can pay card: each pip takes a distinct land
+// producing one of its colours and the rest cover the remaining mana value.
+func canPay(card goldfishCard, lands []goldfishCard) bool {
+	if card.manaValue > len(lands) {
+		return false
+	}
+	used := make([]bool, len(lands))
+	var assign func(pip int) bool
+	assign = func(pip int) bool {
+		if pip == len(card.pips) {
+			return true
+		}
+		for i, land := range lands {
+			if used[i] || !strings.ContainsAny(land.produces, card.pips[pip]) {
+				continue
+			}
+			used[i] = true
+			if assign(pip + 1) {
+				return true
+			}
+			used[i] = false
+		}
+		return false
+	}
+	return assign(0)
+}
+
+func countLands(cards []goldfishCard) int {
+	lands := 0
+	for _, card := range cards {
+		if card.land {
+			lands++
+		}
+	}
+	return lands
+}
+
+// drawGoldfishHand London-mulligans from a shuffled library and returns the
+// kept hand, the rest of the library and the number of mulligans taken.
+func drawGoldfishHand(rng *rand.Rand, deck []goldfishCard, handSize int) ([]goldfishCard, []goldfishCard, int) {
+	for mulligans := 0; ; mulligans++ {
+		library := slices.Clone(deck)
+		rng.Shuffle(len(library), func(i, j int) { library[i], library[j] = library[j], library[i] })
+		hand, library := library[:handSize], library[handSize:]
+		lands := countLands(hand)
+		if mulligans < goldfishMaxMulligans && (lands < 2 || handSize-lands < 2) {
+			continue
+		}
+		// Bottom a card per mulligan: spare lands first when flooded, else the
+		// most expensive spell.
+		hand = slices.Clone(hand)
+		for range min(mulligans, len(hand)) {
+			sort.SliceStable(hand, func(i, j int) bool {
+				if hand[i].land != hand[j].land {
+					return hand[i].land == (countLands(hand) > len(hand)/2)
+				}
+				return hand[i].manaValue > hand[j].manaValue
+			})
+			library = append(library, hand[0])
+			hand = hand[1:]
+		}
+		return hand, library, mulligans
+	}
+}
+
+// pickGoldfishLand returns the index in hand of the land to play: the first
+// land adding a colour that spells in hand need and play lacks, else any land.
+func pickGoldfishLand(hand, battlefield []goldfishCard) int {
+	available := ""
+	for _, land := range battlefield {
+		available += land.produces
+	}
+	best := -1
+	for i, card := range hand {
+		if !card.land {
+			continue
+		}
+		if best < 0 {
+			best = i
+		}
+		for _, spell := range hand {
+			for _, pip := range spell.pips {
+				if !strings.ContainsAny(available, pip) && strings.ContainsAny(card.produces, pip) {
+					return i
+				}
+			}
+		}
+	}
+	return best
+}
+
+// playGoldfishGame returns, per mana value, the first turn a card of that value
+// in hand was castable on colour (0 when never within goldfishTurns).
+func playGoldfishGame(hand, library []goldfishCard, maxManaValue int) []int {
+	firstTurn := make([]int, maxManaValue+1)
+	var battlefield []goldfishCard
+	for turn := 1; turn <= goldfishTurns; turn++ {
+		if turn > 1 && len(library) > 0 {
+			hand = append(hand, library[0])
+			library = library[1:]
+		}
+		if i := pickGoldfishLand(hand, battlefield); i >= 0 {
+			battlefield = append(battlefield, hand[i])
+			hand = slices.Delete(hand, i, i+1)
+		}
+		for _, card := range hand {
+			if !card.land && card.manaValue <= maxManaValue && firstTurn[card.manaValue] == 0 && canPay(card, battlefield) {
+				firstTurn[card.manaValue] = turn
+			}
+		}
+
+		// Curve out: cast the biggest castable spell with the untapped lands
+		// until nothing else fits.
+		untapped := slices.Clone(battlefield)
+		for {
+			cast := -1
+			for i, card := range hand {
+				if !card.land && canPay(card, untapped) && (cast < 0 || card.manaValue > hand[cast].manaValue) {
+					cast = i
+				}
+			}
+			if cast < 0 {
+				break
+			}
+			untapped = tapForGoldfishCard(hand[cast], untapped)
+			hand = slices.Delete(hand, cast, cast+1)
+		}
+	}
+	return firstTurn
+}
+
+// tapForGoldfishCard removes the lands used to pay card, preferring lands with
+// fewer colours for its pips and colourless or narrow lands for generic mana.
+func tapForGoldfishCard(card goldfishCard, lands []goldfishCard) []goldfishCard {
+	remaining := slices.Clone(lands)
+	sort.SliceStable(remaining, func(i, j int) bool { return len(remaining[i].produces) < len(remaining[j].produces) })
+	for _, pip := range card.pips {
+		for i, land := range remaining {
+			if strings.ContainsAny(land.produces, pip) {
+				remaining = slices.Delete(remaining, i, i+1)
+				break
+			}
+		}
+	}
+	generic := card.manaValue - len(card.pips)
+	return remaining[min(max(generic, 0), len(remaining)):]
+}
+
+// simulateGoldfish plays games of cards on the play, or returns nil when the
+// deck has no lands, no spells or fewer cards than a hand.
+func simulateGoldfish(cards []Card, handSize, games int, seed uint64) *GoldfishStats {
+	var deck []goldfishCard
+	maxManaValue := 0
+	present := map[int]bool{}
+	for _, card := range cards {
+		gc := newGoldfishCard(card)
+		for range card.Qty {
+			deck = append(deck, gc)
+		}
+		if !gc.land && gc.manaValue > 0 && card.Qty > 0 {
+			present[gc.manaValue] = true
+			maxManaValue = max(maxManaValue, gc.manaValue)
+		}
+	}
+	lands := countLands(deck)
+	if games <= 0 || handSize <= 0 || len(deck) < handSize || lands == 0 || len(present) == 0 {
+		return nil
+	}
+
+	rng := rand.New(rand.NewPCG(seed, 0))
+	mulligans := make([]int, goldfishMaxMulligans+1)
+	turnCounts := make([][]int, maxManaValue+1)
+	for mv := range turnCounts {
+		turnCounts[mv] = make([]int, goldfishTurns+1)
+	}
+	for range games {
+		hand, library, taken := drawGoldfishHand(rng, deck, handSize)
+		mulligans[taken]++
+		for mv, turn := range playGoldfishGame(hand, library, maxManaValue) {
+			turnCounts[mv][turn]++
+		}
+	}
+
+	stats := &GoldfishStats{Games: games, Mulligans: make([]float64, len(mulligans))}
+	for i, count := range mulligans {
+		stats.Mulligans[i] = roundTo(float64(count)/float64(games), 4)
+	}
+	for mv := 1; mv <= maxManaValue; mv++ {
+		if !present[mv] {
+			continue
+		}
+		entry := GoldfishManaValue{ManaValue: mv, ByTurn: make([]float64, goldfishTurns)}
+		var castable, turnSum int
+		for turn := 1; turn <= goldfishTurns; turn++ {
+			count := turnCounts[mv][turn]
+			entry.ByTurn[turn-1] = roundTo(float64(count)/float64(games), 4)
+			castable += count
+			turnSum += count * turn
+			if turn <= mv {
+				entry.OnCurve += float64(count)
+			}
+		}
+		entry.OnCurve = roundTo(entry.OnCurve/float64(games), 4)
+		entry.Never = roundTo(float64(turnCounts[mv][0])/float64(games), 4)
+		if castable > 0 {
+			entry.MeanTurn = roundTo(float64(turnSum)/float64(castable), 2)
+		}
+		stats.ManaValues = append(stats.ManaValues, entry)
+	}
+	return stats
+}
@@ -0,0 +1,70 @@
+package buildtool
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func goldfishDeck(lands ...Card) []Card {
+	return append(lands,
+		Card{Name: "Elvish Mystic", Qty: 8, Type: "creature", ManaCost: "{G}", ManaValue: 1},
+		Card{Name: "Counterspell", Qty: 8, Type: "spell", ManaCost: "{U}{U}", ManaValue: 2},
+		Card{Name: "Mulldrifter", Qty: 8, Type: "creature", ManaCost: "{4}{U}", ManaValue: 5},
+	)
+}
+
+func TestSimulateGoldfish(t *testing.T) {
+	mono := goldfishDeck(Card{Name: "Island", Qty: 16, Type: "land", ProducedMana: "U"})
+	mono[1].ManaCost = "{U}" // no green pips, so every spell is on colour
+
+	stats := simulateGoldfish(mono, 7, 500, 3)
+	if stats == nil || stats.Games != 500 || len(stats.Mulligans) != goldfishMaxMulligans+1 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	if again := simulateGoldfish(mono, 7, 500, 3); !reflect.DeepEqual(stats, again) {
+		t.Fatalf("same seed gave different results")
+	}
+	var kept float64
+	for _, share := range stats.Mulligans {
+		kept += share
+	}
+	if math.Abs(kept-1) > 0.001 {
+		t.Fatalf("mulligan shares sum to %f", kept)
+	}
+	if len(stats.ManaValues) != 3 || stats.ManaValues[2].ManaValue != 5 || len(stats.ManaValues[0].ByTurn) != goldfishTurns {
+		t.Fatalf("mana values = %+v", stats.ManaValues)
+	}
+
+	split := goldfishDeck(Card{Name: "Island", Qty: 8, Type: "land", ProducedMana: "U"}, Card{Name: "Forest", Qty: 8, Type: "land", ProducedMana: "G"})
+	greedy := simulateGoldfish(split, 7, 500, 3)
+	monoTwo, greedyTwo := stats.ManaValues[1], greedy.ManaValues[1]
+	if greedyTwo.OnCurve >= monoTwo.OnCurve || greedyTwo.MeanTurn <= monoTwo.MeanTurn {
+		t.Fatalf("UU on a split base: on curve %f, mean %f; mono: on curve %f, mean %f",
+			greedyTwo.OnCurve, greedyTwo.MeanTurn, monoTwo.OnCurve, monoTwo.MeanTurn)
+	}
+
+	if simulateGoldfish(mono, 7, 0, 3) != nil {
+		t.Fatalf("zero games should disable the simulation")
+	}
+	if simulateGoldfish(mono[1:], 7, 100, 3) != nil {
+		t.Fatalf("a deck without lands has nothing to simulate")
+	}
+}
+
+func TestGoldfishCanPay(t *testing.T) {
+	island := goldfishCard{land: true, produces: "U"}
+	forest := goldfishCard{land: true, produces: "G"}
+	wastes := goldfishCard{land: true}
+	hybrid := newGoldfishCard(Card{ManaCost: "{1}{G/U}{U}", ManaValue: 3})
+	if !canPay(hybrid, []goldfishCard{island, forest, wastes}) {
+		t.Fatalf("hybrid pip should take the forest")
+	}
+	if canPay(hybrid, []goldfishCard{forest, forest, wastes}) {
+		t.Fatalf("the blue pip needs an island")
+	}
+	phyrexian := newGoldfishCard(Card{ManaCost: "{1}{U/P}{2/W}", ManaValue: 4})
+	if len(phyrexian.pips) != 0 {
+		t.Fatalf("phyrexian and twobrid symbols are generic, got %v", phyrexian.pips)
+	}
+}
@@ -22,6 +22,24 @@ func Main() {
 
 	loadCardCache()
 
+	if *cubeSwaps != "" {
+		if err := runCubeSwaps(dataDir, *cubeSwaps, *acceptSwaps, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error suggesting cube swaps: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	blend, err := matrixBlendFromFlags()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := validateGoldfishFlags(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	projectPrintings := loadPrintings(filepath.Join(dataDir, printingsFileName))
 	battleboxDirs, err := orderedBattleboxDirs(dataDir)
 	if err != nil {
@@ -33,6 +51,8 @@ func Main() {
 	deckWarningAnnotations := map[string]map[string]deckWarningAnnotations{}
 	if appenv.IsDev() {
 		warnings, annotations := validatePrintingsUsage(sources)
+		warnings = append(warnings, validateWinrateMatrices(sources)...)
+		warnings = append(warnings, validateDeckColors(sources)...)
 		deckWarningAnnotations = annotations
 		if *validate {
 			for _, warning := range sortedValidationWarningStrings(warnings) {
@@ -41,8 +61,15 @@ func Main() {
 		}
 	}
 
+	if appenv.IsDev() || *snapshotMatrices {
+		if err := snapshotWinrateMatrices(sources); err != nil {
+			fmt.Fprintf(os.Stderr, "Error snapshotting winrate matrices: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	stamp := loadBuildStamp(stampFile)
-	plan, err := planBuildOutputs(sources, outputDir, stamp)
+	plan, err := planBuildOutputs(sources, outputDir, stamp, blend)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error planning build: %v\n", err)
 		os.Exit(1)
@@ -83,7 +110,7 @@ func Main() {
 		os.Exit(1)
 	}
 
-	if err := writeMatrixOutputs(dataDir, outputDir, plan.MatrixDirtySlugs); err != nil {
+	if err := writeMatrixOutputs(sources, outputDir, plan.MatrixDirtySlugs, blend); err != nil {
 		fmt.Fprintf(os.Stderr, "Error writing matrix output: %v\n", err)
 		os.Exit(1)
 	}
@@ -116,7 +143,7 @@ func (p buildPlan) nextStamp(previous BuildStamp) BuildStamp {
 	}
 }
 
-func planBuildOutputs(sources BuildSources, outputDir string, stamp BuildStamp) (buildPlan, error) {
+func planBuildOutputs(sources BuildSources, outputDir string, stamp BuildStamp, blend MatrixBlend) (buildPlan, error) {
 	plan := buildPlan{
 		BattleboxHashes: make(map[string]string),
 		MatrixHashes:    make(map[string]string),
@@ -133,18 +160,34 @@ func planBuildOutputs(sources BuildSources, outputDir string, stamp BuildStamp)
 		if err != nil {
 			return plan, fmt.Errorf("hashing battlebox %s: %w", slug, err)
 		}
+		bbHash = goldfishSettingsHash(bbHash, *goldfishGames, *goldfishSeed)
 		plan.BattleboxHashes[slug] = bbHash
 
-		matrixSourcePath := filepath.Join(sources.DataDir, slug, "mtgdecks-winrate-matrix.json")
+		matrixSourcePath := filepath.Join(sources.DataDir, slug, mtgdecksMatrixFileName)
+		localResultsPath := filepath.Join(sources.DataDir, slug, localMatchResultsFileName)
 		matrixOutputPath := filepath.Join(outputDir, slug, "winrate.json")
-		matrixHash, err := hashFiles([]string{matrixSourcePath}, stamp.FileCache)
+		historyOutputPath := filepath.Join(outputDir, slug, "winrate-history.json")
+		matrixInputs := []string{matrixSourcePath}
+		hasLocalResults := fileExists(localResultsPath)
+		if hasLocalResults {
+			matrixInputs = append(matrixInputs, localResultsPath)
+		}
+		snapshotPaths, err := listMatrixSnapshots(filepath.Join(sources.DataDir, slug))
+		if err != nil {
+			return plan, fmt.Errorf("listing matrix snapshots for %s: %w", slug, err)
+		}
+		matrixInputs = append(matrixInputs, snapshotPaths...)
+		matrixHash, err := hashFiles(matrixInputs, stamp.FileCache)
 		if err != nil {
 			return plan, fmt.Errorf("hashing matrix for %s: %w", slug, err)
 		}
+		bbSource, _ := sources.Battlebox(slug)
+		matrixHash = matrixSettingsHash(matrixHash, bbSource.DeckSlugs(), blend)
 		plan.MatrixHashes[slug] = matrixHash
 
 		outPath := filepath.Join(outputDir, slug+".json")
-		matrixOutputDrifted := fileExists(matrixSourcePath) != fileExists(matrixOutputPath)
+		matrixOutputDrifted := (fileExists(matrixSourcePath) || hasLocalResults) != fileExists(matrixOutputPath) ||
+			(len(snapshotPaths) > 0) != fileExists(historyOutputPath)
 		if *fullBuild || stamp.GlobalHash != globalHash || stamp.Battleboxes[slug] != bbHash || !fileExists(outPath) {
 			plan.DirtySlugs = append(plan.DirtySlugs, slug)
 		}
@@ -267,9 +310,13 @@ func writeBattleboxOutputs(outputDir string, battleboxes []Battlebox) error {
 	return nil
 }
 
-func writeMatrixOutputs(dataDir, outputDir string, dirtySlugs []string) error {
+func writeMatrixOutputs(sources BuildSources, outputDir string, dirtySlugs []string, blend MatrixBlend) error {
 	for _, slug := range dirtySlugs {
-		if err := writeBattleboxMatrix(dataDir, outputDir, slug); err != nil {
+		bbSource, _ := sources.Battlebox(slug)
+		if err := writeBattleboxMatrix(sources.DataDir, outputDir, slug, bbSource.DeckSlugs(), blend); err != nil {
+			return fmt.Errorf("%s: %w", slug, err)
+		}
+		if err := writeBattleboxMatrixHistory(sources.DataDir, outputDir, slug); err != nil {
 			return fmt.Errorf("%s: %w", slug, err)
 		}
 	}
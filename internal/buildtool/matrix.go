@@ -0,0 +1,339 @@
+package buildtool
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Local match results are blended into the imported mtgdecks matrix as if each
+// local match were LocalWeight imported matches. Blended cells get a recomputed
+// 95% interval and every cell lists where its matches came from.
+
+const (
+	matrixIntervalWilson = "wilson"
+	matrixIntervalBayes  = "bayes"
+	matrixIntervalZ      = 1.96
+	// Cells with fewer matches are flagged low_sample.
+	matrixLowSampleMatches = 20
+	// A-vs-B and B-vs-A may miss summing to 1 by this much; the importer
+	// rounds each rate to two places.
+	matrixComplementTolerance = 0.02
+)
+
+// winrateTiers bucket field winrates, best first.
+var winrateTiers = []struct {
+	Tier  string
+	MinWR float64
+}{
+	{"S", 0.56},
+	{"A", 0.52},
+	{"B", 0.48},
+	{"C", 0.44},
+	{"D", 0},
+}
+
+func matrixBlendFromFlags() (MatrixBlend, error) {
+	blend := MatrixBlend{LocalWeight: *matrixLocalWeight, Interval: *matrixInterval}
+	if blend.LocalWeight < 0 || math.IsNaN(blend.LocalWeight) || math.IsInf(blend.LocalWeight, 0) {
+		return blend, errors.New("-matrix-local-weight must be a non-negative number")
+	}
+	if blend.Interval != matrixIntervalWilson && blend.Interval != matrixIntervalBayes {
+		return blend, fmt.Errorf("-matrix-interval must be %q or %q", matrixIntervalWilson, matrixIntervalBayes)
+	}
+	return blend, nil
+}
+
+// matrixSettingsHash folds the deck list and blend settings into a matrix input
+// hash: field winrates depend on the decks, blended cells on the settings.
+func matrixSettingsHash(fileHash string, deckSlugs []string, blend MatrixBlend) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%g\x00%s", fileHash, strings.Join(deckSlugs, ","), blend.LocalWeight, blend.Interval)))
+	return fmt.Sprintf("%x", sum)
+}
+
+// loadWinrateMatrix reads an imported matrix. Unknown keys and out-of-range
+// cells are errors so a malformed import fails the build.
+func loadWinrateMatrix(path string) (*WinrateMatrix, error) {
+	data, err := buildFiles.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var matrix WinrateMatrix
+	if err := decoder.Decode(&matrix); err != nil {
+		return nil, err
+	}
+	for deck, opponents := range matrix.Matchups {
+		for opponent, cell := range opponents {
+			if cell.Matches < 0 || cell.WR < 0 || cell.WR > 1 || cell.CILow < 0 || cell.CIHigh > 1 || cell.CILow > cell.CIHigh {
+				return nil, fmt.Errorf("invalid cell %s vs %s", deck, opponent)
+			}
+		}
+	}
+	return &matrix, nil
+}
+
+// checkWinrateMatrix reports matrix slugs that are not battlebox decks and pairs
+// whose two directions do not complement each other.
+func checkWinrateMatrix(battleboxSlug string, matrix *WinrateMatrix, deckSlugs []string) []ValidationWarning {
+	var warnings []ValidationWarning
+	unknown := map[string]struct{}{}
+	for deck, opponents := range matrix.Matchups {
+		if !slices.Contains(deckSlugs, deck) {
+			unknown[deck] = struct{}{}
+		}
+		for opponent := range opponents {
+			if !slices.Contains(deckSlugs, opponent) {
+				unknown[opponent] = struct{}{}
+			}
+		}
+	}
+	for slug := range unknown {
+		warnings = append(warnings, ValidationWarning{
+			Kind:      ValidationWarningMatrixUnknownDeck,
+			Battlebox: battleboxSlug,
+			Deck:      slug,
+		})
+	}
+
+	for deck, opponents := range matrix.Matchups {
+		for opponent, cell := range opponents {
+			reverse, ok := matrix.Matchups[opponent][deck]
+			if !ok {
+				warnings = append(warnings, ValidationWarning{
+					Kind:      ValidationWarningMatrixAsymmetric,
+					Battlebox: battleboxSlug,
+					Deck:      deck,
+					Opponent:  opponent,
+					Detail:    "no reverse cell",
+				})
+				continue
+			}
+			// Report each complete pair once.
+			if deck > opponent {
+				continue
+			}
+			if math.Abs(cell.WR+reverse.WR-1) > matrixComplementTolerance {
+				warnings = append(warnings, ValidationWarning{
+					Kind:      ValidationWarningMatrixAsymmetric,
+					Battlebox: battleboxSlug,
+					Deck:      deck,
+					Opponent:  opponent,
+					Detail:    fmt.Sprintf("winrates %.2f and %.2f do not sum to 1", cell.WR, reverse.WR),
+				})
+			}
+			if cell.Matches != reverse.Matches {
+				warnings = append(warnings, ValidationWarning{
+					Kind:      ValidationWarningMatrixAsymmetric,
+					Battlebox: battleboxSlug,
+					Deck:      deck,
+					Opponent:  opponent,
+					Detail:    fmt.Sprintf("match counts %d and %d differ", cell.Matches, reverse.Matches),
+				})
+			}
+		}
+	}
+	return warnings
+}
+
+// validateWinrateMatrices checks every battlebox's imported matrix.
+func validateWinrateMatrices(sources BuildSources) []ValidationWarning {
+	var warnings []ValidationWarning
+	for _, bbSource := range sources.Battleboxes {
+		path := filepath.Join(bbSource.Path, mtgdecksMatrixFileName)
+		if !fileExists(path) {
+			continue
+		}
+		matrix, err := loadWinrateMatrix(path)
+		if err != nil {
+			warnings = append(warnings, ValidationWarning{
+				Kind:      ValidationWarningInput,
+				Battlebox: bbSource.Slug,
+				Detail:    fmt.Sprintf("%s: %v", mtgdecksMatrixFileName, err),
+			})
+			continue
+		}
+		warnings = append(warnings, checkWinrateMatrix(bbSource.Slug, matrix, bbSource.DeckSlugs())...)
+	}
+	return warnings
+}
+
+// annotateWinrateMatrix flags low-sample cells and computes each deck's field
+// winrate over the other battlebox decks, ranked and tiered.
+func annotateWinrateMatrix(matrix *WinrateMatrix, deckSlugs []string) {
+	matrix.Field = map[string]DeckFieldWinrate{}
+	for deck, opponents := range matrix.Matchups {
+		field := DeckFieldWinrate{}
+		var sum float64
+		for opponent, cell := range opponents {
+			cell.LowSample = cell.Matches < matrixLowSampleMatches
+			opponents[opponent] = cell
+			if opponent == deck || !slices.Contains(deckSlugs, opponent) {
+				continue
+			}
+			sum += cell.WR
+			field.Opponents++
+			if cell.LowSample {
+				field.LowSample++
+			}
+		}
+		if field.Opponents == 0 || !slices.Contains(deckSlugs, deck) {
+			continue
+		}
+		field.WR = roundTo(sum/float64(field.Opponents), 4)
+		for _, tier := range winrateTiers {
+			if field.WR >= tier.MinWR {
+				field.Tier = tier.Tier
+				break
+			}
+		}
+		matrix.Field[deck] = field
+	}
+
+	ranked := make([]string, 0, len(matrix.Field))
+	for deck := range matrix.Field {
+		ranked = append(ranked, deck)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := matrix.Field[ranked[i]], matrix.Field[ranked[j]]
+		if a.WR != b.WR {
+			return a.WR > b.WR
+		}
+		return ranked[i] < ranked[j]
+	})
+	for i, deck := range ranked {
+		field := matrix.Field[deck]
+		field.Rank = i + 1
+		matrix.Field[deck] = field
+	}
+}
+
+// loadLocalMatchResults reads a battlebox's local-match-results.json, as served
+// by the draft server's /api/game/matches/matrix endpoint.
+func loadLocalMatchResults(path, battleboxSlug string) (*LocalMatchResults, error) {
+	data, err := buildFiles.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var results LocalMatchResults
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, err
+	}
+	if results.Battlebox != "" && results.Battlebox != battleboxSlug {
+		return nil, fmt.Errorf("results are for battlebox %q", results.Battlebox)
+	}
+	for deck, opponents := range results.Matchups {
+		for opponent, record := range opponents {
+			if record.Matches < 0 || record.Wins < 0 || record.Losses < 0 || record.Draws < 0 || record.Wins+record.Losses+record.Draws != record.Matches {
+				return nil, fmt.Errorf("inconsistent record for %s vs %s", deck, opponent)
+			}
+		}
+	}
+	return &results, nil
+}
+
+func roundTo(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
+
+// matrixIntervalBounds returns a 95% interval for winrate p over n (possibly
+// weighted) matches. Wilson is the score interval; bayes is the normal
+// approximation of the Beta(1+wins, 1+losses) posterior under a uniform prior.
+func matrixIntervalBounds(p, n float64, interval string) (float64, float64) {
+	z := matrixIntervalZ
+	var low, high float64
+	if interval == matrixIntervalBayes {
+		a := 1 + p*n
+		b := 1 + (1-p)*n
+		mean := a / (a + b)
+		sd := math.Sqrt(a * b / ((a + b) * (a + b) * (a + b + 1)))
+		low, high = mean-z*sd, mean+z*sd
+	} else {
+		denom := 1 + z*z/n
+		center := (p + z*z/(2*n)) / denom
+		half := z * math.Sqrt(p*(1-p)/n+z*z/(4*n*n)) / denom
+		low, high = center-half, center+half
+	}
+	return max(0, low), min(1, high)
+}
+
+// blendLocalMatchResults merges local into matrix. Imported cells keep their
+// published interval unless local matches change them.
+func blendLocalMatchResults(matrix *WinrateMatrix, local *LocalMatchResults, blend MatrixBlend) {
+	if matrix.Matchups == nil {
+		matrix.Matchups = map[string]map[string]WinrateCell{}
+	}
+	if matrix.Totals == nil {
+		matrix.Totals = map[string]WinrateTotal{}
+	}
+	for _, opponents := range matrix.Matchups {
+		for opponent, cell := range opponents {
+			cell.Sources = &WinrateCellSources{Mtgdecks: &WinrateImportedSource{Matches: cell.Matches, WR: cell.WR}}
+			opponents[opponent] = cell
+		}
+	}
+
+	for deck, opponents := range local.Matchups {
+		var totalMatches, totalScore float64
+		for opponent, record := range opponents {
+			if record.Matches == 0 {
+				continue
+			}
+			score := float64(record.Wins) + 0.5*float64(record.Draws)
+			localSource := &WinrateLocalSource{
+				LocalMatchRecord: record,
+				WR:               roundTo(score/float64(record.Matches), 2),
+				Weight:           blend.LocalWeight,
+			}
+			row := matrix.Matchups[deck]
+			if row == nil {
+				row = map[string]WinrateCell{}
+				matrix.Matchups[deck] = row
+			}
+			cell, imported := row[opponent]
+			weighted := blend.LocalWeight * float64(record.Matches)
+			n := float64(cell.Matches) + weighted
+			if weighted == 0 || n == 0 {
+				if imported {
+					cell.Sources.Local = localSource
+					row[opponent] = cell
+				}
+				continue
+			}
+			p := (cell.WR*float64(cell.Matches) + blend.LocalWeight*score) / n
+			low, high := matrixIntervalBounds(p, n, blend.Interval)
+			sources := &WinrateCellSources{Local: localSource}
+			if imported {
+				sources.Mtgdecks = cell.Sources.Mtgdecks
+			}
+			row[opponent] = WinrateCell{
+				CIHigh:  roundTo(high, 2),
+				CILow:   roundTo(low, 2),
+				Matches: int(math.Round(n)),
+				WR:      roundTo(p, 2),
+				Sources: sources,
+			}
+			totalMatches += weighted
+			totalScore += blend.LocalWeight * score
+		}
+		if totalMatches == 0 {
+			continue
+		}
+		total := matrix.Totals[deck]
+		total.Matches += int(math.Round(totalMatches))
+		total.Wins += int(math.Round(totalScore))
+		if total.Matches > 0 {
+			total.WR = roundTo(float64(total.Wins)/float64(total.Matches), 4)
+		}
+		matrix.Totals[deck] = total
+	}
+}
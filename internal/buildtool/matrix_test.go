@@ -0,0 +1,250 @@
+package buildtool
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatrixIntervalBounds(t *testing.T) {
+	low, high := matrixIntervalBounds(0.5, 100, matrixIntervalWilson)
+	if math.Abs(low-0.4038) > 0.001 || math.Abs(high-0.5962) > 0.001 {
+		t.Fatalf("wilson interval = [%f, %f], want about [0.404, 0.596]", low, high)
+	}
+	low, high = matrixIntervalBounds(1, 3, matrixIntervalWilson)
+	if high != 1 || low <= 0 {
+		t.Fatalf("wilson interval for 3/3 = [%f, %f]", low, high)
+	}
+	low, high = matrixIntervalBounds(1, 3, matrixIntervalBayes)
+	if high != 1 || low <= 0.3 || low >= 0.7 {
+		t.Fatalf("bayes interval for 3/3 = [%f, %f], want the prior to pull it below 1", low, high)
+	}
+}
+
+func TestBlendLocalMatchResults(t *testing.T) {
+	matrix := WinrateMatrix{
+		Matchups: map[string]map[string]WinrateCell{
+			"affinity": {
+				"elves": {WR: 0.4, Matches: 100, CILow: 0.31, CIHigh: 0.5},
+				"tron":  {WR: 0.2, Matches: 10, CILow: 0.05, CIHigh: 0.52},
+			},
+		},
+		Totals: map[string]WinrateTotal{"affinity": {Matches: 110, Wins: 42, WR: 0.3818}},
+	}
+	local := &LocalMatchResults{Matchups: map[string]map[string]LocalMatchRecord{
+		"affinity": {"elves": {Matches: 10, Wins: 9, Draws: 1}},
+		"elves":    {"affinity": {Matches: 10, Losses: 9, Draws: 1}},
+	}}
+	blendLocalMatchResults(&matrix, local, MatrixBlend{LocalWeight: 2, Interval: matrixIntervalWilson})
+
+	cell := matrix.Matchups["affinity"]["elves"]
+	// (0.4*100 + 2*9.5) / (100 + 2*10)
+	if cell.Matches != 120 || cell.WR != 0.49 {
+		t.Fatalf("blended cell = %+v, want 120 matches at 0.49", cell)
+	}
+	if cell.Sources == nil || cell.Sources.Mtgdecks == nil || cell.Sources.Local == nil {
+		t.Fatalf("blended cell sources = %+v", cell.Sources)
+	}
+	if cell.Sources.Mtgdecks.Matches != 100 || cell.Sources.Local.Matches != 10 || cell.Sources.Local.WR != 0.95 {
+		t.Fatalf("unexpected source breakdown %+v / %+v", cell.Sources.Mtgdecks, cell.Sources.Local)
+	}
+	if cell.CILow >= cell.WR || cell.CIHigh <= cell.WR {
+		t.Fatalf("interval [%f, %f] does not contain %f", cell.CILow, cell.CIHigh, cell.WR)
+	}
+
+	untouched := matrix.Matchups["affinity"]["tron"]
+	if untouched.WR != 0.2 || untouched.CIHigh != 0.52 || untouched.Sources.Local != nil {
+		t.Fatalf("imported-only cell changed: %+v", untouched)
+	}
+	if _, ok := matrix.Matchups["elves"]["affinity"]; !ok {
+		t.Fatal("local-only cell missing")
+	}
+	if total := matrix.Totals["affinity"]; total.Matches != 130 || total.Wins != 61 {
+		t.Fatalf("affinity total = %+v, want 61/130", total)
+	}
+}
+
+func TestWriteBattleboxMatrixBlendsLocalResults(t *testing.T) {
+	dataDir := t.TempDir()
+	outputDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dataDir, "pauper"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	imported := `{"format":"pauper","source":"https://mtgdecks.net/Pauper/winrates","matchups":{"affinity":{"elves":{"ci_high":0.5,"ci_low":0.31,"matches":100,"wr":0.4}}}}`
+	if err := os.WriteFile(filepath.Join(dataDir, "pauper", mtgdecksMatrixFileName), []byte(imported), 0644); err != nil {
+		t.Fatalf("write matrix: %v", err)
+	}
+	local := `{"battlebox":"pauper","matchups":{"affinity":{"elves":{"matches":2,"wins":2,"losses":0,"draws":0}}}}`
+	if err := os.WriteFile(filepath.Join(dataDir, "pauper", localMatchResultsFileName), []byte(local), 0644); err != nil {
+		t.Fatalf("write local results: %v", err)
+	}
+
+	if err := writeBattleboxMatrix(dataDir, outputDir, "pauper", []string{"affinity", "elves"}, MatrixBlend{LocalWeight: 1, Interval: matrixIntervalBayes}); err != nil {
+		t.Fatalf("writeBattleboxMatrix: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(outputDir, "pauper", "winrate.json"))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	var out struct {
+		Source     string                            `json:"source"`
+		Matchups   map[string]map[string]WinrateCell `json:"matchups"`
+		LocalBlend MatrixBlend                       `json:"local_blend"`
+		Field      map[string]DeckFieldWinrate       `json:"field"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("parse output: %v", err)
+	}
+	if out.Source == "" {
+		t.Fatal("imported fields dropped")
+	}
+	if cell := out.Matchups["affinity"]["elves"]; cell.Matches != 102 || cell.Sources == nil || cell.Sources.Local == nil {
+		t.Fatalf("blended cell = %+v", cell)
+	}
+	if out.LocalBlend.Interval != matrixIntervalBayes {
+		t.Fatalf("local_blend = %+v", out.LocalBlend)
+	}
+	if field, ok := out.Field["affinity"]; !ok || field.Rank == 0 || field.Tier == "" {
+		t.Fatalf("field winrate = %+v", out.Field)
+	}
+
+	wrong := `{"battlebox":"premodern","matchups":{}}`
+	if err := os.WriteFile(filepath.Join(dataDir, "pauper", localMatchResultsFileName), []byte(wrong), 0644); err != nil {
+		t.Fatalf("write local results: %v", err)
+	}
+	if err := writeBattleboxMatrix(dataDir, outputDir, "pauper", []string{"affinity", "elves"}, MatrixBlend{LocalWeight: 1, Interval: matrixIntervalWilson}); err == nil {
+		t.Fatal("expected results for another battlebox to be rejected")
+	}
+}
+
+func TestCheckWinrateMatrix(t *testing.T) {
+	matrix := &WinrateMatrix{Matchups: map[string]map[string]WinrateCell{
+		"affinity": {
+			"elves": {WR: 0.6, Matches: 40},
+			"tron":  {WR: 0.45, Matches: 12},
+			"burn":  {WR: 0.5, Matches: 30},
+		},
+		"elves": {"affinity": {WR: 0.6, Matches: 40}},
+		"tron":  {"affinity": {WR: 0.55, Matches: 10}},
+	}}
+	warnings := checkWinrateMatrix("pauper", matrix, []string{"affinity", "elves", "tron"})
+	want := []string{
+		"Winrate matrix pair does not complement (pauper: affinity vs burn): no reverse cell",
+		"Winrate matrix pair does not complement (pauper: affinity vs elves): winrates 0.60 and 0.60 do not sum to 1",
+		"Winrate matrix pair does not complement (pauper: affinity vs tron): match counts 12 and 10 differ",
+		"Winrate matrix slug is not a deck (pauper): burn",
+	}
+	got := sortedValidationWarningStrings(warnings)
+	if len(got) != len(want) {
+		t.Fatalf("warnings = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("warning %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestAnnotateWinrateMatrix(t *testing.T) {
+	matrix := &WinrateMatrix{Matchups: map[string]map[string]WinrateCell{
+		"affinity": {"elves": {WR: 0.6, Matches: 40}, "tron": {WR: 0.56, Matches: 12}, "burn": {WR: 0.1, Matches: 90}},
+		"elves":    {"affinity": {WR: 0.4, Matches: 40}, "tron": {WR: 0.5, Matches: 30}},
+		"tron":     {"affinity": {WR: 0.44, Matches: 12}, "elves": {WR: 0.5, Matches: 30}},
+	}}
+	annotateWinrateMatrix(matrix, []string{"affinity", "elves", "tron"})
+
+	if !matrix.Matchups["affinity"]["tron"].LowSample || matrix.Matchups["affinity"]["elves"].LowSample {
+		t.Fatalf("low-sample flags wrong: %+v", matrix.Matchups["affinity"])
+	}
+	affinity := matrix.Field["affinity"]
+	if affinity.WR != 0.58 || affinity.Opponents != 2 || affinity.LowSample != 1 || affinity.Rank != 1 || affinity.Tier != "S" {
+		t.Fatalf("affinity field = %+v, want 0.58 over 2 opponents ranked 1 in tier S", affinity)
+	}
+	if elves := matrix.Field["elves"]; elves.Rank != 3 || elves.Tier != "C" {
+		t.Fatalf("elves field = %+v, want rank 3 tier C", elves)
+	}
+	if _, ok := matrix.Field["burn"]; ok {
+		t.Fatal("non-battlebox decks get no field winrate")
+	}
+}
+
+func TestWinrateMatrixHistory(t *testing.T) {
+	dataDir := t.TempDir()
+	outputDir := t.TempDir()
+	bbPath := filepath.Join(dataDir, "pauper")
+	if err := os.MkdirAll(bbPath, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	srcPath := filepath.Join(bbPath, mtgdecksMatrixFileName)
+	writeMatrix := func(body string) {
+		t.Helper()
+		if err := os.WriteFile(srcPath, []byte(body), 0644); err != nil {
+			t.Fatalf("write matrix: %v", err)
+		}
+	}
+
+	writeMatrix(`{"fetched_at":"2026-04-13T03:57:03Z","matchups":{"affinity":{"elves":{"ci_high":0.5,"ci_low":0.3,"matches":100,"wr":0.4},"tron":{"ci_high":0.7,"ci_low":0.3,"matches":20,"wr":0.5}}}}`)
+	first, err := snapshotWinrateMatrix(bbPath)
+	if err != nil {
+		t.Fatalf("snapshotWinrateMatrix: %v", err)
+	}
+	if filepath.Base(first) != "20260413T035703Z.json" {
+		t.Fatalf("snapshot path = %q", first)
+	}
+	if again, err := snapshotWinrateMatrix(bbPath); err != nil || again != "" {
+		t.Fatalf("repeat snapshot = %q, %v; want no new snapshot", again, err)
+	}
+
+	// A partial refresh dates the snapshot by its newest cell update.
+	writeMatrix(`{"fetched_at":"2026-04-13T03:57:03Z","matchups":{"affinity":{"elves":{"ci_high":0.55,"ci_low":0.4,"matches":140,"wr":0.47},"tron":{"ci_high":0.7,"ci_low":0.3,"matches":20,"wr":0.5}}},"cell_updates":{"affinity":{"elves":"2026-05-15T06:02:44Z"}}}`)
+	second, err := snapshotWinrateMatrix(bbPath)
+	if err != nil {
+		t.Fatalf("snapshotWinrateMatrix: %v", err)
+	}
+	if filepath.Base(second) != "20260515T060244Z.json" {
+		t.Fatalf("snapshot path = %q", second)
+	}
+
+	// Changed content under an existing refresh time never overwrites it.
+	writeMatrix(`{"fetched_at":"2026-04-13T03:57:03Z","matchups":{"affinity":{"elves":{"ci_high":0.6,"ci_low":0.4,"matches":150,"wr":0.5},"tron":{"ci_high":0.7,"ci_low":0.3,"matches":20,"wr":0.5}}},"cell_updates":{"affinity":{"elves":"2026-05-15T06:02:44Z"}}}`)
+	if path, err := snapshotWinrateMatrix(bbPath); err == nil {
+		t.Fatalf("conflicting snapshot = %q, want an error", path)
+	}
+	if kept, err := os.ReadFile(second); err != nil || !strings.Contains(string(kept), `"matches":140`) {
+		t.Fatalf("existing snapshot changed: %s, %v", kept, err)
+	}
+
+	if err := writeBattleboxMatrixHistory(dataDir, outputDir, "pauper"); err != nil {
+		t.Fatalf("writeBattleboxMatrixHistory: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(outputDir, "pauper", "winrate-history.json"))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	var history WinrateHistory
+	if err := json.Unmarshal(data, &history); err != nil {
+		t.Fatalf("parse output: %v", err)
+	}
+	if len(history.Snapshots) != 2 || history.Snapshots[1] != "2026-05-15T06:02:44Z" {
+		t.Fatalf("snapshots = %v", history.Snapshots)
+	}
+	elves := history.Matchups["affinity"]["elves"]
+	if len(elves.Points) != 2 || elves.DeltaWR != 0.07 || elves.DeltaMatches != 40 {
+		t.Fatalf("affinity vs elves history = %+v, want two points moving +0.07 over 40 matches", elves)
+	}
+	if tron := history.Matchups["affinity"]["tron"]; len(tron.Points) != 1 || tron.DeltaWR != 0 {
+		t.Fatalf("unchanged cell history = %+v, want a single point", tron)
+	}
+
+	battleboxDirs, err := os.ReadDir(dataDir)
+	if err != nil {
+		t.Fatalf("read data dir: %v", err)
+	}
+	sources := loadBuildSources(dataDir, nil, battleboxDirs)
+	if slugs := sources.Battleboxes[0].DeckSlugs(); len(slugs) != 0 {
+		t.Fatalf("matrix history read as decks: %v", slugs)
+	}
+}
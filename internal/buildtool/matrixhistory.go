@@ -0,0 +1,189 @@
+package buildtool
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Each refresh of mtgdecks-winrate-matrix.json is kept as a dated snapshot in
+// data/<battlebox>/matrix-history/. A snapshot is dated by the matrix's latest
+// refresh: fetched_at, or a newer cell_updates/point_updates time when only part
+// of the matrix was re-imported.
+
+const matrixSnapshotTimeFormat = "20060102T150405Z"
+
+// matrixUpdatedAt returns the latest refresh time recorded in matrix.
+func matrixUpdatedAt(matrix *WinrateMatrix) (time.Time, bool) {
+	var latest time.Time
+	consider := func(value string) {
+		t, err := time.Parse(time.RFC3339, value)
+		if err == nil && t.After(latest) {
+			latest = t
+		}
+	}
+	consider(matrix.FetchedAt)
+	for _, updatedAt := range matrix.PointUpdates {
+		consider(updatedAt)
+	}
+	for _, cells := range matrix.CellUpdates {
+		for _, updatedAt := range cells {
+			consider(updatedAt)
+		}
+	}
+	return latest.UTC(), !latest.IsZero()
+}
+
+// snapshotWinrateMatrix copies the battlebox's imported matrix into
+// matrix-history/ unless that refresh is already there. It returns the path
+// written, or "" when nothing changed. A snapshot of the same refresh time with
+// different content is an error rather than overwritten, since the history
+// would silently lose a point.
+func snapshotWinrateMatrix(bbPath string) (string, error) {
+	srcPath := filepath.Join(bbPath, mtgdecksMatrixFileName)
+	if !fileExists(srcPath) {
+		return "", nil
+	}
+	matrix, err := loadWinrateMatrix(srcPath)
+	if err != nil {
+		return "", fmt.Errorf("reading source matrix %s: %w", srcPath, err)
+	}
+	updatedAt, ok := matrixUpdatedAt(matrix)
+	if !ok {
+		return "", nil
+	}
+	data, err := buildFiles.ReadFile(srcPath)
+	if err != nil {
+		return "", err
+	}
+
+	snapshotPath := filepath.Join(bbPath, matrixHistoryDirName, updatedAt.Format(matrixSnapshotTimeFormat)+".json")
+	if existing, err := buildFiles.ReadFile(snapshotPath); err == nil {
+		if bytes.Equal(existing, data) {
+			return "", nil
+		}
+		return "", fmt.Errorf("matrix snapshot %s already exists with different content; the matrix changed without a newer fetched_at or cell update time", snapshotPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(snapshotPath), 0755); err != nil {
+		return "", fmt.Errorf("creating matrix history directory: %w", err)
+	}
+	if err := os.WriteFile(snapshotPath, data, 0644); err != nil {
+		return "", fmt.Errorf("writing matrix snapshot %s: %w", snapshotPath, err)
+	}
+	return snapshotPath, nil
+}
+
+// snapshotWinrateMatrices snapshots every battlebox's imported matrix. It runs
+// before build planning so new snapshots count toward this build's matrix hash.
+func snapshotWinrateMatrices(sources BuildSources) error {
+	for _, bbSource := range sources.Battleboxes {
+		snapshotPath, err := snapshotWinrateMatrix(bbSource.Path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", bbSource.Slug, err)
+		}
+		if snapshotPath != "" {
+			fmt.Printf("Snapshot: %s\n", snapshotPath)
+		}
+	}
+	return nil
+}
+
+// listMatrixSnapshots returns the battlebox's snapshot paths, oldest first.
+func listMatrixSnapshots(bbPath string) ([]string, error) {
+	entries, err := buildFiles.ReadDir(filepath.Join(bbPath, matrixHistoryDirName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var paths []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		paths = append(paths, filepath.Join(bbPath, matrixHistoryDirName, entry.Name()))
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// buildWinrateHistory follows every cell across snapshots, which must be in
+// date order.
+func buildWinrateHistory(snapshots []*WinrateMatrix) WinrateHistory {
+	history := WinrateHistory{
+		Snapshots: []string{},
+		Matchups:  map[string]map[string]WinrateMatchupHistory{},
+	}
+	for _, snapshot := range snapshots {
+		updatedAt, _ := matrixUpdatedAt(snapshot)
+		at := updatedAt.Format(time.RFC3339)
+		history.Snapshots = append(history.Snapshots, at)
+		for deck, opponents := range snapshot.Matchups {
+			row := history.Matchups[deck]
+			if row == nil {
+				row = map[string]WinrateMatchupHistory{}
+				history.Matchups[deck] = row
+			}
+			for opponent, cell := range opponents {
+				cellHistory := row[opponent]
+				if n := len(cellHistory.Points); n > 0 {
+					last := cellHistory.Points[n-1]
+					if last.WR == cell.WR && last.Matches == cell.Matches {
+						continue
+					}
+				}
+				cellHistory.Points = append(cellHistory.Points, WinrateHistoryPoint{At: at, WR: cell.WR, Matches: cell.Matches})
+				first := cellHistory.Points[0]
+				cellHistory.DeltaWR = roundTo(cell.WR-first.WR, 4)
+				cellHistory.DeltaMatches = cell.Matches - first.Matches
+				row[opponent] = cellHistory
+			}
+		}
+	}
+	return history
+}
+
+// writeBattleboxMatrixHistory writes winrate-history.json from the battlebox's
+// matrix snapshots, or removes it when there are none.
+func writeBattleboxMatrixHistory(dataDir, outputDir, slug string) error {
+	outPath := filepath.Join(outputDir, slug, "winrate-history.json")
+	snapshotPaths, err := listMatrixSnapshots(filepath.Join(dataDir, slug))
+	if err != nil {
+		return fmt.Errorf("listing matrix snapshots: %w", err)
+	}
+	if len(snapshotPaths) == 0 {
+		if err := removeJSONAndGzip(outPath); err != nil {
+			return fmt.Errorf("removing stale matrix history %s: %w", outPath, err)
+		}
+		return nil
+	}
+
+	snapshots := make([]*WinrateMatrix, 0, len(snapshotPaths))
+	for _, path := range snapshotPaths {
+		snapshot, err := loadWinrateMatrix(path)
+		if err != nil {
+			return fmt.Errorf("reading matrix snapshot %s: %w", path, err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	jsonData, err := json.Marshal(buildWinrateHistory(snapshots))
+	if err != nil {
+		return fmt.Errorf("marshaling matrix history %s: %w", outPath, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+		return fmt.Errorf("creating matrix output directory: %w", err)
+	}
+	gzipSize, err := writeJSONAndGzip(outPath, jsonData)
+	if err != nil {
+		return fmt.Errorf("writing matrix history %s: %w", outPath, err)
+	}
+	fmt.Printf("Written: %s (%d bytes), %s.gz (%d bytes)\n", outPath, len(jsonData), outPath, gzipSize)
+	return nil
+}
@@ -0,0 +1,104 @@
+package buildtool
+
+import (
+	"math"
+)
+
+// Draw odds cover turns 1 through deckOddsTurns.
+const deckOddsTurns = 6
+
+// logChoose returns ln C(n, k).
+func logChoose(n, k int) float64 {
+	a, _ := math.Lgamma(float64(n + 1))
+	b, _ := math.Lgamma(float64(k + 1))
+	c, _ := math.Lgamma(float64(n - k + 1))
+	return a - b - c
+}
+
+// hypergeometric returns the chance of exactly k successes in a sample of n
+// cards from a population of size containing successes.
+func hypergeometric(size, successes, n, k int) float64 {
+	if k < 0 || k > successes || k > n || n-k > size-successes {
+		return 0
+	}
+	return math.Exp(logChoose(successes, k) + logChoose(size-successes, n-k) - logChoose(size, n))
+}
+
+// hypergeometricAtLeast returns the chance of at least k successes.
+func hypergeometricAtLeast(size, successes, n, k int) float64 {
+	var p float64
+	for i := max(k, 0); i <= min(successes, n); i++ {
+		p += hypergeometric(size, successes, n, i)
+	}
+	return min(p, 1)
+}
+
+// turnOdds returns the chance of at least k successes seen by each turn.
+// atLeast returns the count needed on a turn.
+func turnOdds(size, successes, handSize int, atLeast func(turn int) int) []TurnOdds {
+	odds := make([]TurnOdds, 0, deckOddsTurns)
+	for turn := 1; turn <= deckOddsTurns; turn++ {
+		onPlay := min(handSize+turn-1, size)
+		onDraw := min(handSize+turn, size)
+		odds = append(odds, TurnOdds{
+			Turn:   turn,
+			OnPlay: roundTo(hypergeometricAtLeast(size, successes, onPlay, atLeast(turn)), 4),
+			OnDraw: roundTo(hypergeometricAtLeast(size, successes, onDraw, atLeast(turn)), 4),
+		})
+	}
+	return odds
+}
+
+// computeDeckOdds returns draw odds for a mainboard sampled as handSize-card
+// hands, or nil when the deck is smaller than a hand.
+func computeDeckOdds(cards []Card, handSize int) *DeckOdds {
+	deckSize := countCards(cards)
+	if handSize <= 0 || deckSize < handSize {
+		return nil
+	}
+	lands := 0
+	for _, card := range cards {
+		if card.Type == "land" {
+			lands += card.Qty
+		}
+	}
+
+	odds := &DeckOdds{
+		DeckSize:         deckSize,
+		Lands:            lands,
+		HandSize:         handSize,
+		OpeningHandLands: make([]float64, 0, handSize+1),
+		Cards:            []CardDrawOdds{},
+	}
+	for k := 0; k <= handSize; k++ {
+		p := hypergeometric(deckSize, lands, handSize, k)
+		odds.OpeningHandLands = append(odds.OpeningHandLands, roundTo(p, 4))
+		if k >= 2 && k <= 4 {
+			odds.OpeningHand2To4Lands += p
+		}
+	}
+	odds.OpeningHand2To4Lands = roundTo(odds.OpeningHand2To4Lands, 4)
+	odds.LandDrops = turnOdds(deckSize, lands, handSize, func(turn int) int { return turn })
+
+	// Lines naming the same card (for example two printings) count together.
+	var names []string
+	copiesByName := map[string]int{}
+	for _, card := range cards {
+		if card.Type == "land" || card.Qty <= 0 {
+			continue
+		}
+		if _, seen := copiesByName[card.Name]; !seen {
+			names = append(names, card.Name)
+		}
+		copiesByName[card.Name] += card.Qty
+	}
+	for _, name := range names {
+		copies := copiesByName[name]
+		odds.Cards = append(odds.Cards, CardDrawOdds{
+			Name:   name,
+			Copies: copies,
+			ByTurn: turnOdds(deckSize, copies, handSize, func(int) int { return 1 }),
+		})
+	}
+	return odds
+}
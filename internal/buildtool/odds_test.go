@@ -0,0 +1,59 @@
+package buildtool
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHypergeometric(t *testing.T) {
+	// 17 lands in 40 cards, 7-card hand: the classic limited numbers.
+	if p := hypergeometric(40, 17, 7, 3); math.Abs(p-0.3230) > 0.0005 {
+		t.Fatalf("P(3 lands) = %f, want about 0.3230", p)
+	}
+	var total float64
+	for k := 0; k <= 7; k++ {
+		total += hypergeometric(40, 17, 7, k)
+	}
+	if math.Abs(total-1) > 1e-9 {
+		t.Fatalf("distribution sums to %f", total)
+	}
+	if p := hypergeometric(10, 2, 5, 3); p != 0 {
+		t.Fatalf("P(3 of 2 successes) = %f, want 0", p)
+	}
+}
+
+func TestComputeDeckOdds(t *testing.T) {
+	cards := []Card{
+		{Name: "Island", Qty: 24, Type: "land"},
+		{Name: "Counterspell", Qty: 4, Type: "spell"},
+		{Name: "Brainstorm", Qty: 2, Type: "spell", Printing: "ice/61"},
+		{Name: "Brainstorm", Qty: 2, Type: "spell", Printing: "mmq/61"},
+		{Name: "Ponder", Qty: 28, Type: "spell"},
+	}
+	odds := computeDeckOdds(cards, 7)
+	if odds == nil || odds.DeckSize != 60 || odds.Lands != 24 || len(odds.OpeningHandLands) != 8 {
+		t.Fatalf("odds = %+v", odds)
+	}
+	if math.Abs(odds.OpeningHand2To4Lands-0.7746) > 0.0005 {
+		t.Fatalf("2-4 lands = %f, want about 0.7746", odds.OpeningHand2To4Lands)
+	}
+	if len(odds.LandDrops) != deckOddsTurns || odds.LandDrops[0].Turn != 1 {
+		t.Fatalf("land drops = %+v", odds.LandDrops)
+	}
+	for _, turn := range odds.LandDrops {
+		if turn.OnDraw < turn.OnPlay {
+			t.Fatalf("turn %d: on the draw %f below on the play %f", turn.Turn, turn.OnDraw, turn.OnPlay)
+		}
+	}
+	if len(odds.Cards) != 3 || odds.Cards[1].Name != "Brainstorm" || odds.Cards[1].Copies != 4 {
+		t.Fatalf("card odds = %+v, want printings of one card merged", odds.Cards)
+	}
+	// Four copies in 60 cards: 1 - C(56,7)/C(60,7) in the opening hand.
+	if p := odds.Cards[0].ByTurn[0].OnPlay; math.Abs(p-0.3995) > 0.0005 {
+		t.Fatalf("Counterspell by turn 1 on the play = %f, want about 0.3995", p)
+	}
+
+	if small := computeDeckOdds(cards[:1], 30); small != nil {
+		t.Fatalf("deck smaller than a hand got odds %+v", small)
+	}
+}
@@ -36,13 +36,14 @@ func buildIndexOutput(sources BuildSources, deckWarningAnnotations map[string]ma
 			if deckSource.ManifestErr != nil {
 				return indexOutput, fmt.Errorf("reading manifest %s: %w", filepath.Join(deckSource.Path, "manifest.json"), deckSource.ManifestErr)
 			}
-			manifest := deckSource.Manifest
+			manifest := cloneManifest(deckSource.Manifest)
 
 			uiProfile, err := resolveDeckUIProfile(manifest, bbManifest)
 			if err != nil {
 				return indexOutput, fmt.Errorf("resolving ui profile for %s: %w", filepath.Join(deckSource.Path, "manifest.json"), err)
 			}
 			cardCount := countCards(manifest.Cards)
+			enrichManifestCards(&manifest, bbSource.Slug, deckSource.Slug, deckSource.MergedPrintings, bbManifest.LandSubtypes, nil)
 
 			indexEntry.Decks = append(indexEntry.Decks, DeckIndex{
 				Slug:           deckSource.Slug,
@@ -53,6 +54,7 @@ func buildIndexOutput(sources BuildSources, deckWarningAnnotations map[string]ma
 				DifficultyTags: normalizeDifficultyTags(manifest.DifficultyTags),
 				UI:             uiProfile,
 				CardCount:      cardCount,
+				Stats:          summarizeDeckStats(computeDeckStats(manifest.Cards, manifest.Sideboard)),
 			})
 		}
 
@@ -96,34 +98,46 @@ func removeJSONAndGzip(path string) error {
 	return nil
 }
 
-func writeBattleboxMatrix(dataDir, outputDir, slug string) error {
-	srcPath := filepath.Join(dataDir, slug, "mtgdecks-winrate-matrix.json")
+// writeBattleboxMatrix writes winrate.json from the imported matrix and any
+// local match results, with low-sample flags and field winrates for deckSlugs.
+func writeBattleboxMatrix(dataDir, outputDir, slug string, deckSlugs []string, blend MatrixBlend) error {
+	srcPath := filepath.Join(dataDir, slug, mtgdecksMatrixFileName)
+	localPath := filepath.Join(dataDir, slug, localMatchResultsFileName)
 	outPath := filepath.Join(outputDir, slug, "winrate.json")
-	legacyOutPath := filepath.Join(outputDir, slug, "mtgdecks-winrate-matrix.json")
+	legacyOutPath := filepath.Join(outputDir, slug, mtgdecksMatrixFileName)
 
 	if err := removeJSONAndGzip(legacyOutPath); err != nil {
 		return fmt.Errorf("removing legacy matrix %s: %w", legacyOutPath, err)
 	}
 
-	if !fileExists(srcPath) {
+	if !fileExists(srcPath) && !fileExists(localPath) {
 		if err := removeJSONAndGzip(outPath); err != nil {
 			return fmt.Errorf("removing stale matrix %s: %w", outPath, err)
 		}
 		return nil
 	}
 
-	srcData, err := buildFiles.ReadFile(srcPath)
-	if err != nil {
-		return fmt.Errorf("reading source matrix %s: %w", srcPath, err)
+	matrix := &WinrateMatrix{}
+	if fileExists(srcPath) {
+		imported, err := loadWinrateMatrix(srcPath)
+		if err != nil {
+			return fmt.Errorf("reading source matrix %s: %w", srcPath, err)
+		}
+		matrix = imported
 	}
-
-	var payload map[string]any
-	if err := json.Unmarshal(srcData, &payload); err != nil {
-		return fmt.Errorf("parsing source matrix %s: %w", srcPath, err)
+	if fileExists(localPath) {
+		local, err := loadLocalMatchResults(localPath, slug)
+		if err != nil {
+			return fmt.Errorf("reading local match results %s: %w", localPath, err)
+		}
+		blendLocalMatchResults(matrix, local, blend)
+		matrix.LocalBlend = &blend
 	}
-	jsonData, err := json.Marshal(payload)
+	annotateWinrateMatrix(matrix, deckSlugs)
+
+	jsonData, err := json.Marshal(matrix)
 	if err != nil {
-		return fmt.Errorf("marshaling matrix %s: %w", srcPath, err)
+		return fmt.Errorf("marshaling matrix %s: %w", outPath, err)
 	}
 
 	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
@@ -47,6 +47,7 @@ func loadBattleboxManifest(path string) BattleboxManifest {
 		}
 		manifest.Banned = banned
 	}
+	manifest.Rules.MaxRarity = normalizeName(manifest.Rules.MaxRarity)
 	if len(manifest.LandSubtypes) > 0 {
 		normalized := make(map[string]string, len(manifest.LandSubtypes))
 		for name, subtype := range manifest.LandSubtypes {
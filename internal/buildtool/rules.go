@@ -0,0 +1,173 @@
+package buildtool
+
+import (
+	"cmp"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Deck rules are declared in the battlebox manifest and may be overridden per
+// deck. They are evaluated for every deck and staged deck in dev builds and
+// reported as validation warnings and deck annotations.
+
+const defaultMainboardSize = 60
+
+// rarityRank orders Scryfall rarities for max_rarity checks.
+var rarityRank = map[string]int{
+	"common":   0,
+	"uncommon": 1,
+	"rare":     2,
+	"special":  3,
+	"mythic":   3,
+	"bonus":    3,
+}
+
+// isBasicLandName reports whether any number of copies of name is allowed.
+func isBasicLandName(name string) bool {
+	key := strings.TrimPrefix(normalizeName(name), "snow-covered ")
+	if _, ok := basicLandPrintingKeys[key]; ok {
+		return true
+	}
+	return key == "wastes"
+}
+
+// resolveDeckRules applies a deck's overrides to its battlebox rules. Sizes,
+// copy limits and rarity override when set; singleton is on when either sets
+// it; restricted lists are combined.
+func resolveDeckRules(battlebox DeckRules, deck *DeckRules) DeckRules {
+	rules := battlebox
+	rules.Restricted = append([]string(nil), battlebox.Restricted...)
+	if deck != nil {
+		if deck.MainboardSize > 0 {
+			rules.MainboardSize = deck.MainboardSize
+		}
+		if deck.MaxSideboardSize > 0 {
+			rules.MaxSideboardSize = deck.MaxSideboardSize
+		}
+		if deck.MaxCopies > 0 {
+			rules.MaxCopies = deck.MaxCopies
+		}
+		if deck.MaxRarity != "" {
+			rules.MaxRarity = deck.MaxRarity
+		}
+		rules.Singleton = rules.Singleton || deck.Singleton
+		rules.Restricted = append(rules.Restricted, deck.Restricted...)
+	}
+	if rules.MainboardSize <= 0 {
+		rules.MainboardSize = defaultMainboardSize
+	}
+	return rules
+}
+
+// withinMaxRarity reports whether a card meets maxRarity. Rarity is a property
+// of the card rather than the printing chosen for display: a card with a common
+// printing anywhere, per its pauper legality, meets every limit. Otherwise the
+// cached printing's rarity decides.
+func withinMaxRarity(meta cardMeta, maxRarity string) bool {
+	if meta.PauperLegality != "" && meta.PauperLegality != "not_legal" {
+		return true
+	}
+	return rarityRank[meta.Rarity] <= rarityRank[maxRarity]
+}
+
+// checkDeckRules evaluates enriched mainboard and sideboard cards against
+// rules and a banned list, returning one message per violation.
+func checkDeckRules(rules DeckRules, banned []string, mainboard, sideboard []Card) []string {
+	var messages []string
+	if total := countCards(mainboard); total != rules.MainboardSize {
+		messages = append(messages, fmt.Sprintf("mainboard has %d cards (expected %d)", total, rules.MainboardSize))
+	}
+	if total := countCards(sideboard); rules.MaxSideboardSize > 0 && total > rules.MaxSideboardSize {
+		messages = append(messages, fmt.Sprintf("sideboard has %d cards (max %d)", total, rules.MaxSideboardSize))
+	}
+
+	copies := map[string]int{}
+	names := map[string]string{}
+	var keys []string
+	for _, card := range append(append([]Card(nil), mainboard...), sideboard...) {
+		key := normalizeName(card.Name)
+		if key == "" || card.Qty <= 0 {
+			continue
+		}
+		if _, ok := names[key]; !ok {
+			names[key] = card.Name
+			keys = append(keys, key)
+		}
+		copies[key] += card.Qty
+
+		if rules.MaxRarity == "" || isBasicLandName(card.Name) {
+			continue
+		}
+		meta, ok := cardCache[card.Printing]
+		if !ok || meta.Rarity == "" || withinMaxRarity(meta, rules.MaxRarity) {
+			continue
+		}
+		if rules.MaxRarity == "common" {
+			messages = append(messages, fmt.Sprintf("%s has no common printing", card.Name))
+		} else {
+			messages = append(messages, fmt.Sprintf("%s printing %s is %s (max %s)", card.Name, card.Printing, meta.Rarity, rules.MaxRarity))
+		}
+	}
+	sort.Strings(keys)
+
+	bannedKeys := map[string]bool{}
+	for _, name := range banned {
+		bannedKeys[normalizeName(name)] = true
+	}
+	restrictedKeys := map[string]bool{}
+	for _, name := range rules.Restricted {
+		restrictedKeys[normalizeName(name)] = true
+	}
+	maxCopies := rules.MaxCopies
+	if rules.Singleton {
+		maxCopies = 1
+	}
+	for _, key := range keys {
+		name, qty := names[key], copies[key]
+		switch {
+		case bannedKeys[key]:
+			messages = append(messages, fmt.Sprintf("%s is banned", name))
+		case restrictedKeys[key] && qty > 1:
+			messages = append(messages, fmt.Sprintf("%s is restricted (%d copies)", name, qty))
+		case maxCopies > 0 && qty > maxCopies && !isBasicLandName(name):
+			messages = append(messages, fmt.Sprintf("%s has %d copies (max %d)", name, qty, maxCopies))
+		}
+	}
+	return messages
+}
+
+// collectDeckRuleWarnings checks a deck and its staged manifest, if any. A
+// staged manifest without its own rules block keeps the live deck's overrides.
+func collectDeckRuleWarnings(bbSource BattleboxSource, deckSource DeckSource) ([]ValidationWarning, []string, []string) {
+	check := func(manifest Manifest, printings map[string]string) []string {
+		rules := resolveDeckRules(bbSource.Manifest.Rules, cmp.Or(manifest.Rules, deckSource.Manifest.Rules))
+		manifest = cloneManifest(manifest)
+		enrichManifestCards(&manifest, bbSource.Slug, deckSource.Slug, printings, bbSource.Manifest.LandSubtypes, nil)
+		return checkDeckRules(rules, bbSource.Manifest.Banned, manifest.Cards, manifest.Sideboard)
+	}
+
+	var warnings []ValidationWarning
+	messages := check(deckSource.Manifest, deckSource.MergedPrintings)
+	for _, message := range messages {
+		warnings = append(warnings, ValidationWarning{
+			Kind:      ValidationWarningDeckRule,
+			Battlebox: bbSource.Slug,
+			Deck:      deckSource.Slug,
+			Detail:    message,
+		})
+	}
+	var stagedMessages []string
+	if deckSource.HasStaged && deckSource.StagedErr == nil {
+		stagedMessages = check(deckSource.StagedManifest, mergePrintings(deckSource.MergedPrintings, deckSource.StagedPrintings))
+		for _, message := range stagedMessages {
+			warnings = append(warnings, ValidationWarning{
+				Kind:      ValidationWarningStagedDeckRule,
+				Battlebox: bbSource.Slug,
+				Deck:      deckSource.Slug,
+				Detail:    message,
+			})
+		}
+	}
+	return warnings, messages, stagedMessages
+}
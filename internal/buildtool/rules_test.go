@@ -0,0 +1,93 @@
+package buildtool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveDeckRules(t *testing.T) {
+	battlebox := DeckRules{MaxCopies: 1, Restricted: []string{"Black Lotus"}, MaxRarity: "common"}
+	rules := resolveDeckRules(battlebox, &DeckRules{MainboardSize: 360, MaxCopies: 2, Restricted: []string{"Sol Ring"}})
+	want := DeckRules{MainboardSize: 360, MaxCopies: 2, Restricted: []string{"Black Lotus", "Sol Ring"}, MaxRarity: "common"}
+	if !reflect.DeepEqual(rules, want) {
+		t.Fatalf("rules = %+v, want %+v", rules, want)
+	}
+	if len(battlebox.Restricted) != 1 {
+		t.Fatalf("deck overrides leaked into the battlebox rules: %v", battlebox.Restricted)
+	}
+	if rules := resolveDeckRules(DeckRules{}, nil); rules.MainboardSize != defaultMainboardSize {
+		t.Fatalf("mainboard size defaults to %d, got %d", defaultMainboardSize, rules.MainboardSize)
+	}
+}
+
+func TestCheckDeckRules(t *testing.T) {
+	previous := cardCache
+	cardCache = map[string]cardMeta{
+		"lea/232": {Rarity: "rare", PauperLegality: "not_legal"},
+		"ice/61":  {Rarity: "common", PauperLegality: "legal"},
+		"lea/54":  {Rarity: "uncommon", PauperLegality: "legal"},
+	}
+	t.Cleanup(func() { cardCache = previous })
+
+	rules := DeckRules{MainboardSize: 60, MaxSideboardSize: 15, MaxCopies: 4, Restricted: []string{"Brainstorm"}, MaxRarity: "common"}
+	mainboard := []Card{
+		{Name: "Snow-Covered Island", Qty: 20},
+		{Name: "Brainstorm", Qty: 2, Printing: "ice/61"},
+		{Name: "Counterspell", Qty: 4, Printing: "lea/54"},
+		{Name: "Time Walk", Qty: 1, Printing: "lea/232"},
+		{Name: "Gush", Qty: 4},
+	}
+	sideboard := []Card{
+		{Name: "Counterspell", Qty: 1},
+		{Name: "Hydroblast", Qty: 16},
+	}
+	got := checkDeckRules(rules, []string{"gush"}, mainboard, sideboard)
+	want := []string{
+		"mainboard has 31 cards (expected 60)",
+		"sideboard has 17 cards (max 15)",
+		"Time Walk has no common printing",
+		"Brainstorm is restricted (2 copies)",
+		"Counterspell has 5 copies (max 4)",
+		"Gush is banned",
+		"Hydroblast has 16 copies (max 4)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("messages = %q, want %q", got, want)
+	}
+
+	rules = DeckRules{MainboardSize: 1, MaxRarity: "uncommon"}
+	if got := checkDeckRules(rules, nil, []Card{{Name: "Time Walk", Qty: 1, Printing: "lea/232"}}, nil); !reflect.DeepEqual(got, []string{"Time Walk printing lea/232 is rare (max uncommon)"}) {
+		t.Fatalf("uncommon limit messages = %q", got)
+	}
+
+	rules = DeckRules{MainboardSize: 3, Singleton: true}
+	if got := checkDeckRules(rules, nil, []Card{{Name: "Island", Qty: 2}, {Name: "Opt", Qty: 1}}, nil); len(got) != 0 {
+		t.Fatalf("basics are exempt from singleton, got %q", got)
+	}
+}
+
+func TestCollectDeckRuleWarningsUsesStagedRules(t *testing.T) {
+	bbSource := BattleboxSource{Slug: "cube", Manifest: BattleboxManifest{Rules: DeckRules{MainboardSize: 2}}}
+	deckSource := DeckSource{
+		Slug:           "vintage",
+		Manifest:       Manifest{Cards: []Card{{Name: "Opt", Qty: 2}}},
+		HasStaged:      true,
+		StagedManifest: Manifest{Cards: []Card{{Name: "Opt", Qty: 3}}, Rules: &DeckRules{MainboardSize: 3}},
+	}
+	warnings, messages, stagedMessages := collectDeckRuleWarnings(bbSource, deckSource)
+	if len(warnings) != 0 || len(messages) != 0 || len(stagedMessages) != 0 {
+		t.Fatalf("staged deck should be checked against its own rules, got %q and %q", messages, stagedMessages)
+	}
+
+	// Staged manifests written by -accept-swaps carry no rules block.
+	deckSource.Manifest.Rules = &DeckRules{MainboardSize: 3, MaxCopies: 2}
+	deckSource.Manifest.Cards = []Card{{Name: "Opt", Qty: 3}}
+	deckSource.StagedManifest = Manifest{Cards: []Card{{Name: "Opt", Qty: 1}, {Name: "Ponder", Qty: 2}}}
+	_, messages, stagedMessages = collectDeckRuleWarnings(bbSource, deckSource)
+	if want := []string{"Opt has 3 copies (max 2)"}; !reflect.DeepEqual(messages, want) {
+		t.Fatalf("live messages = %q, want %q", messages, want)
+	}
+	if len(stagedMessages) != 0 {
+		t.Fatalf("staged deck without rules should keep the live overrides, got %q", stagedMessages)
+	}
+}
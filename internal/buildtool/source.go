@@ -32,6 +32,7 @@ type DeckSource struct {
 	MergedPrintings map[string]string
 	GuideFiles      []string
 	PrimerPath      string
+	DraftStatsPath  string
 }
 
 func loadBuildSources(dataDir string, projectPrintings map[string]string, battleboxDirs []os.DirEntry) BuildSources {
@@ -64,7 +65,7 @@ func loadBuildSources(dataDir string, projectPrintings map[string]string, battle
 		}
 
 		for _, deckDir := range deckDirs {
-			if !deckDir.IsDir() {
+			if !deckDir.IsDir() || deckDir.Name() == matrixHistoryDirName {
 				continue
 			}
 			deckSlug := deckDir.Name()
@@ -86,6 +87,7 @@ func loadBuildSources(dataDir string, projectPrintings map[string]string, battle
 				MergedPrintings: mergePrintings(mergedBattleboxPrintings, deckPrintings),
 				GuideFiles:      listGuideFiles(deckPath),
 				PrimerPath:      filepath.Join(deckPath, "primer.md"),
+				DraftStatsPath:  filepath.Join(deckPath, draftStatsFileName),
 			})
 		}
 
@@ -104,6 +106,15 @@ func (s BuildSources) Battlebox(slug string) (BattleboxSource, bool) {
 	return BattleboxSource{}, false
 }
 
+// DeckSlugs lists the battlebox's decks in source order.
+func (s BattleboxSource) DeckSlugs() []string {
+	slugs := make([]string, 0, len(s.Decks))
+	for _, deck := range s.Decks {
+		slugs = append(slugs, deck.Slug)
+	}
+	return slugs
+}
+
 func (s BuildSources) Slugs() []string {
 	out := make([]string, 0, len(s.Battleboxes))
 	for _, bb := range s.Battleboxes {
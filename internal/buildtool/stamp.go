@@ -119,6 +119,10 @@ func hashBattleboxInputs(bbPath string, fileCache map[string]FileFingerprint) (s
 				return err
 			}
 			if d.IsDir() {
+				// Matrix snapshots feed the matrix hash, not the battlebox's.
+				if d.Name() == matrixHistoryDirName {
+					return fs.SkipDir
+				}
 				return nil
 			}
 			ext := strings.ToLower(filepath.Ext(path))
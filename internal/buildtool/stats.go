@@ -0,0 +1,97 @@
+package buildtool
+
+import "strings"
+
+// deckStatsMaxManaValue is the last curve bucket; it also holds every card
+// above it.
+const deckStatsMaxManaValue = 7
+
+// deckStatsLandOther labels lands without a manual land subtype.
+const deckStatsLandOther = "other"
+
+// computeCurve buckets nonland cards by mana value (0 through 7+) and type.
+func computeCurve(cards []Card) []CurveBucket {
+	curve := make([]CurveBucket, deckStatsMaxManaValue+1)
+	for mv := range curve {
+		curve[mv] = CurveBucket{ManaValue: mv, ByType: map[string]int{}}
+	}
+	for _, card := range cards {
+		if card.Type == "land" || card.Qty <= 0 {
+			continue
+		}
+		bucket := &curve[min(max(card.ManaValue, 0), deckStatsMaxManaValue)]
+		bucket.Total += card.Qty
+		bucket.ByType[card.Type] += card.Qty
+	}
+	return curve
+}
+
+// countPips counts coloured mana symbols per copy. Hybrid and Phyrexian
+// symbols count towards every colour they name.
+func countPips(cards []Card) map[string]int {
+	pips := map[string]int{}
+	for _, card := range cards {
+		if card.Qty <= 0 {
+			continue
+		}
+		for _, token := range manaSymbolRE.FindAllStringSubmatch(card.ManaCost, -1) {
+			for _, ch := range strings.ToUpper(token[1]) {
+				switch ch {
+				case 'W', 'U', 'B', 'R', 'G':
+					pips[string(ch)] += card.Qty
+				}
+			}
+		}
+	}
+	return pips
+}
+
+// computeDeckStats summarises enriched mainboard and sideboard cards.
+func computeDeckStats(mainboard, sideboard []Card) *DeckStats {
+	stats := &DeckStats{
+		Curve:        computeCurve(mainboard),
+		Pips:         countPips(mainboard),
+		LandSubtypes: map[string]int{},
+	}
+	var manaValueSum, nonlands int
+	for _, card := range mainboard {
+		if card.Qty <= 0 {
+			continue
+		}
+		if card.Type == "land" {
+			stats.Lands += card.Qty
+			subtype := card.LandSubtype
+			if subtype == "" {
+				subtype = deckStatsLandOther
+			}
+			stats.LandSubtypes[subtype] += card.Qty
+			continue
+		}
+		nonlands += card.Qty
+		manaValueSum += card.ManaValue * card.Qty
+	}
+	if nonlands > 0 {
+		stats.AvgManaValue = roundTo(float64(manaValueSum)/float64(nonlands), 2)
+	}
+	if total := nonlands + stats.Lands; total > 0 {
+		stats.AvgManaValueWithLands = roundTo(float64(manaValueSum)/float64(total), 2)
+	}
+	if countCards(sideboard) > 0 {
+		stats.SideboardCurve = computeCurve(sideboard)
+	}
+	return stats
+}
+
+// summarizeDeckStats keeps the parts of stats the battlebox list shows.
+func summarizeDeckStats(stats *DeckStats) *DeckStatsSummary {
+	summary := &DeckStatsSummary{
+		Lands:        stats.Lands,
+		AvgManaValue: stats.AvgManaValue,
+		Curve:        make([]int, len(stats.Curve)),
+		Pips:         stats.Pips,
+	}
+	for i, bucket := range stats.Curve {
+		summary.Curve[i] = bucket.Total
+	}
+	return summary
+}
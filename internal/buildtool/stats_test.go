@@ -0,0 +1,47 @@
+package buildtool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComputeDeckStats(t *testing.T) {
+	mainboard := []Card{
+		{Name: "Island", Qty: 10, Type: "land"},
+		{Name: "Scalding Tarn", Qty: 2, Type: "land", LandSubtype: "fetch"},
+		{Name: "Delver of Secrets", Qty: 4, Type: "creature", ManaCost: "{U}", ManaValue: 1},
+		{Name: "Counterspell", Qty: 4, Type: "spell", ManaCost: "{U}{U}", ManaValue: 2},
+		{Name: "Kitchen Finks", Qty: 2, Type: "creature", ManaCost: "{1}{G/W}{G/W}", ManaValue: 3},
+		{Name: "Emrakul, the Aeons Torn", Qty: 1, Type: "creature", ManaValue: 15},
+	}
+	sideboard := []Card{{Name: "Hydroblast", Qty: 3, Type: "spell", ManaCost: "{U}", ManaValue: 1}}
+
+	stats := computeDeckStats(mainboard, sideboard)
+	if stats.Lands != 12 || !reflect.DeepEqual(stats.LandSubtypes, map[string]int{"fetch": 2, deckStatsLandOther: 10}) {
+		t.Fatalf("lands = %d %v", stats.Lands, stats.LandSubtypes)
+	}
+	if want := map[string]int{"U": 12, "G": 4, "W": 4}; !reflect.DeepEqual(stats.Pips, want) {
+		t.Fatalf("pips = %v, want %v", stats.Pips, want)
+	}
+	if len(stats.Curve) != deckStatsMaxManaValue+1 || stats.Curve[1].ByType["creature"] != 4 || stats.Curve[2].ByType["spell"] != 4 {
+		t.Fatalf("curve = %+v", stats.Curve)
+	}
+	if stats.Curve[deckStatsMaxManaValue].Total != 1 {
+		t.Fatalf("a 15-drop belongs in the 7+ bucket, got %+v", stats.Curve[deckStatsMaxManaValue])
+	}
+	// (4 + 8 + 6 + 15) / 11 nonlands and / 23 cards.
+	if stats.AvgManaValue != 3 || stats.AvgManaValueWithLands != 1.43 {
+		t.Fatalf("avg mana value = %v, with lands %v", stats.AvgManaValue, stats.AvgManaValueWithLands)
+	}
+	if len(stats.SideboardCurve) == 0 || stats.SideboardCurve[1].Total != 3 {
+		t.Fatalf("sideboard curve = %+v", stats.SideboardCurve)
+	}
+	if computeDeckStats(mainboard, nil).SideboardCurve != nil {
+		t.Fatalf("no sideboard should omit the sideboard curve")
+	}
+
+	summary := summarizeDeckStats(stats)
+	if summary.Lands != 12 || summary.AvgManaValue != 3 || !reflect.DeepEqual(summary.Curve, []int{0, 4, 4, 2, 0, 0, 0, 1}) {
+		t.Fatalf("summary = %+v", summary)
+	}
+}
@@ -0,0 +1,397 @@
+package buildtool
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Cube swap suggestions pair a cube's weakest drafted cards with maybeboard
+// candidates from the same colour and type bucket. The report is printed with
+// -cube-swaps <slug>; -accept-swaps writes chosen swaps to the staged manifest.
+
+// minSwapSeen is how often a card must have been dealt before its stats count.
+const minSwapSeen = 3
+
+// SwapSuggestion is one proposed cube change.
+type SwapSuggestion struct {
+	// Colour and type bucket shared by both cards, for example "U creature".
+	Bucket string `json:"bucket"`
+	Out    string `json:"out"`
+	In     string `json:"in"`
+	// Higher is weaker; see swapWeakness.
+	Weakness float64 `json:"weakness"`
+	Reason   string  `json:"reason"`
+}
+
+// cardColorBucket returns the colour bucket for a mana cost: a single WUBRG
+// letter, "M" for multicolour, or "C" for colourless. Hybrid symbols count
+// every colour they name.
+func cardColorBucket(manaCost string) string {
+	seen := map[rune]bool{}
+	for _, token := range manaSymbolRE.FindAllStringSubmatch(manaCost, -1) {
+		for _, ch := range strings.ToUpper(token[1]) {
+			switch ch {
+			case 'W', 'U', 'B', 'R', 'G':
+				seen[ch] = true
+			}
+		}
+	}
+	switch len(seen) {
+	case 0:
+		return "C"
+	case 1:
+		for ch := range seen {
+			return string(ch)
+		}
+	}
+	return "M"
+}
+
+func swapBucket(card Card) string {
+	return cardColorBucket(card.ManaCost) + " " + card.Type
+}
+
+// swapWeakness scores a card from 0 (always taken early and played) to 1
+// (always burned). maxAvgPick normalizes pick position across pack sizes.
+func swapWeakness(stats DraftCardStats, maxAvgPick float64) float64 {
+	pick := 1.0
+	if stats.Picked > 0 && maxAvgPick > 0 {
+		pick = stats.AvgPick / maxAvgPick
+	}
+	unplayed := 1.0
+	if stats.Mainboard+stats.Sideboard > 0 {
+		unplayed = 1 - stats.MainboardRate
+	}
+	return 0.5*stats.BurnRate + 0.3*pick + 0.2*unplayed
+}
+
+// suggestCubeSwaps proposes swaps for an enriched cube manifest. Only cards in
+// the weaker half of the cube are offered out; each bucket pairs its weakest
+// cards with maybeboard cards in manifest order. Suggestions are ordered
+// weakest first.
+func suggestCubeSwaps(manifest Manifest, stats *DraftStats) []SwapSuggestion {
+	if stats == nil {
+		return []SwapSuggestion{}
+	}
+	byName := map[string]DraftCardStats{}
+	maxAvgPick := 0.0
+	for _, card := range stats.Cards {
+		byName[normalizeName(card.Name)] = card
+		if card.Picked > 0 && card.AvgPick > maxAvgPick {
+			maxAvgPick = card.AvgPick
+		}
+	}
+
+	type candidate struct {
+		card     Card
+		stats    DraftCardStats
+		weakness float64
+	}
+	var scored []candidate
+	inCube := map[string]bool{}
+	for _, card := range manifest.Cards {
+		inCube[normalizeName(card.Name)] = true
+		cardStats, ok := byName[normalizeName(card.Name)]
+		if !ok || cardStats.Seen < minSwapSeen {
+			continue
+		}
+		scored = append(scored, candidate{card: card, stats: cardStats, weakness: swapWeakness(cardStats, maxAvgPick)})
+	}
+	if len(scored) == 0 {
+		return []SwapSuggestion{}
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		if scored[i].weakness != scored[j].weakness {
+			return scored[i].weakness > scored[j].weakness
+		}
+		return scored[i].card.Name < scored[j].card.Name
+	})
+	median := scored[len(scored)/2].weakness
+
+	outsByBucket := map[string][]candidate{}
+	for _, c := range scored {
+		if c.weakness <= median {
+			break
+		}
+		bucket := swapBucket(c.card)
+		outsByBucket[bucket] = append(outsByBucket[bucket], c)
+	}
+	insByBucket := map[string][]Card{}
+	for _, card := range manifest.Maybeboard {
+		if inCube[normalizeName(card.Name)] {
+			continue
+		}
+		bucket := swapBucket(card)
+		insByBucket[bucket] = append(insByBucket[bucket], card)
+	}
+
+	suggestions := []SwapSuggestion{}
+	for bucket, outs := range outsByBucket {
+		ins := insByBucket[bucket]
+		for i := 0; i < len(outs) && i < len(ins); i++ {
+			out := outs[i]
+			suggestions = append(suggestions, SwapSuggestion{
+				Bucket:   bucket,
+				Out:      out.card.Name,
+				In:       ins[i].Name,
+				Weakness: out.weakness,
+				Reason:   swapReason(out.stats),
+			})
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Weakness != suggestions[j].Weakness {
+			return suggestions[i].Weakness > suggestions[j].Weakness
+		}
+		return suggestions[i].Out < suggestions[j].Out
+	})
+	return suggestions
+}
+
+func swapReason(stats DraftCardStats) string {
+	pick := "never picked"
+	if stats.Picked > 0 {
+		pick = fmt.Sprintf("avg pick %.1f", stats.AvgPick)
+	}
+	return fmt.Sprintf("%s, burned %.0f%%, mainboarded %.0f%% (seen %d)", pick, 100*stats.BurnRate, 100*stats.MainboardRate, stats.Seen)
+}
+
+// writeSwapReport prints suggestions as a numbered markdown table in the style
+// of cube-swap-notes.md. Numbers are what -accept-swaps takes.
+func writeSwapReport(w io.Writer, slug string, drafts int, suggestions []SwapSuggestion) {
+	fmt.Fprintf(w, "# Swap suggestions for `%s` (%d drafts)\n\n", slug, drafts)
+	if len(suggestions) == 0 {
+		fmt.Fprintln(w, "No swaps suggested.")
+		return
+	}
+	fmt.Fprintln(w, "| # | Bucket | Out | In | Why |")
+	fmt.Fprintln(w, "|---|---|---|---|---|")
+	for i, s := range suggestions {
+		fmt.Fprintf(w, "| %d | %s | `%s` | `%s` | %s |\n", i+1, s.Bucket, s.Out, s.In, s.Reason)
+	}
+}
+
+// selectSwaps picks suggestions by 1-based number from a comma-separated list,
+// or all of them for "all".
+func selectSwaps(suggestions []SwapSuggestion, spec string) ([]SwapSuggestion, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "all" {
+		return suggestions, nil
+	}
+	var selected []SwapSuggestion
+	seen := map[int]bool{}
+	for _, part := range strings.Split(spec, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n < 1 || n > len(suggestions) {
+			return nil, fmt.Errorf("invalid swap number %q", strings.TrimSpace(part))
+		}
+		if !seen[n] {
+			seen[n] = true
+			selected = append(selected, suggestions[n-1])
+		}
+	}
+	return selected, nil
+}
+
+type manifestField struct {
+	Key   string
+	Value json.RawMessage
+}
+
+// decodeManifestFields splits a manifest into its top-level fields in file
+// order, so rewriting it keeps keys the Manifest type does not model.
+func decodeManifestFields(data []byte) ([]manifestField, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, errors.New("manifest is not a JSON object")
+	}
+	var fields []manifestField
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		fields = append(fields, manifestField{Key: key, Value: value})
+	}
+	return fields, nil
+}
+
+func encodeManifestFields(fields []manifestField) ([]byte, error) {
+	var compact bytes.Buffer
+	compact.WriteByte('{')
+	for i, field := range fields {
+		if i > 0 {
+			compact.WriteByte(',')
+		}
+		key, err := json.Marshal(field.Key)
+		if err != nil {
+			return nil, err
+		}
+		compact.Write(key)
+		compact.WriteByte(':')
+		compact.Write(field.Value)
+	}
+	compact.WriteByte('}')
+	var out bytes.Buffer
+	if err := json.Indent(&out, compact.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+func joinRawArray(entries []json.RawMessage) json.RawMessage {
+	var out bytes.Buffer
+	out.WriteByte('[')
+	for i, entry := range entries {
+		if i > 0 {
+			out.WriteByte(',')
+		}
+		out.Write(entry)
+	}
+	out.WriteByte(']')
+	return out.Bytes()
+}
+
+func rawEntryIndex(entries []json.RawMessage, name string) int {
+	for i, entry := range entries {
+		var card struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(entry, &card) == nil && normalizeName(card.Name) == normalizeName(name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// applyCubeSwaps rewrites manifest JSON with each swap applied: the In card
+// takes the Out card's place in cards, and the Out card takes the In card's
+// place in the maybeboard. Other fields and their order are kept.
+func applyCubeSwaps(data []byte, swaps []SwapSuggestion) ([]byte, error) {
+	fields, err := decodeManifestFields(data)
+	if err != nil {
+		return nil, err
+	}
+	cardsIdx, maybeIdx := -1, -1
+	for i, field := range fields {
+		switch field.Key {
+		case "cards":
+			cardsIdx = i
+		case "maybeboard":
+			maybeIdx = i
+		}
+	}
+	if cardsIdx < 0 || maybeIdx < 0 {
+		return nil, errors.New("manifest needs cards and maybeboard")
+	}
+	var cards, maybeboard []json.RawMessage
+	if err := json.Unmarshal(fields[cardsIdx].Value, &cards); err != nil {
+		return nil, fmt.Errorf("cards: %w", err)
+	}
+	if err := json.Unmarshal(fields[maybeIdx].Value, &maybeboard); err != nil {
+		return nil, fmt.Errorf("maybeboard: %w", err)
+	}
+
+	for _, swap := range swaps {
+		out := rawEntryIndex(cards, swap.Out)
+		in := rawEntryIndex(maybeboard, swap.In)
+		if out < 0 || in < 0 {
+			return nil, fmt.Errorf("swap %s -> %s no longer applies", swap.Out, swap.In)
+		}
+		cards[out], maybeboard[in] = maybeboard[in], cards[out]
+	}
+	fields[cardsIdx].Value = joinRawArray(cards)
+	fields[maybeIdx].Value = joinRawArray(maybeboard)
+	return encodeManifestFields(fields)
+}
+
+// runCubeSwaps prints the swap report for data/cube/<slug> and, when accept is
+// set, writes the accepted swaps to staging/cube/<slug>/manifest.json. An
+// existing staged manifest is the starting point, so accepted swaps accumulate.
+func runCubeSwaps(dataDir, slug, accept string, w io.Writer) error {
+	const battlebox = "cube"
+	projectPrintings := loadPrintings(filepath.Join(dataDir, printingsFileName))
+	battleboxDirs, err := orderedBattleboxDirs(dataDir)
+	if err != nil {
+		return err
+	}
+	sources := loadBuildSources(dataDir, projectPrintings, battleboxDirs)
+	bbSource, ok := sources.Battlebox(battlebox)
+	if !ok {
+		return errors.New("no cube battlebox")
+	}
+	var deckSource *DeckSource
+	for i := range bbSource.Decks {
+		if bbSource.Decks[i].Slug == slug {
+			deckSource = &bbSource.Decks[i]
+		}
+	}
+	if deckSource == nil {
+		return fmt.Errorf("unknown cube %q", slug)
+	}
+	if deckSource.ManifestErr != nil {
+		return deckSource.ManifestErr
+	}
+	if deckSource.StagedErr != nil {
+		return fmt.Errorf("reading staged manifest: %w", deckSource.StagedErr)
+	}
+	if !fileExists(deckSource.DraftStatsPath) {
+		return fmt.Errorf("no %s for cube %s; export it from /api/draft/stats", draftStatsFileName, slug)
+	}
+	stats, err := loadDraftStats(deckSource.DraftStatsPath)
+	if err != nil {
+		return fmt.Errorf("reading draft stats: %w", err)
+	}
+
+	stagedPath := filepath.Join("staging", battlebox, slug, "manifest.json")
+	basePath := filepath.Join(deckSource.Path, "manifest.json")
+	manifest := cloneManifest(deckSource.Manifest)
+	printings := deckSource.MergedPrintings
+	if deckSource.HasStaged {
+		basePath = stagedPath
+		manifest = cloneManifest(deckSource.StagedManifest)
+		printings = mergePrintings(printings, deckSource.StagedPrintings)
+	}
+	enrichManifestCards(&manifest, battlebox, slug, printings, bbSource.Manifest.LandSubtypes, nil)
+
+	suggestions := suggestCubeSwaps(manifest, stats)
+	writeSwapReport(w, slug, stats.Drafts, suggestions)
+	if accept == "" {
+		return nil
+	}
+
+	selected, err := selectSwaps(suggestions, accept)
+	if err != nil {
+		return err
+	}
+	raw, err := buildFiles.ReadFile(basePath)
+	if err != nil {
+		return err
+	}
+	updated, err := applyCubeSwaps(raw, selected)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(stagedPath), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(stagedPath, updated, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\nWrote %d swaps to %s\n", len(selected), stagedPath)
+	return nil
+}
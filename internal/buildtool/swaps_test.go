@@ -0,0 +1,115 @@
+package buildtool
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCardColorBucket(t *testing.T) {
+	cases := map[string]string{
+		"":             "C",
+		"{3}":          "C",
+		"{1}{U}":       "U",
+		"{U}{U}{W/P}":  "M",
+		"{R/G}":        "M",
+		"{2}{B}{B}{B}": "B",
+	}
+	for cost, want := range cases {
+		if got := cardColorBucket(cost); got != want {
+			t.Fatalf("cardColorBucket(%q) = %q, want %q", cost, got, want)
+		}
+	}
+}
+
+func TestSuggestCubeSwaps(t *testing.T) {
+	manifest := Manifest{
+		Cards: []Card{
+			{Name: "Brainstorm", Type: "spell", ManaCost: "{U}"},
+			{Name: "Opt", Type: "spell", ManaCost: "{U}"},
+			{Name: "Snapcaster Mage", Type: "creature", ManaCost: "{1}{U}"},
+			{Name: "Lightning Bolt", Type: "spell", ManaCost: "{R}"},
+			{Name: "Shock", Type: "spell", ManaCost: "{R}"},
+		},
+		Maybeboard: []Card{
+			{Name: "Consider", Type: "spell", ManaCost: "{U}"},
+			{Name: "Spell Pierce", Type: "spell", ManaCost: "{U}"},
+			{Name: "Brainstorm", Type: "spell", ManaCost: "{U}"},
+		},
+	}
+	stats := &DraftStats{Drafts: 4, Cards: []DraftCardStats{
+		{Name: "Brainstorm", Seen: 4, Picked: 4, AvgPick: 1.5, MainboardRate: 1, Mainboard: 4},
+		{Name: "Opt", Seen: 4, Picked: 1, AvgPick: 12, Burned: 3, BurnRate: 0.75, Sideboard: 1},
+		{Name: "Snapcaster Mage", Seen: 4, Burned: 4, BurnRate: 1},
+		{Name: "Lightning Bolt", Seen: 4, Picked: 4, AvgPick: 2, MainboardRate: 1, Mainboard: 4},
+		{Name: "Shock", Seen: 2, Burned: 2, BurnRate: 1},
+	}}
+
+	got := suggestCubeSwaps(manifest, stats)
+	want := []SwapSuggestion{{Bucket: "U spell", Out: "Opt", In: "Consider"}}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d suggestions, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i].Bucket != want[i].Bucket || got[i].Out != want[i].Out || got[i].In != want[i].In {
+			t.Fatalf("suggestion %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if !strings.Contains(got[0].Reason, "burned 75%") {
+		t.Fatalf("unexpected reason %q", got[0].Reason)
+	}
+}
+
+func TestApplyCubeSwapsKeepsManifestLayout(t *testing.T) {
+	raw := []byte(`{
+  "name": "Tempo",
+  "sleeve_color": "Radiant Sun",
+  "cards": [
+    {
+      "name": "Opt",
+      "qty": 1
+    },
+    {
+      "name": "Lightning Bolt",
+      "qty": 1
+    }
+  ],
+  "maybeboard": [
+    {
+      "name": "Consider",
+      "qty": 1
+    }
+  ]
+}
+`)
+	out, err := applyCubeSwaps(raw, []SwapSuggestion{{Out: "opt", In: "Consider"}})
+	if err != nil {
+		t.Fatalf("applyCubeSwaps: %v", err)
+	}
+	want := strings.ReplaceAll(strings.ReplaceAll(string(raw), `"Opt"`, `"tmp"`), `"Consider"`, `"Opt"`)
+	want = strings.ReplaceAll(want, `"tmp"`, `"Consider"`)
+	if string(out) != want {
+		t.Fatalf("unexpected manifest:\n%s", out)
+	}
+
+	if _, err := applyCubeSwaps(out, []SwapSuggestion{{Out: "Opt", In: "Consider"}}); err == nil {
+		t.Fatalf("expected stale swap to fail")
+	}
+}
+
+func TestRunCubeSwapsRequiresDraftStats(t *testing.T) {
+	dataDir := t.TempDir()
+	deckDir := filepath.Join(dataDir, "cube", "vintage")
+	if err := os.MkdirAll(deckDir, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(deckDir, "manifest.json"), []byte(`{"name":"Vintage","cards":[]}`), 0644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+	err := runCubeSwaps(dataDir, "vintage", "", io.Discard)
+	if err == nil || err.Error() != "no draft-stats.json for cube vintage; export it from /api/draft/stats" {
+		t.Fatalf("runCubeSwaps error = %v", err)
+	}
+}
@@ -23,6 +23,8 @@ type Card struct {
 	DoubleFaced bool `json:"double_faced,omitempty"`
 	// Optional manual land subtype (for example fetch/shock/surveil) from battlebox manifest.
 	LandSubtype string `json:"land_subtype,omitempty"`
+	// Colours of mana a land can produce, in WUBRG order (C for colourless).
+	ProducedMana string `json:"produced_mana,omitempty"`
 }
 
 // Manifest models a deck's source manifest.json file.
@@ -51,6 +53,26 @@ type Manifest struct {
 	Sideboard []Card `json:"sideboard,omitempty"`
 	// Optional maybeboard entries from manifest.json.
 	Maybeboard []Card `json:"maybeboard,omitempty"`
+	// Optional overrides of the battlebox deck rules.
+	Rules *DeckRules `json:"rules,omitempty"`
+}
+
+// DeckRules declares deck construction rules. Zero values leave a rule off,
+// except MainboardSize, which defaults to 60.
+type DeckRules struct {
+	// Exact mainboard card count.
+	MainboardSize int `json:"mainboard_size,omitempty"`
+	// Maximum sideboard card count.
+	MaxSideboardSize int `json:"max_sideboard_size,omitempty"`
+	// Maximum copies of a nonbasic card across mainboard and sideboard.
+	MaxCopies int `json:"max_copies,omitempty"`
+	// Limit every nonbasic card to one copy.
+	Singleton bool `json:"singleton,omitempty"`
+	// Card names limited to one copy.
+	Restricted []string `json:"restricted,omitempty"`
+	// Highest Scryfall rarity allowed for each card, e.g. "common"; a card with a
+	// common printing anywhere meets every limit.
+	MaxRarity string `json:"max_rarity,omitempty"`
 }
 
 // DeckUISample configures sample-viewer behavior for a deck.
@@ -165,6 +187,248 @@ type Deck struct {
 	Guides map[string]MatchupGuide `json:"guides,omitempty"`
 	// Optional staged manifest diff shown in the deck UI.
 	Diff *DeckDiff `json:"diff,omitempty"`
+	// Optional pick-rate analytics exported from the draft server.
+	DraftStats *DraftStats `json:"draft_stats,omitempty"`
+	// Build-time deck rule violations intended for frontend annotation.
+	RuleWarnings []string `json:"rule_warnings,omitempty"`
+	// Draw odds for decks sampled as hands, from the mainboard.
+	Odds *DeckOdds `json:"odds,omitempty"`
+	// Simulated mana development for decks sampled as hands.
+	Goldfish *GoldfishStats `json:"goldfish,omitempty"`
+	// Curve, colour and land breakdown of the mainboard and sideboard.
+	Stats *DeckStats `json:"stats,omitempty"`
+}
+
+// DeckStats describes a deck's mana curve, colour requirements and lands.
+type DeckStats struct {
+	// Mainboard nonland curve; the last bucket holds every card at 7 or more.
+	Curve []CurveBucket `json:"curve"`
+	// Coloured mana symbols per colour (W, U, B, R, G) across all copies.
+	Pips  map[string]int `json:"pips"`
+	Lands int            `json:"lands"`
+	// Land copies per land subtype, with unlabelled lands under "other".
+	LandSubtypes map[string]int `json:"land_subtypes"`
+	// Average mana value of nonland cards, and of every mainboard card.
+	AvgManaValue          float64 `json:"avg_mana_value"`
+	AvgManaValueWithLands float64 `json:"avg_mana_value_with_lands"`
+	// Sideboard nonland curve, omitted when there is no sideboard.
+	SideboardCurve []CurveBucket `json:"sideboard_curve,omitempty"`
+}
+
+// CurveBucket counts nonland copies at one mana value, in total and per type.
+type CurveBucket struct {
+	ManaValue int            `json:"mana_value"`
+	Total     int            `json:"total"`
+	ByType    map[string]int `json:"by_type"`
+}
+
+// DeckStatsSummary is the compact DeckStats written to index.json.
+type DeckStatsSummary struct {
+	Lands        int     `json:"lands"`
+	AvgManaValue float64 `json:"avg_mana_value"`
+	// Nonland copies per mana value, 0 through 7+.
+	Curve []int          `json:"curve"`
+	Pips  map[string]int `json:"pips"`
+}
+
+// DeckOdds holds exact (hypergeometric) draw probabilities for a mainboard.
+// Turn N sees the opening hand plus N-1 draws on the play, N on the draw.
+type DeckOdds struct {
+	DeckSize int `json:"deck_size"`
+	Lands    int `json:"lands"`
+	// Opening hand size, from UI.Sample.Size.
+	HandSize int `json:"hand_size"`
+	// OpeningHandLands[k] is the chance of exactly k lands in the opening hand.
+	OpeningHandLands []float64 `json:"opening_hand_lands"`
+	// OpeningHand2To4Lands is the chance of 2, 3 or 4 lands in the opening hand.
+	OpeningHand2To4Lands float64 `json:"opening_hand_2_to_4_lands"`
+	// LandDrops gives, per turn N, the chance of having seen at least N lands.
+	LandDrops []TurnOdds `json:"land_drops"`
+	// Cards gives, per nonland card, the chance of having seen a copy by each turn.
+	Cards []CardDrawOdds `json:"cards"`
+}
+
+type TurnOdds struct {
+	Turn   int     `json:"turn"`
+	OnPlay float64 `json:"on_play"`
+	OnDraw float64 `json:"on_draw"`
+}
+
+type CardDrawOdds struct {
+	Name   string     `json:"name"`
+	Copies int        `json:"copies"`
+	ByTurn []TurnOdds `json:"by_turn"`
+}
+
+// GoldfishStats summarises simulated solitaire games on the play.
+type GoldfishStats struct {
+	Games int `json:"games"`
+	// Mulligans[k] is the share of games keeping after k mulligans.
+	Mulligans  []float64           `json:"mulligans"`
+	ManaValues []GoldfishManaValue `json:"mana_values"`
+}
+
+// GoldfishManaValue is when a spell of one mana value first became castable on
+// colour. ByTurn[i] is the share of games where that happened on turn i+1.
+type GoldfishManaValue struct {
+	ManaValue int       `json:"mana_value"`
+	ByTurn    []float64 `json:"by_turn"`
+	// Never is the share of games where it was not castable by the last turn.
+	Never    float64 `json:"never"`
+	MeanTurn float64 `json:"mean_turn"`
+	// OnCurve is the share of games castable by the turn equal to its mana value.
+	OnCurve float64 `json:"on_curve"`
+}
+
+// DraftStats aggregates how a cube's cards were drafted across completed drafts.
+// The draft server serves this shape; saving it as draft-stats.json in a deck
+// directory adds it to the build output.
+type DraftStats struct {
+	// Cube deck slug the drafts used.
+	DeckSlug string `json:"deck_slug"`
+	// Number of completed drafts aggregated.
+	Drafts int `json:"drafts"`
+	// Per-card statistics, earliest average pick first.
+	Cards []DraftCardStats `json:"cards"`
+}
+
+// WinrateMatrix is a battlebox's winrate.json: the imported mtgdecks matrix,
+// optionally blended with local match results, plus derived per-deck fields.
+type WinrateMatrix struct {
+	FetchedAt string `json:"fetched_at,omitempty"`
+	Format    string `json:"format,omitempty"`
+	Source    string `json:"source,omitempty"`
+	// Matchups[deck][opponent] is deck's record against opponent.
+	Matchups map[string]map[string]WinrateCell `json:"matchups"`
+	// Last refresh time per cell and per deck, as recorded by the importer.
+	CellUpdates  map[string]map[string]string `json:"cell_updates,omitempty"`
+	PointUpdates map[string]string            `json:"point_updates,omitempty"`
+	// Totals are each deck's overall record in the imported format.
+	Totals map[string]WinrateTotal `json:"totals,omitempty"`
+	// Field is each battlebox deck's average winrate against the other decks.
+	Field map[string]DeckFieldWinrate `json:"field,omitempty"`
+	// LocalBlend records the blend settings when local results were merged in.
+	LocalBlend *MatrixBlend `json:"local_blend,omitempty"`
+}
+
+// WinrateCell is one matchup. Matches is the weighted match count when local
+// results are blended in; rates are 0..1.
+type WinrateCell struct {
+	CIHigh  float64 `json:"ci_high"`
+	CILow   float64 `json:"ci_low"`
+	Matches int     `json:"matches"`
+	WR      float64 `json:"wr"`
+	// LowSample marks cells with too few matches to trust.
+	LowSample bool                `json:"low_sample,omitempty"`
+	Sources   *WinrateCellSources `json:"sources,omitempty"`
+}
+
+// WinrateCellSources breaks a blended cell down by where its matches came from.
+type WinrateCellSources struct {
+	Mtgdecks *WinrateImportedSource `json:"mtgdecks,omitempty"`
+	Local    *WinrateLocalSource    `json:"local,omitempty"`
+}
+
+type WinrateImportedSource struct {
+	Matches int     `json:"matches"`
+	WR      float64 `json:"wr"`
+}
+
+type WinrateLocalSource struct {
+	LocalMatchRecord
+	// WR scores drawn matches as half a win.
+	WR     float64 `json:"wr"`
+	Weight float64 `json:"weight"`
+}
+
+type WinrateTotal struct {
+	Matches int     `json:"matches"`
+	Wins    int     `json:"wins"`
+	WR      float64 `json:"wr"`
+}
+
+// WinrateHistory tracks how each imported matchup moved across the dated
+// matrix snapshots in matrix-history/.
+type WinrateHistory struct {
+	// Snapshots lists each snapshot's as-of time, oldest first.
+	Snapshots []string `json:"snapshots"`
+	// Matchups[deck][opponent] follows the deck's imported record against opponent.
+	Matchups map[string]map[string]WinrateMatchupHistory `json:"matchups"`
+}
+
+// WinrateMatchupHistory keeps a point for each snapshot where the cell first
+// appeared or changed. Deltas are last point minus first point.
+type WinrateMatchupHistory struct {
+	Points       []WinrateHistoryPoint `json:"points"`
+	DeltaWR      float64               `json:"delta_wr"`
+	DeltaMatches int                   `json:"delta_matches"`
+}
+
+type WinrateHistoryPoint struct {
+	At      string  `json:"at"`
+	WR      float64 `json:"wr"`
+	Matches int     `json:"matches"`
+}
+
+// DeckFieldWinrate is a deck's unweighted mean winrate across the battlebox
+// decks it has matchup data against.
+type DeckFieldWinrate struct {
+	WR float64 `json:"wr"`
+	// Opponents with a matchup cell, and how many of those are low-sample.
+	Opponents int `json:"opponents"`
+	LowSample int `json:"low_sample"`
+	// Rank is 1 for the best field winrate; Tier buckets WR (S, A, B, C, D).
+	Rank int    `json:"rank"`
+	Tier string `json:"tier"`
+}
+
+// MatrixBlend is how local match results were weighted into the matrix.
+type MatrixBlend struct {
+	LocalWeight float64 `json:"local_weight"`
+	Interval    string  `json:"interval"`
+}
+
+// LocalMatchResults tallies locally recorded battlebox matches per matchup. The
+// draft server serves this shape; saving it as local-match-results.json in a
+// battlebox directory blends it into the winrate matrix.
+type LocalMatchResults struct {
+	// Battlebox slug the matches were played in.
+	Battlebox string `json:"battlebox"`
+	// Matchups[deck][opponent] is deck's record against opponent.
+	Matchups map[string]map[string]LocalMatchRecord `json:"matchups"`
+}
+
+// LocalMatchRecord is one deck's match record against one opponent.
+type LocalMatchRecord struct {
+	Matches int `json:"matches"`
+	Wins    int `json:"wins"`
+	Losses  int `json:"losses"`
+	Draws   int `json:"draws"`
+}
+
+// DraftCardStats is one card's pick statistics. Rates are 0..1.
+type DraftCardStats struct {
+	// Card name.
+	Name string `json:"name"`
+	// Copies dealt into packs.
+	Seen int `json:"seen"`
+	// Copies picked by a seat.
+	Picked int `json:"picked"`
+	// Copies taken first from their pack.
+	FirstPicks int `json:"first_picks"`
+	// Copies left in a pack when it ran out of passes.
+	Burned int `json:"burned"`
+	// Picked copies that ended in a mainboard or sideboard.
+	Mainboard int `json:"mainboard"`
+	Sideboard int `json:"sideboard"`
+	// Mean 1-based position the card was taken from its pack.
+	AvgPick float64 `json:"avg_pick"`
+	// First picks per copy seen.
+	FirstPickRate float64 `json:"first_pick_rate"`
+	// Burned copies per copy seen.
+	BurnRate float64 `json:"burn_rate"`
+	// Mainboard copies per picked copy that reached a pool zone.
+	MainboardRate float64 `json:"mainboard_rate"`
 }
 
 // BattleboxManifest models a battlebox's source manifest.json file.
@@ -191,8 +455,10 @@ type BattleboxManifest struct {
 	Presets map[string]DraftPreset `json:"presets,omitempty"`
 	// Optional combo library shared across decks in this battlebox.
 	Combos []ComboManifest `json:"combos,omitempty"`
-	// Optional banned card names for warning indicators.
+	// Optional banned card names for warning indicators and deck rules.
 	Banned []string `json:"banned,omitempty"`
+	// Optional deck construction rules checked by the validator.
+	Rules DeckRules `json:"rules,omitempty"`
 	// Optional manual land subtype taxonomy keyed by card name.
 	LandSubtypes map[string]string `json:"land_subtypes,omitempty"`
 }
@@ -251,6 +517,8 @@ type DeckIndex struct {
 	UI DeckUIProfile `json:"ui"`
 	// Mainboard card count (sum of qty) for compact UI badges.
 	CardCount int `json:"card_count"`
+	// Compact curve, colour and land summary.
+	Stats *DeckStatsSummary `json:"stats,omitempty"`
 	// True when any matchup guide plan is empty.
 	HasEmptyGuideWarnings bool `json:"has_empty_guide_warnings,omitempty"`
 	// True when any matchup guide has a non-empty warning.
@@ -361,6 +629,8 @@ type DeckDiff struct {
 	Sideboard DeckDiffPlan `json:"sideboard"`
 	// Maybeboard additions/removals.
 	Maybeboard DeckDiffPlan `json:"maybeboard,omitempty"`
+	// Deck rule violations of the staged manifest.
+	RuleWarnings []string `json:"rule_warnings,omitempty"`
 }
 
 // MissingPrinting tracks cards that lack merged printing mappings.
@@ -409,6 +679,12 @@ type ScryfallCard struct {
 	CardFaces []ScryfallCardFace `json:"card_faces"`
 	// Layout used to detect cards with back faces.
 	Layout string `json:"layout"`
+	// Mana symbols the card can produce, for lands.
+	ProducedMana []string `json:"produced_mana"`
+	// Rarity of this printing (common, uncommon, rare, mythic, special, bonus).
+	Rarity string `json:"rarity"`
+	// Format legalities by format name; the same for every printing of a card.
+	Legalities map[string]string `json:"legalities"`
 }
 
 type ScryfallCardFace struct {
@@ -432,6 +708,13 @@ type cardMeta struct {
 	ManaValue int `json:"mana_value"`
 	// Double-faced flag cached by printing key.
 	DoubleFaced *bool `json:"double_faced,omitempty"`
+	// Produced mana cached by printing key, in WUBRG order.
+	ProducedMana string `json:"produced_mana,omitempty"`
+	// Printing rarity cached by printing key.
+	Rarity string `json:"rarity,omitempty"`
+	// Scryfall pauper legality (legal, not_legal, banned). Anything but not_legal
+	// means the card has a common printing.
+	PauperLegality string `json:"pauper_legality,omitempty"`
 }
 
 type cardCacheFile struct {
@@ -445,8 +728,12 @@ var cardRefRE = regexp.MustCompile(`\[\[([^\]]+)\]\]`)
 
 const jsonGzipLevel = 5
 const cacheFile = ".card-types.json"
-const cardCacheVersion = 8
+const cardCacheVersion = 11
 const printingsFileName = "printings.json"
+const draftStatsFileName = "draft-stats.json"
+const mtgdecksMatrixFileName = "mtgdecks-winrate-matrix.json"
+const localMatchResultsFileName = "local-match-results.json"
+const matrixHistoryDirName = "matrix-history"
 const stampFile = "tmp/build-stamps.json"
 const buildFingerprintVersion = "v1"
 
@@ -459,3 +746,10 @@ var cardTypeOverrideByPrinting = map[string]string{
 
 var validate = flag.Bool("validate", true, "emit build validation warnings to stderr (annotations in JSON are always generated)")
 var fullBuild = flag.Bool("full", false, "force full rebuild (ignore incremental cache)")
+var cubeSwaps = flag.String("cube-swaps", "", "print swap suggestions for a cube from its draft-stats.json and maybeboard, then exit")
+var acceptSwaps = flag.String("accept-swaps", "", "with -cube-swaps, write these suggestion numbers (comma-separated or \"all\") to staging/cube/<slug>/manifest.json")
+var matrixLocalWeight = flag.Float64("matrix-local-weight", 1, "weight of one locally recorded match relative to one imported mtgdecks match in the winrate matrix")
+var goldfishGames = flag.Int("goldfish-games", 2000, "goldfish games simulated per deck sampled as hands (0 disables)")
+var goldfishSeed = flag.Uint64("goldfish-seed", 1, "seed for goldfish simulations; each deck derives its own stream")
+var snapshotMatrices = flag.Bool("snapshot-matrices", false, "snapshot imported winrate matrices into matrix-history/ outside dev builds")
+var matrixInterval = flag.String("matrix-interval", matrixIntervalWilson, "confidence interval for blended winrate matrix cells: wilson or bayes")
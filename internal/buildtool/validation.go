@@ -23,7 +23,10 @@ import (
 //   - Deck-level and battlebox-level printings must be referenced by deck entries.
 //   - Unreferenced-printing checks intentionally ignore prose [[Card]] refs.
 // 4) Deck shape validation:
-//   - Mainboard count defaults to 60, with per-deck overrides where needed.
+//   - Deck rules from the battlebox manifest (rules.go), with per-deck
+//     overrides: mainboard size (default 60), sideboard size, copy limits,
+//     banned/restricted cards and printing rarity, for decks and staged decks.
+//   - Declared colors must match the mainboard and sideboard mana costs.
 // Implementation note:
 // - Primer and guide parsing is memoized by file path for this build run so
 //   validation and deck emission share one parse/load result.
@@ -50,7 +53,6 @@ type ValidationWarningKind string
 
 const (
 	ValidationWarningInput                    ValidationWarningKind = "input"
-	ValidationWarningMainboardCount           ValidationWarningKind = "mainboard_count"
 	ValidationWarningDeckMissingPrinting      ValidationWarningKind = "deck_missing_printing"
 	ValidationWarningUnreferencedDeckPrinting ValidationWarningKind = "unreferenced_deck_printing"
 	ValidationWarningUnreferencedBoxPrinting  ValidationWarningKind = "unreferenced_battlebox_printing"
@@ -58,6 +60,13 @@ const (
 	ValidationWarningTodoGuide                ValidationWarningKind = "todo_guide"
 	ValidationWarningMalformedGuide           ValidationWarningKind = "malformed_guide"
 	ValidationWarningGuideMissingPrinting     ValidationWarningKind = "guide_missing_printing"
+	ValidationWarningMatrixUnknownDeck        ValidationWarningKind = "matrix_unknown_deck"
+	ValidationWarningMatrixAsymmetric         ValidationWarningKind = "matrix_asymmetric"
+	ValidationWarningDeckColorsMissing        ValidationWarningKind = "deck_colors_missing"
+	ValidationWarningDeckColorsUnused         ValidationWarningKind = "deck_colors_unused"
+	ValidationWarningDeckColorsSkipped        ValidationWarningKind = "deck_colors_skipped"
+	ValidationWarningDeckRule                 ValidationWarningKind = "deck_rule"
+	ValidationWarningStagedDeckRule           ValidationWarningKind = "staged_deck_rule"
 )
 
 type ValidationWarning struct {
@@ -67,8 +76,6 @@ type ValidationWarning struct {
 	Opponent  string
 	Card      string
 	Detail    string
-	Actual    int
-	Expected  int
 }
 
 func (w ValidationWarning) String() string {
@@ -81,8 +88,6 @@ func (w ValidationWarning) String() string {
 			return fmt.Sprintf("Validator input error (%s/%s): %s", w.Battlebox, w.Deck, w.Card)
 		}
 		return fmt.Sprintf("Validator input error (%s/%s): %s", w.Battlebox, w.Deck, w.Detail)
-	case ValidationWarningMainboardCount:
-		return fmt.Sprintf("%s/%s mainboard count is %d (expected %d)", w.Battlebox, w.Deck, w.Actual, w.Expected)
 	case ValidationWarningDeckMissingPrinting:
 		return fmt.Sprintf("Deck card missing printing (%s/%s): %s", w.Battlebox, w.Deck, w.Card)
 	case ValidationWarningUnreferencedDeckPrinting:
@@ -97,6 +102,20 @@ func (w ValidationWarning) String() string {
 		return fmt.Sprintf("Malformed sideboard plan (%s/%s -> %s): %s", w.Battlebox, w.Deck, w.Opponent, w.Detail)
 	case ValidationWarningGuideMissingPrinting:
 		return fmt.Sprintf("Matchup guide missing printing (%s/%s -> %s): %s", w.Battlebox, w.Deck, w.Opponent, w.Card)
+	case ValidationWarningMatrixUnknownDeck:
+		return fmt.Sprintf("Winrate matrix slug is not a deck (%s): %s", w.Battlebox, w.Deck)
+	case ValidationWarningMatrixAsymmetric:
+		return fmt.Sprintf("Winrate matrix pair does not complement (%s: %s vs %s): %s", w.Battlebox, w.Deck, w.Opponent, w.Detail)
+	case ValidationWarningDeckRule:
+		return fmt.Sprintf("Deck rule (%s/%s): %s", w.Battlebox, w.Deck, w.Detail)
+	case ValidationWarningStagedDeckRule:
+		return fmt.Sprintf("Staged deck rule (%s/%s): %s", w.Battlebox, w.Deck, w.Detail)
+	case ValidationWarningDeckColorsMissing:
+		return fmt.Sprintf("Deck colors missing %s (%s/%s): %s", w.Detail, w.Battlebox, w.Deck, w.Card)
+	case ValidationWarningDeckColorsUnused:
+		return fmt.Sprintf("Deck colors declare unused %s (%s/%s)", w.Detail, w.Battlebox, w.Deck)
+	case ValidationWarningDeckColorsSkipped:
+		return fmt.Sprintf("Deck colors not checked (%s/%s): %s", w.Battlebox, w.Deck, w.Detail)
 	default:
 		return w.Detail
 	}
@@ -112,8 +131,10 @@ func sortedValidationWarningStrings(warnings []ValidationWarning) []string {
 }
 
 type deckWarningAnnotations struct {
-	Primer []string
-	Guides map[string]guideWarningAnnotations
+	Primer      []string
+	Rules       []string
+	StagedRules []string
+	Guides      map[string]guideWarningAnnotations
 }
 
 type guideWarningAnnotations struct {
@@ -139,21 +160,6 @@ var parseCache = validationParseCache{
 	guides:  make(map[string]guideParseCacheEntry),
 }
 
-var expectedMainboardCountOverrides = map[string]map[string]int{
-	"cube": {
-		"artifact": 180,
-		"legacy":   360,
-		"modern":   180,
-		"pauper":   180,
-		"peasant":  180,
-		"tempo":    180,
-	},
-	"shared": {
-		"dandan":      80,
-		"draft-chaff": 203,
-	},
-}
-
 var basicLandPrintingKeys = map[string]struct{}{
 	"plains":   {},
 	"island":   {},
@@ -402,7 +408,9 @@ func finalizeDeckWarningAnnotations(raw map[string]map[string]*deckWarningAnnota
 				continue
 			}
 			deckOut := deckWarningAnnotations{
-				Primer: append([]string(nil), warnings.Primer...),
+				Primer:      append([]string(nil), warnings.Primer...),
+				Rules:       append([]string(nil), warnings.Rules...),
+				StagedRules: append([]string(nil), warnings.StagedRules...),
 			}
 			if len(warnings.Guides) > 0 {
 				deckOut.Guides = make(map[string]guideWarningAnnotations, len(warnings.Guides))
@@ -470,19 +478,12 @@ func validatePrintingsUsage(sources BuildSources) ([]ValidationWarning, map[stri
 			deckCards := collectManifestCards(manifest)
 			mainboardIndex := indexCards(manifest.Cards)
 			sideboardIndex := indexCards(manifest.Sideboard)
-			mainboardTotal := 0
-			for _, card := range manifest.Cards {
-				mainboardTotal += card.Qty
-			}
-			expectedMainboardTotal := expectedMainboardCount(bbSlug, deckSlug)
-			if mainboardTotal != expectedMainboardTotal {
-				warnings = append(warnings, ValidationWarning{
-					Kind:      ValidationWarningMainboardCount,
-					Battlebox: bbSlug,
-					Deck:      deckSlug,
-					Actual:    mainboardTotal,
-					Expected:  expectedMainboardTotal,
-				})
+			ruleWarnings, ruleMessages, stagedRuleMessages := collectDeckRuleWarnings(bbSource, deckSource)
+			warnings = append(warnings, ruleWarnings...)
+			if len(ruleMessages) > 0 || len(stagedRuleMessages) > 0 {
+				deckWarnings := appendDeckWarningAnnotation(annotations, bbSlug, deckSlug)
+				deckWarnings.Rules = append(deckWarnings.Rules, ruleMessages...)
+				deckWarnings.StagedRules = append(deckWarnings.StagedRules, stagedRuleMessages...)
 			}
 			for key := range deckCards {
 				battleboxUsedCards[key] = struct{}{}
@@ -625,15 +626,6 @@ func validatePrintingsUsage(sources BuildSources) ([]ValidationWarning, map[stri
 	return warnings, finalizeDeckWarningAnnotations(annotations)
 }
 
-func expectedMainboardCount(battleboxSlug, deckSlug string) int {
-	if battleboxOverrides, ok := expectedMainboardCountOverrides[battleboxSlug]; ok {
-		if expected, ok := battleboxOverrides[deckSlug]; ok {
-			return expected
-		}
-	}
-	return 60
-}
-
 func collectManifestCards(manifest Manifest) map[string]struct{} {
 	out := make(map[string]struct{}, len(manifest.Cards)+len(manifest.Sideboard)+len(manifest.Maybeboard))
 	for _, card := range manifest.Cards {
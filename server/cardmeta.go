@@ -0,0 +1,335 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lxing/battlebox/internal/buildtool"
+)
+
+// cardManaCostSource resolves card names to mana costs for basic land suggestions.
+type cardManaCostSource interface {
+	ManaCosts(cardNames []string) map[string]string
+}
+
+// builtBattleboxes caches the built battlebox payloads and winrate matrices in
+// dir for the request-path sources below. The build writes index.json last with
+// a fresh build_id, so the cache reloads when that id changes; index.json is only
+// reparsed when its modification time moves.
+type builtBattleboxes struct {
+	dir string
+
+	mu       sync.Mutex
+	indexMod time.Time
+	buildID  string
+	data     *builtData
+}
+
+// builtData is one load of the built output. It is shared between requests and
+// never modified.
+type builtData struct {
+	battleboxes []buildtool.Battlebox
+	// matrices holds each battlebox's built winrate matchups, when it has any.
+	matrices  map[string]map[string]map[string]buildtool.WinrateCell
+	manaCosts map[string]string
+}
+
+func newBuiltBattleboxes(dir string) *builtBattleboxes {
+	return &builtBattleboxes{dir: dir}
+}
+
+var builtOutput = newBuiltBattleboxes(filepath.Join(staticRoot, "data"))
+
+// current returns the loaded output, reloading it first when the build changed.
+func (c *builtBattleboxes) current() *builtData {
+	indexPath := filepath.Join(c.dir, "index.json")
+	var indexMod time.Time
+	if info, err := os.Stat(indexPath); err == nil {
+		indexMod = info.ModTime()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.data != nil && indexMod.Equal(c.indexMod) {
+		return c.data
+	}
+	buildID := readBuildID(indexPath)
+	c.indexMod = indexMod
+	if c.data != nil && buildID == c.buildID {
+		return c.data
+	}
+	c.data = loadBuiltData(c.dir)
+	c.buildID = buildID
+	return c.data
+}
+
+func loadBuiltData(dir string) *builtData {
+	data := &builtData{
+		battleboxes: readBuiltBattleboxes(dir),
+		matrices:    map[string]map[string]map[string]buildtool.WinrateCell{},
+		manaCosts:   map[string]string{},
+	}
+	for _, battlebox := range data.battleboxes {
+		if matchups := readBuiltWinrateMatrix(dir, battlebox.Slug); matchups != nil {
+			data.matrices[battlebox.Slug] = matchups
+		}
+		for _, deck := range battlebox.Decks {
+			for _, cards := range [][]buildtool.Card{deck.Cards, deck.Sideboard, deck.Maybeboard} {
+				for _, card := range cards {
+					if card.ManaCost != "" {
+						data.manaCosts[strings.ToLower(card.Name)] = card.ManaCost
+					}
+				}
+			}
+		}
+	}
+	return data
+}
+
+// readBuildID returns the build_id of the index at path, or "" when it cannot
+// be read.
+func readBuildID(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	var index struct {
+		BuildID string `json:"build_id"`
+	}
+	if err := json.Unmarshal(data, &index); err != nil {
+		log.Printf("Failed to parse %s: %v", path, err)
+		return ""
+	}
+	return index.BuildID
+}
+
+func readBuiltWinrateMatrix(dir, battleboxSlug string) map[string]map[string]buildtool.WinrateCell {
+	data, err := os.ReadFile(filepath.Join(dir, battleboxSlug, "winrate.json"))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Failed to read winrate matrix for %s: %v", battleboxSlug, err)
+		}
+		return nil
+	}
+	var matrix buildtool.WinrateMatrix
+	if err := json.Unmarshal(data, &matrix); err != nil {
+		log.Printf("Failed to parse winrate matrix for %s: %v", battleboxSlug, err)
+		return nil
+	}
+	return matrix.Matchups
+}
+
+// builtCardManaCosts reads mana costs from the built battlebox JSON, which carries
+// ManaCost from the build's card metadata cache.
+type builtCardManaCosts struct {
+	built *builtBattleboxes
+}
+
+// readBuiltBattleboxes loads every built battlebox payload in dir, skipping the
+// index and logging files that cannot be read.
+func readBuiltBattleboxes(dir string) []buildtool.Battlebox {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		log.Printf("Failed to list built battleboxes in %s: %v", dir, err)
+		return nil
+	}
+	battleboxes := make([]buildtool.Battlebox, 0, len(paths))
+	for _, path := range paths {
+		if filepath.Base(path) == "index.json" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("Failed to read %s: %v", path, err)
+			continue
+		}
+		var battlebox buildtool.Battlebox
+		if err := json.Unmarshal(data, &battlebox); err != nil {
+			log.Printf("Failed to parse %s: %v", path, err)
+			continue
+		}
+		battleboxes = append(battleboxes, battlebox)
+	}
+	return battleboxes
+}
+
+func (c *builtCardManaCosts) ManaCosts(cardNames []string) map[string]string {
+	byName := c.built.current().manaCosts
+	out := make(map[string]string, len(cardNames))
+	for _, name := range cardNames {
+		if manaCost, ok := byName[strings.ToLower(name)]; ok {
+			out[name] = manaCost
+		}
+	}
+	return out
+}
+
+var cardManaCosts cardManaCostSource = &builtCardManaCosts{built: builtOutput}
+
+// draftDeckSource resolves a cube deck slug and preset id to a room config and
+// the card list to deal packs from, as the lobby does when creating a room.
+type draftDeckSource interface {
+	DraftDeck(deckSlug, presetID string) (DraftConfig, []string, error)
+}
+
+// builtDraftDecks reads cube decks and presets from the built battlebox JSON.
+// The cache reloads on a new build so scheduled drafts pick up a rebuilt cube.
+type builtDraftDecks struct {
+	built *builtBattleboxes
+}
+
+func (s *builtDraftDecks) DraftDeck(deckSlug, presetID string) (DraftConfig, []string, error) {
+	for _, battlebox := range s.built.current().battleboxes {
+		for _, deck := range battlebox.Decks {
+			if deck.Slug != deckSlug {
+				continue
+			}
+			if !slices.Contains(deck.DraftPresets, presetID) {
+				return DraftConfig{}, nil, fmt.Errorf("deck %q has no draft preset %q", deckSlug, presetID)
+			}
+			preset, ok := battlebox.Presets[presetID]
+			if !ok {
+				return DraftConfig{}, nil, fmt.Errorf("unknown draft preset %q", presetID)
+			}
+			names := make([]string, 0, deck.CardCount)
+			for _, card := range deck.Cards {
+				for i := 0; i < card.Qty; i++ {
+					names = append(names, card.Name)
+				}
+			}
+			return DraftConfig{
+				PackCount:   preset.PackCount,
+				PackSize:    preset.PackSize,
+				SeatCount:   preset.SeatCount,
+				PassPattern: append([]int(nil), preset.PassPattern...),
+			}, names, nil
+		}
+	}
+	return DraftConfig{}, nil, fmt.Errorf("deck %q not found", deckSlug)
+}
+
+var draftDecks draftDeckSource = &builtDraftDecks{built: builtOutput}
+
+// libraryDeckSource resolves a deck of the shared battlebox to its mainboard,
+// one entry per copy, for shared-library game sessions.
+type libraryDeckSource interface {
+	LibraryDeck(deckSlug string) ([]string, error)
+}
+
+type builtLibraryDecks struct {
+	built *builtBattleboxes
+}
+
+func (s *builtLibraryDecks) LibraryDeck(deckSlug string) ([]string, error) {
+	for _, battlebox := range s.built.current().battleboxes {
+		if battlebox.Slug != sharedLibraryBattlebox {
+			continue
+		}
+		for _, deck := range battlebox.Decks {
+			if deck.Slug != deckSlug {
+				continue
+			}
+			names := make([]string, 0, deck.CardCount)
+			for _, card := range deck.Cards {
+				for i := 0; i < card.Qty; i++ {
+					names = append(names, card.Name)
+				}
+			}
+			return names, nil
+		}
+	}
+	return nil, fmt.Errorf("shared deck %q not found", deckSlug)
+}
+
+var libraryDecks libraryDeckSource = &builtLibraryDecks{built: builtOutput}
+
+// matchDeckSource lists the deck slugs of a battlebox so recorded match results
+// can only name decks that exist, and finds the battlebox a deck belongs to.
+type matchDeckSource interface {
+	BattleboxDecks(battleboxSlug string) ([]string, error)
+	DeckBattlebox(deckSlug string) (string, error)
+}
+
+type builtMatchDecks struct {
+	built *builtBattleboxes
+}
+
+func (s *builtMatchDecks) BattleboxDecks(battleboxSlug string) ([]string, error) {
+	for _, battlebox := range s.built.current().battleboxes {
+		if battlebox.Slug != battleboxSlug {
+			continue
+		}
+		slugs := make([]string, 0, len(battlebox.Decks))
+		for _, deck := range battlebox.Decks {
+			slugs = append(slugs, deck.Slug)
+		}
+		return slugs, nil
+	}
+	return nil, fmt.Errorf("battlebox %q not found", battleboxSlug)
+}
+
+func (s *builtMatchDecks) DeckBattlebox(deckSlug string) (string, error) {
+	for _, battlebox := range s.built.current().battleboxes {
+		for _, deck := range battlebox.Decks {
+			if deck.Slug == deckSlug {
+				return battlebox.Slug, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("deck %q not found", deckSlug)
+}
+
+var matchDecks matchDeckSource = &builtMatchDecks{built: builtOutput}
+
+// rollDeckSource loads what the matchup roller needs about a battlebox: its
+// roll switches, deck tags and built winrate matrix.
+type rollDeckSource interface {
+	RollPool(battleboxSlug string) (rollPool, error)
+}
+
+type rollPool struct {
+	RandomRollEnabled       bool
+	DisableDoubleRandomRoll bool
+	Decks                   []rollDeck
+	// Matchups is the built winrate matrix; nil when the battlebox has none.
+	Matchups map[string]map[string]buildtool.WinrateCell
+}
+
+type rollDeck struct {
+	Slug           string
+	Tags           []string
+	DifficultyTags []string
+}
+
+type builtRollDecks struct {
+	built *builtBattleboxes
+}
+
+func (s *builtRollDecks) RollPool(battleboxSlug string) (rollPool, error) {
+	built := s.built.current()
+	for _, battlebox := range built.battleboxes {
+		if battlebox.Slug != battleboxSlug {
+			continue
+		}
+		pool := rollPool{
+			RandomRollEnabled:       battlebox.RandomRollEnabled,
+			DisableDoubleRandomRoll: battlebox.DisableDoubleRandomRoll,
+			Decks:                   make([]rollDeck, 0, len(battlebox.Decks)),
+		}
+		for _, deck := range battlebox.Decks {
+			pool.Decks = append(pool.Decks, rollDeck{Slug: deck.Slug, Tags: deck.Tags, DifficultyTags: deck.DifficultyTags})
+		}
+		pool.Matchups = built.matrices[battleboxSlug]
+		return pool, nil
+	}
+	return rollPool{}, fmt.Errorf("battlebox %q not found", battleboxSlug)
+}
+
+var rollDecks rollDeckSource = &builtRollDecks{built: builtOutput}
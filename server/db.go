@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/lxing/battlebox/internal/buildtool"
@@ -21,19 +22,25 @@ const draftSnapshotSchemaVersion = 2
 type draftRoomSnapshot struct {
 	SchemaVersion int              `json:"schema_version"`
 	OwnerDeviceID string           `json:"owner_device_id,omitempty"`
+	HostName      string           `json:"host_name,omitempty"`
+	CreatedAt     time.Time        `json:"created_at,omitzero"`
+	UpdatedAt     time.Time        `json:"updated_at,omitzero"`
 	Config        DraftConfig      `json:"config"`
 	Packs         [][]packSnapshot `json:"packs"`
 	Progress      DraftProgress    `json:"progress"`
 	Seats         []SeatState      `json:"seats"`
 	SeatPicked    []bool           `json:"seat_picked"`
+	PassPicks     []*PassPick      `json:"pass_picks,omitempty"`
 	LastSeqBySeat []uint64         `json:"last_seq_by_seat"`
 	GlobalSeq     uint64           `json:"global_seq"`
+	Waiting       bool             `json:"waiting,omitempty"`
 }
 
 type packSnapshot struct {
-	ID     string   `json:"id"`
-	Cards  []string `json:"cards"`
-	Picked []bool   `json:"picked"`
+	ID       string   `json:"id"`
+	Cards    []string `json:"cards"`
+	Picked   []bool   `json:"picked"`
+	PickedAt []int    `json:"picked_at,omitempty"`
 }
 
 type draftRoomRecord struct {
@@ -94,6 +101,128 @@ CREATE TABLE IF NOT EXISTS draft_rooms (
 		}
 	}
 
+	if _, err := db.Exec(`
+CREATE TABLE IF NOT EXISTS draft_events (
+  event_id TEXT PRIMARY KEY,
+  owner_device_id TEXT NOT NULL DEFAULT '',
+  event_json TEXT NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);`); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("create draft_events table: %w", err)
+	}
+
+	if _, err := db.Exec(`
+CREATE TABLE IF NOT EXISTS draft_schedules (
+  schedule_id TEXT PRIMARY KEY,
+  owner_device_id TEXT NOT NULL DEFAULT '',
+  schedule_json TEXT NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);`); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("create draft_schedules table: %w", err)
+	}
+
+	if _, err := db.Exec(`
+CREATE TABLE IF NOT EXISTS draft_card_stats (
+  draft_key TEXT NOT NULL,
+  deck_slug TEXT NOT NULL,
+  card_name TEXT NOT NULL,
+  seen INTEGER NOT NULL DEFAULT 0,
+  picked INTEGER NOT NULL DEFAULT 0,
+  pick_position_sum INTEGER NOT NULL DEFAULT 0,
+  first_picks INTEGER NOT NULL DEFAULT 0,
+  burned INTEGER NOT NULL DEFAULT 0,
+  mainboard INTEGER NOT NULL DEFAULT 0,
+  sideboard INTEGER NOT NULL DEFAULT 0,
+  PRIMARY KEY (draft_key, card_name)
+);`); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("create draft_card_stats table: %w", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS draft_card_stats_deck_slug_idx ON draft_card_stats(deck_slug);`); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("create draft_card_stats index: %w", err)
+	}
+
+	if _, err := db.Exec(`
+CREATE TABLE IF NOT EXISTS game_sessions (
+  session_id TEXT PRIMARY KEY,
+  owner_device_id TEXT NOT NULL DEFAULT '',
+  version INTEGER NOT NULL DEFAULT 0,
+  session_json TEXT NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);`); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("create game_sessions table: %w", err)
+	}
+
+	if _, err := db.Exec(`
+CREATE TABLE IF NOT EXISTS game_libraries (
+  session_id TEXT PRIMARY KEY,
+  owner_device_id TEXT NOT NULL DEFAULT '',
+  version INTEGER NOT NULL DEFAULT 0,
+  session_json TEXT NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);`); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("create game_libraries table: %w", err)
+	}
+	if _, err := db.Exec(`ALTER TABLE game_libraries ADD COLUMN seat_claims INTEGER NOT NULL DEFAULT 0;`); err != nil {
+		if !strings.Contains(strings.ToLower(err.Error()), "duplicate column name") {
+			_ = db.Close()
+			return nil, fmt.Errorf("ensure seat_claims column: %w", err)
+		}
+	}
+
+	if _, err := db.Exec(`
+CREATE TABLE IF NOT EXISTS match_results (
+  match_id INTEGER PRIMARY KEY AUTOINCREMENT,
+  battlebox TEXT NOT NULL,
+  p1_deck TEXT NOT NULL,
+  p2_deck TEXT NOT NULL,
+  p1_wins INTEGER NOT NULL DEFAULT 0,
+  p2_wins INTEGER NOT NULL DEFAULT 0,
+  on_play TEXT NOT NULL DEFAULT '',
+  p1_name TEXT NOT NULL DEFAULT '',
+  p2_name TEXT NOT NULL DEFAULT '',
+  guide TEXT NOT NULL DEFAULT '',
+  recorder_device_id TEXT NOT NULL DEFAULT '',
+  played_at INTEGER NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);`); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("create match_results table: %w", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS match_results_battlebox_idx ON match_results(battlebox);`); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("create match_results index: %w", err)
+	}
+
+	if _, err := db.Exec(`
+CREATE TABLE IF NOT EXISTS ratings (
+  battlebox TEXT NOT NULL,
+  kind TEXT NOT NULL,
+  name_key TEXT NOT NULL,
+  name TEXT NOT NULL,
+  rating REAL NOT NULL,
+  matches INTEGER NOT NULL DEFAULT 0,
+  wins INTEGER NOT NULL DEFAULT 0,
+  losses INTEGER NOT NULL DEFAULT 0,
+  draws INTEGER NOT NULL DEFAULT 0,
+  games_won INTEGER NOT NULL DEFAULT 0,
+  games_lost INTEGER NOT NULL DEFAULT 0,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (battlebox, kind, name_key)
+);`); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("create ratings table: %w", err)
+	}
+
 	return &draftRoomStore{db: db}, nil
 }
 
@@ -229,6 +358,211 @@ ORDER BY room_id ASC;
 	return records, nil
 }
 
+// SaveEvent upserts one draft event. Events are written through on every change
+// rather than on the room snapshot ticker.
+func (s *draftRoomStore) SaveEvent(ctx context.Context, event draftEventRecord) error {
+	if s == nil || s.db == nil {
+		return errors.New("draft room store not initialized")
+	}
+	if event.EventID == "" {
+		return errors.New("event id required")
+	}
+	raw, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal event %q: %w", event.EventID, err)
+	}
+	if _, err := s.db.ExecContext(ctx, `
+INSERT INTO draft_events (event_id, owner_device_id, event_json)
+VALUES (?, ?, ?)
+ON CONFLICT(event_id) DO UPDATE SET
+  owner_device_id = excluded.owner_device_id,
+  event_json = excluded.event_json,
+  updated_at = CURRENT_TIMESTAMP;
+`, event.EventID, event.OwnerDeviceID, string(raw)); err != nil {
+		return fmt.Errorf("upsert event %q: %w", event.EventID, err)
+	}
+	return nil
+}
+
+func (s *draftRoomStore) LoadEvents(ctx context.Context) ([]draftEventRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("draft room store not initialized")
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT event_id, event_json FROM draft_events ORDER BY event_id ASC;`)
+	if err != nil {
+		return nil, fmt.Errorf("query draft events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]draftEventRecord, 0)
+	for rows.Next() {
+		var eventID string
+		var raw string
+		if err := rows.Scan(&eventID, &raw); err != nil {
+			return nil, fmt.Errorf("scan draft event row: %w", err)
+		}
+		var event draftEventRecord
+		if err := json.Unmarshal([]byte(raw), &event); err != nil {
+			return nil, fmt.Errorf("decode event %q: %w", eventID, err)
+		}
+		event.EventID = eventID
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate draft event rows: %w", err)
+	}
+	return events, nil
+}
+
+// SaveSchedule upserts one scheduled draft. Like events, schedules are written
+// through on every change.
+func (s *draftRoomStore) SaveSchedule(ctx context.Context, schedule draftScheduleRecord) error {
+	if s == nil || s.db == nil {
+		return errors.New("draft room store not initialized")
+	}
+	if schedule.ScheduleID == "" {
+		return errors.New("schedule id required")
+	}
+	raw, err := json.Marshal(schedule)
+	if err != nil {
+		return fmt.Errorf("marshal schedule %q: %w", schedule.ScheduleID, err)
+	}
+	if _, err := s.db.ExecContext(ctx, `
+INSERT INTO draft_schedules (schedule_id, owner_device_id, schedule_json)
+VALUES (?, ?, ?)
+ON CONFLICT(schedule_id) DO UPDATE SET
+  owner_device_id = excluded.owner_device_id,
+  schedule_json = excluded.schedule_json,
+  updated_at = CURRENT_TIMESTAMP;
+`, schedule.ScheduleID, schedule.OwnerDeviceID, string(raw)); err != nil {
+		return fmt.Errorf("upsert schedule %q: %w", schedule.ScheduleID, err)
+	}
+	return nil
+}
+
+func (s *draftRoomStore) LoadSchedules(ctx context.Context) ([]draftScheduleRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("draft room store not initialized")
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT schedule_id, schedule_json FROM draft_schedules ORDER BY schedule_id ASC;`)
+	if err != nil {
+		return nil, fmt.Errorf("query draft schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := make([]draftScheduleRecord, 0)
+	for rows.Next() {
+		var scheduleID string
+		var raw string
+		if err := rows.Scan(&scheduleID, &raw); err != nil {
+			return nil, fmt.Errorf("scan draft schedule row: %w", err)
+		}
+		var schedule draftScheduleRecord
+		if err := json.Unmarshal([]byte(raw), &schedule); err != nil {
+			return nil, fmt.Errorf("decode schedule %q: %w", scheduleID, err)
+		}
+		schedule.ScheduleID = scheduleID
+		schedules = append(schedules, schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate draft schedule rows: %w", err)
+	}
+	return schedules, nil
+}
+
+func (s *draftRoomStore) DeleteSchedule(ctx context.Context, scheduleID string) error {
+	if s == nil || s.db == nil {
+		return errors.New("draft room store not initialized")
+	}
+	if scheduleID == "" {
+		return errors.New("schedule id required")
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM draft_schedules WHERE schedule_id = ?;`, scheduleID); err != nil {
+		return fmt.Errorf("delete draft schedule %q: %w", scheduleID, err)
+	}
+	return nil
+}
+
+// SaveCardStats replaces the per-card pick counts recorded for one draft. A draft
+// is recorded again whenever it changes after completion, e.g. when a seat
+// registers its deck.
+func (s *draftRoomStore) SaveCardStats(ctx context.Context, draftKey, deckSlug string, counts []cardPickCount) error {
+	if s == nil || s.db == nil {
+		return errors.New("draft room store not initialized")
+	}
+	if draftKey == "" || deckSlug == "" {
+		return errors.New("draft key and deck slug required")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin card stats tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM draft_card_stats WHERE draft_key = ?;`, draftKey); err != nil {
+		return fmt.Errorf("clear card stats for %q: %w", draftKey, err)
+	}
+	insertStmt, err := tx.PrepareContext(ctx, `
+INSERT INTO draft_card_stats (draft_key, deck_slug, card_name, seen, picked, pick_position_sum, first_picks, burned, mainboard, sideboard)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+`)
+	if err != nil {
+		return fmt.Errorf("prepare card stats insert: %w", err)
+	}
+	defer insertStmt.Close()
+	for _, count := range counts {
+		if _, err := insertStmt.ExecContext(ctx, draftKey, deckSlug, count.Name, count.Seen, count.Picked, count.PickPositionSum, count.FirstPicks, count.Burned, count.Mainboard, count.Sideboard); err != nil {
+			return fmt.Errorf("insert card stats for %q: %w", count.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit card stats tx: %w", err)
+	}
+	return nil
+}
+
+// LoadCardStats sums the per-card pick counts of every recorded draft of a cube.
+func (s *draftRoomStore) LoadCardStats(ctx context.Context, deckSlug string) (int, []cardPickCount, error) {
+	if s == nil || s.db == nil {
+		return 0, nil, errors.New("draft room store not initialized")
+	}
+
+	var drafts int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(DISTINCT draft_key) FROM draft_card_stats WHERE deck_slug = ?;`, deckSlug).Scan(&drafts); err != nil {
+		return 0, nil, fmt.Errorf("count drafts for %q: %w", deckSlug, err)
+	}
+	rows, err := s.db.QueryContext(ctx, `
+SELECT card_name, SUM(seen), SUM(picked), SUM(pick_position_sum), SUM(first_picks), SUM(burned), SUM(mainboard), SUM(sideboard)
+FROM draft_card_stats
+WHERE deck_slug = ?
+GROUP BY card_name
+ORDER BY card_name ASC;
+`, deckSlug)
+	if err != nil {
+		return 0, nil, fmt.Errorf("query card stats for %q: %w", deckSlug, err)
+	}
+	defer rows.Close()
+
+	counts := make([]cardPickCount, 0)
+	for rows.Next() {
+		var count cardPickCount
+		if err := rows.Scan(&count.Name, &count.Seen, &count.Picked, &count.PickPositionSum, &count.FirstPicks, &count.Burned, &count.Mainboard, &count.Sideboard); err != nil {
+			return 0, nil, fmt.Errorf("scan card stats row: %w", err)
+		}
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, fmt.Errorf("iterate card stats rows: %w", err)
+	}
+	return drafts, counts, nil
+}
+
 func (s *draftRoomStore) DeleteRoom(ctx context.Context, roomID string) error {
 	if s == nil || s.db == nil {
 		return errors.New("draft room store not initialized")
@@ -242,6 +576,365 @@ func (s *draftRoomStore) DeleteRoom(ctx context.Context, roomID string) error {
 	return nil
 }
 
+// SaveGameSessions upserts game session snapshots whose state version changed
+// since they were last written and returns how many were written.
+func (s *draftRoomStore) SaveGameSessions(ctx context.Context, records []gameSessionRecord) (int, error) {
+	if s == nil || s.db == nil {
+		return 0, errors.New("draft room store not initialized")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin game session tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	selectStmt, err := tx.PrepareContext(ctx, `SELECT version FROM game_sessions WHERE session_id = ?;`)
+	if err != nil {
+		return 0, fmt.Errorf("prepare select game session version: %w", err)
+	}
+	defer selectStmt.Close()
+
+	upsertStmt, err := tx.PrepareContext(ctx, `
+INSERT INTO game_sessions (session_id, owner_device_id, version, session_json)
+VALUES (?, ?, ?, ?)
+ON CONFLICT(session_id) DO UPDATE SET
+  owner_device_id = excluded.owner_device_id,
+  version = excluded.version,
+  session_json = excluded.session_json,
+  updated_at = CURRENT_TIMESTAMP;
+`)
+	if err != nil {
+		return 0, fmt.Errorf("prepare game session upsert: %w", err)
+	}
+	defer upsertStmt.Close()
+
+	saved := 0
+	for _, record := range records {
+		if record.SessionID == "" {
+			continue
+		}
+		var existingVersion uint64
+		scanErr := selectStmt.QueryRowContext(ctx, record.SessionID).Scan(&existingVersion)
+		if scanErr == nil && existingVersion == record.Snapshot.State.Version {
+			continue
+		}
+		if scanErr != nil && !errors.Is(scanErr, sql.ErrNoRows) {
+			return 0, fmt.Errorf("select version for game session %q: %w", record.SessionID, scanErr)
+		}
+
+		raw, err := json.Marshal(record.Snapshot)
+		if err != nil {
+			return 0, fmt.Errorf("marshal game session %q: %w", record.SessionID, err)
+		}
+		if _, err := upsertStmt.ExecContext(ctx, record.SessionID, record.Snapshot.OwnerDeviceID, record.Snapshot.State.Version, string(raw)); err != nil {
+			return 0, fmt.Errorf("upsert game session %q: %w", record.SessionID, err)
+		}
+		saved++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit game session tx: %w", err)
+	}
+	return saved, nil
+}
+
+func (s *draftRoomStore) LoadGameSessions(ctx context.Context) ([]gameSessionRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("draft room store not initialized")
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT session_id, session_json FROM game_sessions ORDER BY session_id ASC;`)
+	if err != nil {
+		return nil, fmt.Errorf("query game sessions: %w", err)
+	}
+	defer rows.Close()
+
+	records := make([]gameSessionRecord, 0)
+	for rows.Next() {
+		var sessionID string
+		var raw string
+		if err := rows.Scan(&sessionID, &raw); err != nil {
+			return nil, fmt.Errorf("scan game session row: %w", err)
+		}
+		var snapshot gameSessionSnapshot
+		if err := json.Unmarshal([]byte(raw), &snapshot); err != nil {
+			return nil, fmt.Errorf("decode game session %q: %w", sessionID, err)
+		}
+		records = append(records, gameSessionRecord{SessionID: sessionID, Snapshot: snapshot})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate game session rows: %w", err)
+	}
+	return records, nil
+}
+
+func (s *draftRoomStore) DeleteGameSession(ctx context.Context, sessionID string) error {
+	if s == nil || s.db == nil {
+		return errors.New("draft room store not initialized")
+	}
+	if sessionID == "" {
+		return errors.New("session id required")
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM game_sessions WHERE session_id = ?;`, sessionID); err != nil {
+		return fmt.Errorf("delete game session %q: %w", sessionID, err)
+	}
+	return nil
+}
+
+// SaveLibrarySessions upserts shared-library snapshots whose version changed
+// since they were last written and returns how many were written.
+func (s *draftRoomStore) SaveLibrarySessions(ctx context.Context, records []librarySessionRecord) (int, error) {
+	if s == nil || s.db == nil {
+		return 0, errors.New("draft room store not initialized")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin library session tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	selectStmt, err := tx.PrepareContext(ctx, `SELECT version, seat_claims FROM game_libraries WHERE session_id = ?;`)
+	if err != nil {
+		return 0, fmt.Errorf("prepare select library session version: %w", err)
+	}
+	defer selectStmt.Close()
+
+	upsertStmt, err := tx.PrepareContext(ctx, `
+INSERT INTO game_libraries (session_id, owner_device_id, version, seat_claims, session_json)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT(session_id) DO UPDATE SET
+  owner_device_id = excluded.owner_device_id,
+  version = excluded.version,
+  seat_claims = excluded.seat_claims,
+  session_json = excluded.session_json,
+  updated_at = CURRENT_TIMESTAMP;
+`)
+	if err != nil {
+		return 0, fmt.Errorf("prepare library session upsert: %w", err)
+	}
+	defer upsertStmt.Close()
+
+	saved := 0
+	for _, record := range records {
+		if record.SessionID == "" {
+			continue
+		}
+		// Seat claims do not bump the version, and seats are only ever
+		// claimed, so their count tells a new claim apart.
+		claims := len(record.Snapshot.SeatDevices)
+		var existingVersion uint64
+		var existingClaims int
+		scanErr := selectStmt.QueryRowContext(ctx, record.SessionID).Scan(&existingVersion, &existingClaims)
+		if scanErr == nil && existingVersion == record.Snapshot.State.Version && existingClaims == claims {
+			continue
+		}
+		if scanErr != nil && !errors.Is(scanErr, sql.ErrNoRows) {
+			return 0, fmt.Errorf("select version for library session %q: %w", record.SessionID, scanErr)
+		}
+
+		raw, err := json.Marshal(record.Snapshot)
+		if err != nil {
+			return 0, fmt.Errorf("marshal library session %q: %w", record.SessionID, err)
+		}
+		if _, err := upsertStmt.ExecContext(ctx, record.SessionID, record.Snapshot.OwnerDeviceID, record.Snapshot.State.Version, claims, string(raw)); err != nil {
+			return 0, fmt.Errorf("upsert library session %q: %w", record.SessionID, err)
+		}
+		saved++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit library session tx: %w", err)
+	}
+	return saved, nil
+}
+
+func (s *draftRoomStore) LoadLibrarySessions(ctx context.Context) ([]librarySessionRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("draft room store not initialized")
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT session_id, session_json FROM game_libraries ORDER BY session_id ASC;`)
+	if err != nil {
+		return nil, fmt.Errorf("query library sessions: %w", err)
+	}
+	defer rows.Close()
+
+	records := make([]librarySessionRecord, 0)
+	for rows.Next() {
+		var sessionID string
+		var raw string
+		if err := rows.Scan(&sessionID, &raw); err != nil {
+			return nil, fmt.Errorf("scan library session row: %w", err)
+		}
+		var snapshot librarySessionSnapshot
+		if err := json.Unmarshal([]byte(raw), &snapshot); err != nil {
+			return nil, fmt.Errorf("decode library session %q: %w", sessionID, err)
+		}
+		records = append(records, librarySessionRecord{SessionID: sessionID, Snapshot: snapshot})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate library session rows: %w", err)
+	}
+	return records, nil
+}
+
+func (s *draftRoomStore) DeleteLibrarySession(ctx context.Context, sessionID string) error {
+	if s == nil || s.db == nil {
+		return errors.New("draft room store not initialized")
+	}
+	if sessionID == "" {
+		return errors.New("session id required")
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM game_libraries WHERE session_id = ?;`, sessionID); err != nil {
+		return fmt.Errorf("delete library session %q: %w", sessionID, err)
+	}
+	return nil
+}
+
+// SaveMatchResult inserts one match result and returns its id. played_at is
+// stored as unix milliseconds so results sort by when they were played.
+func (s *draftRoomStore) SaveMatchResult(ctx context.Context, result matchResult) (int64, error) {
+	if s == nil || s.db == nil {
+		return 0, errors.New("draft room store not initialized")
+	}
+	res, err := s.db.ExecContext(ctx, `
+INSERT INTO match_results (battlebox, p1_deck, p2_deck, p1_wins, p2_wins, on_play, p1_name, p2_name, guide, recorder_device_id, played_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+`, result.Battlebox, result.P1Deck, result.P2Deck, result.P1Wins, result.P2Wins, result.OnPlay,
+		result.P1Name, result.P2Name, result.Guide, result.RecorderDeviceID, result.PlayedAt.UnixMilli())
+	if err != nil {
+		return 0, fmt.Errorf("insert match result: %w", err)
+	}
+	matchID, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("read match result id: %w", err)
+	}
+	return matchID, nil
+}
+
+// LoadMatchResults returns a battlebox's results, most recently played first.
+func (s *draftRoomStore) LoadMatchResults(ctx context.Context, battlebox string) ([]matchResult, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("draft room store not initialized")
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+SELECT match_id, battlebox, p1_deck, p2_deck, p1_wins, p2_wins, on_play, p1_name, p2_name, guide, recorder_device_id, played_at
+FROM match_results
+WHERE battlebox = ?
+ORDER BY played_at DESC, match_id DESC;
+`, battlebox)
+	if err != nil {
+		return nil, fmt.Errorf("query match results: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]matchResult, 0)
+	for rows.Next() {
+		var result matchResult
+		var playedAt int64
+		if err := rows.Scan(&result.MatchID, &result.Battlebox, &result.P1Deck, &result.P2Deck, &result.P1Wins, &result.P2Wins,
+			&result.OnPlay, &result.P1Name, &result.P2Name, &result.Guide, &result.RecorderDeviceID, &playedAt); err != nil {
+			return nil, fmt.Errorf("scan match result row: %w", err)
+		}
+		result.PlayedAt = time.UnixMilli(playedAt).UTC()
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate match result rows: %w", err)
+	}
+	return results, nil
+}
+
+// DeleteMatchResult removes a result recorded by requesterDeviceID and returns
+// its battlebox.
+func (s *draftRoomStore) DeleteMatchResult(ctx context.Context, matchID int64, requesterDeviceID string) (string, error) {
+	if s == nil || s.db == nil {
+		return "", errors.New("draft room store not initialized")
+	}
+	var battlebox, recorder string
+	err := s.db.QueryRowContext(ctx, `SELECT battlebox, recorder_device_id FROM match_results WHERE match_id = ?;`, matchID).Scan(&battlebox, &recorder)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", errMatchResultNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("select match result %d: %w", matchID, err)
+	}
+	if recorder == "" || recorder != requesterDeviceID {
+		return "", errDraftRoomForbidden
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM match_results WHERE match_id = ?;`, matchID); err != nil {
+		return "", fmt.Errorf("delete match result %d: %w", matchID, err)
+	}
+	return battlebox, nil
+}
+
+// ReplaceRatings swaps a battlebox's stored ratings for ratings in one
+// transaction.
+func (s *draftRoomStore) ReplaceRatings(ctx context.Context, battlebox string, ratings []rating) error {
+	if s == nil || s.db == nil {
+		return errors.New("draft room store not initialized")
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin ratings tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM ratings WHERE battlebox = ?;`, battlebox); err != nil {
+		return fmt.Errorf("clear ratings for %q: %w", battlebox, err)
+	}
+	for _, r := range ratings {
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO ratings (battlebox, kind, name_key, name, rating, matches, wins, losses, draws, games_won, games_lost)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+`, battlebox, r.Kind, r.Key, r.Name, r.Rating, r.Matches, r.Wins, r.Losses, r.Draws, r.GamesWon, r.GamesLost); err != nil {
+			return fmt.Errorf("insert %s rating %q: %w", r.Kind, r.Key, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit ratings tx: %w", err)
+	}
+	return nil
+}
+
+// LoadRatings returns a battlebox's stored ratings, highest first.
+func (s *draftRoomStore) LoadRatings(ctx context.Context, battlebox string) ([]rating, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("draft room store not initialized")
+	}
+	rows, err := s.db.QueryContext(ctx, `
+SELECT kind, name_key, name, rating, matches, wins, losses, draws, games_won, games_lost
+FROM ratings
+WHERE battlebox = ?
+ORDER BY rating DESC, name_key ASC;
+`, battlebox)
+	if err != nil {
+		return nil, fmt.Errorf("query ratings: %w", err)
+	}
+	defer rows.Close()
+
+	ratings := make([]rating, 0)
+	for rows.Next() {
+		var r rating
+		if err := rows.Scan(&r.Kind, &r.Key, &r.Name, &r.Rating, &r.Matches, &r.Wins, &r.Losses, &r.Draws, &r.GamesWon, &r.GamesLost); err != nil {
+			return nil, fmt.Errorf("scan rating row: %w", err)
+		}
+		ratings = append(ratings, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rating rows: %w", err)
+	}
+	return ratings, nil
+}
+
 func (h *draftHub) snapshotRecords() []draftRoomRecord {
 	h.mu.RLock()
 	rooms := make([]*draftRoom, 0, len(h.rooms))
@@ -298,12 +991,25 @@ func (h *draftHub) restoreRooms(records []draftRoomRecord) error {
 		if ownerDeviceID == "" {
 			ownerDeviceID = record.Snapshot.OwnerDeviceID
 		}
+		createdAt := record.Snapshot.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+		updatedAt := record.Snapshot.UpdatedAt
+		if updatedAt.IsZero() {
+			updatedAt = createdAt
+		}
 		h.rooms[record.RoomID] = &draftRoom{
 			id:            record.RoomID,
 			deckSlug:      normalizeSlug(record.DeckSlug),
 			ownerDeviceID: ownerDeviceID,
+			hostName:      record.Snapshot.HostName,
 			draft:         draft,
 			clients:       make(map[int]map[*websocket.Conn]struct{}),
+			createdAt:     createdAt,
+			updatedAt:     updatedAt,
+			// Restored rooms were announced before the restart.
+			webhookState: draft.State(),
 		}
 	}
 	return nil
@@ -323,6 +1029,9 @@ func (r *draftRoom) snapshotRecord() draftRoomRecord {
 func snapshotFromRoom(r *draftRoom) draftRoomSnapshot {
 	snapshot := snapshotFromDraft(r.draft)
 	snapshot.OwnerDeviceID = r.ownerDeviceID
+	snapshot.HostName = r.hostName
+	snapshot.CreatedAt = r.createdAt
+	snapshot.UpdatedAt = r.updatedAt
 	return snapshot
 }
 
@@ -336,9 +1045,10 @@ func snapshotFromDraft(d *Draft) draftRoomSnapshot {
 				continue
 			}
 			rowCopy[j] = packSnapshot{
-				ID:     pack.ID,
-				Cards:  append([]string(nil), pack.Cards...),
-				Picked: append([]bool(nil), pack.Picked...),
+				ID:       pack.ID,
+				Cards:    append([]string(nil), pack.Cards...),
+				Picked:   append([]bool(nil), pack.Picked...),
+				PickedAt: append([]int(nil), pack.PickedAt...),
 			}
 		}
 		packs[i] = rowCopy
@@ -353,6 +1063,7 @@ func snapshotFromDraft(d *Draft) draftRoomSnapshot {
 				Mainboard: append([]string(nil), seat.Picks.Mainboard...),
 				Sideboard: append([]string(nil), seat.Picks.Sideboard...),
 			},
+			Deck: cloneSeatPicks(seat.Deck),
 		}
 	}
 
@@ -363,8 +1074,10 @@ func snapshotFromDraft(d *Draft) draftRoomSnapshot {
 		Progress:      d.Progress,
 		Seats:         seats,
 		SeatPicked:    append([]bool(nil), d.seatPicked...),
+		PassPicks:     clonePassPicks(d.passPicks),
 		LastSeqBySeat: append([]uint64(nil), d.lastSeqBySeat...),
 		GlobalSeq:     d.globalSeq,
+		Waiting:       d.waiting,
 	}
 }
 
@@ -377,6 +1090,9 @@ func draftFromSnapshot(snapshot draftRoomSnapshot) (*Draft, error) {
 	if cfg.PackCount <= 0 || cfg.PackSize <= 0 || cfg.SeatCount <= 0 {
 		return nil, errors.New("invalid draft config in snapshot")
 	}
+	if err := validateTeamSeatCount(cfg); err != nil {
+		return nil, fmt.Errorf("invalid team config in snapshot: %w", err)
+	}
 	passPattern, err := buildtool.NormalizeDraftPassPattern(cfg.PackSize, cfg.PassPattern)
 	if err != nil {
 		return nil, fmt.Errorf("invalid pass pattern in snapshot: %w", err)
@@ -403,6 +1119,10 @@ func draftFromSnapshot(snapshot draftRoomSnapshot) (*Draft, error) {
 				Cards:  append([]string(nil), pack.Cards...),
 				Picked: append([]bool(nil), pack.Picked...),
 			}
+			// Snapshots taken before pick positions were tracked leave PickedAt nil.
+			if len(pack.PickedAt) == cfg.PackSize {
+				packRow[seat].PickedAt = append([]int(nil), pack.PickedAt...)
+			}
 		}
 		packs[packNo] = packRow
 	}
@@ -419,6 +1139,7 @@ func draftFromSnapshot(snapshot draftRoomSnapshot) (*Draft, error) {
 				Mainboard: append([]string(nil), seat.Picks.Mainboard...),
 				Sideboard: append([]string(nil), seat.Picks.Sideboard...),
 			},
+			Deck: cloneSeatPicks(seat.Deck),
 		}
 	}
 
@@ -445,9 +1166,19 @@ func draftFromSnapshot(snapshot draftRoomSnapshot) (*Draft, error) {
 	lastSeqBySeat := make([]uint64, cfg.SeatCount)
 	copy(lastSeqBySeat, snapshot.LastSeqBySeat)
 
+	// Snapshots written before pick retraction carry no pass picks; those seats simply cannot retract.
+	passPicks := make([]*PassPick, cfg.SeatCount)
+	if len(snapshot.PassPicks) > 0 {
+		if len(snapshot.PassPicks) != cfg.SeatCount {
+			return nil, fmt.Errorf("pass picks count mismatch: got %d want %d", len(snapshot.PassPicks), cfg.SeatCount)
+		}
+		copy(passPicks, clonePassPicks(snapshot.PassPicks))
+	}
+
 	if progress.PackNumber >= cfg.PackCount {
 		for i := range seatPicked {
 			seatPicked[i] = false
+			passPicks[i] = nil
 		}
 	}
 
@@ -457,7 +1188,24 @@ func draftFromSnapshot(snapshot draftRoomSnapshot) (*Draft, error) {
 		Progress:      progress,
 		Seats:         seats,
 		seatPicked:    seatPicked,
+		passPicks:     passPicks,
 		lastSeqBySeat: lastSeqBySeat,
 		globalSeq:     snapshot.GlobalSeq,
+		waiting:       snapshot.Waiting,
 	}, nil
 }
+
+func clonePassPicks(passPicks []*PassPick) []*PassPick {
+	out := make([]*PassPick, len(passPicks))
+	for i, passPick := range passPicks {
+		if passPick == nil {
+			continue
+		}
+		out[i] = &PassPick{
+			PackID:  passPick.PackID,
+			Indices: append([]int(nil), passPick.Indices...),
+			Picks:   append([]PickSelection(nil), passPick.Picks...),
+		}
+	}
+	return out
+}
@@ -111,3 +111,18 @@ func TestDraftRoomStoreDeleteRoom(t *testing.T) {
 	require.NoError(t, err, "LoadRooms")
 	assert.Empty(t, records, "expected no rooms after delete")
 }
+
+func TestDraftSnapshotKeepsRetractablePick(t *testing.T) {
+	draft := makeDraft(t, 1, 2, 2)
+	seat0State, err := draft.PlayerState(0)
+	require.NoError(t, err, "seat 0 PlayerState")
+	_, err = draft.Pick(0, 1, seat0State.Active.PackID, seat0State.Active.Cards[0], PickZoneMainboard)
+	require.NoError(t, err, "seat 0 pick")
+
+	restored, err := draftFromSnapshot(snapshotFromDraft(draft))
+	require.NoError(t, err, "draftFromSnapshot")
+
+	_, err = restored.RetractPick(0, 2, seat0State.Active.PackID)
+	require.NoError(t, err, "restored draft should allow retract")
+	assert.Empty(t, restored.Seats[0].Picks.Mainboard, "retract should clear restored pick")
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultDeckSize is the registered mainboard size when DraftConfig.DeckSize is unset.
+const DefaultDeckSize = 40
+
+var errDeckLocked = errors.New("deck already registered")
+
+var manaSymbolRE = regexp.MustCompile(`\{([^}]+)\}`)
+
+// basicLandKeyByColor maps a coloured mana symbol to the basic that produces it.
+var basicLandKeyByColor = map[string]string{
+	"W": "plains",
+	"U": "island",
+	"B": "swamp",
+	"R": "mountain",
+	"G": "forest",
+}
+
+func (d *Draft) deckSize() int {
+	if d.Config.DeckSize > 0 {
+		return d.Config.DeckSize
+	}
+	return DefaultDeckSize
+}
+
+func cloneSeatPicks(picks *SeatPicks) *SeatPicks {
+	if picks == nil {
+		return nil
+	}
+	return &SeatPicks{
+		Mainboard: append([]string{}, picks.Mainboard...),
+		Sideboard: append([]string{}, picks.Sideboard...),
+	}
+}
+
+// RegisterDeck locks in a seat's final deck once the draft is done.
+// The mainboard must be exactly the configured deck size and built from the seat's
+// pool plus any number of basic lands; every other pooled card becomes sideboard.
+// A registered deck cannot be changed and is what pairings and exports should use.
+func (d *Draft) RegisterDeck(seat int, seq uint64, mainboard []string) (PickResult, error) {
+	if err := d.validateSeatIndex(seat); err != nil {
+		return PickResult{}, err
+	}
+	if d.State() != "done" {
+		return PickResult{}, errDraftNotComplete
+	}
+	if duplicateResult, duplicate, err := d.validateMutationSeq(seat, seq); err != nil {
+		return PickResult{}, err
+	} else if duplicate {
+		return duplicateResult, nil
+	}
+
+	seatState := &d.Seats[seat]
+	if seatState.Deck != nil {
+		return PickResult{}, errDeckLocked
+	}
+	if len(mainboard) != d.deckSize() {
+		return PickResult{}, fmt.Errorf("deck must have %d cards, got %d", d.deckSize(), len(mainboard))
+	}
+
+	pool := map[string]int{}
+	for _, cardName := range seatState.Picks.Mainboard {
+		if !isBasicLandCardName(cardName) {
+			pool[cardName]++
+		}
+	}
+	for _, cardName := range seatState.Picks.Sideboard {
+		if !isBasicLandCardName(cardName) {
+			pool[cardName]++
+		}
+	}
+
+	deck := SeatPicks{
+		Mainboard: make([]string, 0, len(mainboard)),
+		Sideboard: []string{},
+	}
+	for _, cardName := range mainboard {
+		if key := normalizeBasicLandKey(cardName); isBasicLandCardName(key) {
+			deck.Mainboard = append(deck.Mainboard, basicLandNameByKey[key])
+			continue
+		}
+		if pool[cardName] <= 0 {
+			return PickResult{}, fmt.Errorf("card %q not in pool", cardName)
+		}
+		pool[cardName]--
+		deck.Mainboard = append(deck.Mainboard, cardName)
+	}
+	// Keep the pool's pick order for the leftover sideboard.
+	for _, cardName := range append(append([]string(nil), seatState.Picks.Mainboard...), seatState.Picks.Sideboard...) {
+		if isBasicLandCardName(cardName) || pool[cardName] <= 0 {
+			continue
+		}
+		pool[cardName]--
+		deck.Sideboard = append(deck.Sideboard, cardName)
+	}
+
+	seatState.Picks = SeatPicks{
+		Mainboard: append([]string{}, deck.Mainboard...),
+		Sideboard: append([]string{}, deck.Sideboard...),
+	}
+	seatState.Deck = &deck
+	d.lastSeqBySeat[seat] = seq
+	d.globalSeq++
+
+	state, err := d.PlayerState(seat)
+	if err != nil {
+		return PickResult{}, err
+	}
+	return PickResult{State: state, Events: nil, Duplicate: false}, nil
+}
+
+// SuggestBasics proposes basic land counts that fill a seat's mainboard up to the
+// deck size, split by the coloured mana symbols in its non-basic cards.
+// manaCosts maps card names to mana cost strings such as "{1}{W}{U/B}";
+// cards without a known cost are ignored.
+func (d *Draft) SuggestBasics(seat int, manaCosts map[string]string) (map[string]int, error) {
+	if err := d.validateSeatIndex(seat); err != nil {
+		return nil, err
+	}
+	nonBasics := make([]string, 0, len(d.Seats[seat].Picks.Mainboard))
+	for _, cardName := range d.Seats[seat].Picks.Mainboard {
+		if !isBasicLandCardName(cardName) {
+			nonBasics = append(nonBasics, cardName)
+		}
+	}
+	return suggestBasicSplit(nonBasics, manaCosts, d.deckSize()-len(nonBasics)), nil
+}
+
+// suggestBasicSplit distributes landSlots basics proportionally to colour pips
+// using largest remainders, breaking ties in WUBRG order.
+func suggestBasicSplit(cards []string, manaCosts map[string]string, landSlots int) map[string]int {
+	counts := make(map[string]int, len(basicLandKeys))
+	for _, key := range basicLandKeys {
+		counts[key] = 0
+	}
+
+	pips := map[string]int{}
+	total := 0
+	for _, cardName := range cards {
+		for _, key := range manaCostBasicKeys(manaCosts[cardName]) {
+			pips[key]++
+			total++
+		}
+	}
+	if landSlots <= 0 || total == 0 {
+		return counts
+	}
+	if landSlots > len(basicLandKeys)*BasicLandMaxCount {
+		landSlots = len(basicLandKeys) * BasicLandMaxCount
+	}
+
+	remainders := make(map[string]int, len(basicLandKeys))
+	assigned := 0
+	for _, key := range basicLandKeys {
+		share := pips[key] * landSlots
+		counts[key] = share / total
+		remainders[key] = share % total
+		assigned += counts[key]
+	}
+	order := append([]string(nil), basicLandKeys...)
+	sort.SliceStable(order, func(i, j int) bool {
+		return remainders[order[i]] > remainders[order[j]]
+	})
+	for i := 0; assigned < landSlots; i = (i + 1) % len(order) {
+		if pips[order[i]] == 0 {
+			continue
+		}
+		counts[order[i]]++
+		assigned++
+	}
+	for _, key := range basicLandKeys {
+		counts[key] = clampBasicLandCount(counts[key])
+	}
+	return counts
+}
+
+// manaCostBasicKeys returns one basic land key per coloured pip in a mana cost.
+// Hybrid symbols count toward each of their colours; Phyrexian symbols count their colour.
+func manaCostBasicKeys(manaCost string) []string {
+	var keys []string
+	for _, match := range manaSymbolRE.FindAllStringSubmatch(manaCost, -1) {
+		for _, part := range strings.Split(strings.ToUpper(match[1]), "/") {
+			if key, ok := basicLandKeyByColor[part]; ok {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
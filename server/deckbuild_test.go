@@ -0,0 +1,125 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeFinishedDraft(t *testing.T, deckSize int) *Draft {
+	t.Helper()
+
+	d := makeDraftWithConfig(t, DraftConfig{PackCount: 1, PackSize: 3, SeatCount: 2, DeckSize: deckSize})
+	for d.State() != "done" {
+		for seat := 0; seat < d.Config.SeatCount; seat++ {
+			if d.seatPicked[seat] {
+				continue
+			}
+			_, err := d.randomPickBatchForSeat(seat, PickZoneMainboard)
+			require.NoErrorf(t, err, "bot pick seat %d", seat)
+		}
+	}
+	return d
+}
+
+func TestRegisterDeckValidatesPoolAndLocks(t *testing.T) {
+	d := makeFinishedDraft(t, 5)
+	pool := append([]string(nil), d.Seats[0].Picks.Mainboard...)
+	require.Len(t, pool, 3, "seat 0 pool size")
+
+	_, err := d.RegisterDeck(0, 4, []string{pool[0], "Island"})
+	require.Error(t, err, "wrong deck size should be rejected")
+	_, err = d.RegisterDeck(0, 4, []string{pool[0], pool[0], "Island", "Island", "Island"})
+	require.Error(t, err, "cards beyond the pool should be rejected")
+	_, err = d.RegisterDeck(0, 4, []string{pool[0], d.Seats[1].Picks.Mainboard[0], "Island", "Island", "Island"})
+	require.Error(t, err, "another seat's card should be rejected")
+
+	res, err := d.RegisterDeck(0, 4, []string{pool[0], pool[1], "island", "Island", "Swamp"})
+	require.NoError(t, err, "RegisterDeck")
+	require.NotNil(t, res.State.Deck, "registered deck in state")
+	assert.Equal(t, []string{pool[0], pool[1], "Island", "Island", "Swamp"}, res.State.Deck.Mainboard, "mainboard mismatch")
+	assert.Equal(t, []string{pool[2]}, res.State.Deck.Sideboard, "unused pool should be sideboard")
+
+	_, err = d.RegisterDeck(0, 5, []string{pool[0], pool[2], "Island", "Island", "Swamp"})
+	assert.ErrorIs(t, err, errDeckLocked, "second registration should be rejected")
+	_, err = d.MovePick(0, 5, pool[0], PickZoneMainboard, PickZoneSideboard)
+	assert.ErrorIs(t, err, errDeckLocked, "moves after registration should be rejected")
+	_, err = d.SetBasics(0, 5, map[string]int{"plains": 1, "island": 0, "swamp": 0, "mountain": 0, "forest": 0})
+	assert.ErrorIs(t, err, errDeckLocked, "basics after registration should be rejected")
+
+	dup, err := d.RegisterDeck(0, 4, nil)
+	require.NoError(t, err, "duplicate seq should be idempotent")
+	assert.True(t, dup.Duplicate, "duplicate flag")
+
+	restored, err := draftFromSnapshot(snapshotFromDraft(d))
+	require.NoError(t, err, "draftFromSnapshot")
+	st, err := restored.PlayerState(0)
+	require.NoError(t, err, "restored PlayerState")
+	assert.Equal(t, res.State.Deck, st.Deck, "registered deck should survive restore")
+}
+
+func TestRegisterDeckRequiresFinishedDraft(t *testing.T) {
+	d := makeDraftWithConfig(t, DraftConfig{PackCount: 1, PackSize: 3, SeatCount: 2})
+	_, err := d.RegisterDeck(0, 1, []string{"Island"})
+	require.Error(t, err, "registration before the draft ends should be rejected")
+}
+
+func TestSuggestBasicsSplitsByColourPips(t *testing.T) {
+	d := makeFinishedDraft(t, 10)
+	pool := d.Seats[0].Picks.Mainboard
+	manaCosts := map[string]string{
+		pool[0]: "{1}{W}{W}",
+		pool[1]: "{U/B}",
+		pool[2]: "{2}{W/P}",
+	}
+
+	basics, err := d.SuggestBasics(0, manaCosts)
+	require.NoError(t, err, "SuggestBasics")
+	assert.Equal(t, map[string]int{"plains": 4, "island": 2, "swamp": 1, "mountain": 0, "forest": 0}, basics, "basic split mismatch")
+
+	colourless, err := d.SuggestBasics(0, nil)
+	require.NoError(t, err, "SuggestBasics without costs")
+	for key, count := range colourless {
+		assert.Zerof(t, count, "%s should be zero without coloured pips", key)
+	}
+}
+
+func TestBuiltCardManaCostsReadsBattleboxJSON(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, body string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644), "write %s", name)
+	}
+	writeFile("cube.json", `{"slug":"cube","decks":[{"slug":"modern","cards":[{"name":"Lightning Bolt","mana_cost":"{R}"}],"sideboard":[{"name":"Counterspell","mana_cost":"{U}{U}"}]}]}`)
+	writeFile("index.json", `{"battleboxes":[]}`)
+
+	source := &builtCardManaCosts{built: newBuiltBattleboxes(dir)}
+	got := source.ManaCosts([]string{"lightning bolt", "Counterspell", "Unknown"})
+	assert.Equal(t, map[string]string{"lightning bolt": "{R}", "Counterspell": "{U}{U}"}, got, "mana cost lookup mismatch")
+}
+
+func TestBuiltBattleboxesReloadOnNewBuildID(t *testing.T) {
+	dir := t.TempDir()
+	writeBuild := func(buildID, manaCost string, mod time.Time) {
+		t.Helper()
+		cube := `{"slug":"cube","decks":[{"slug":"modern","cards":[{"name":"Lightning Bolt","mana_cost":"` + manaCost + `"}]}]}`
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "cube.json"), []byte(cube), 0o644), "write cube.json")
+		indexPath := filepath.Join(dir, "index.json")
+		require.NoError(t, os.WriteFile(indexPath, []byte(`{"build_id":"`+buildID+`"}`), 0o644), "write index.json")
+		require.NoError(t, os.Chtimes(indexPath, mod, mod), "touch index.json")
+	}
+	start := time.Now().Add(-time.Hour)
+	writeBuild("a", "{R}", start)
+
+	source := &builtCardManaCosts{built: newBuiltBattleboxes(dir)}
+	assert.Equal(t, map[string]string{"Lightning Bolt": "{R}"}, source.ManaCosts([]string{"Lightning Bolt"}), "first build")
+
+	writeBuild("a", "{1}{R}", start.Add(time.Minute))
+	assert.Equal(t, map[string]string{"Lightning Bolt": "{R}"}, source.ManaCosts([]string{"Lightning Bolt"}), "same build id keeps the cache")
+
+	writeBuild("b", "{1}{R}", start.Add(2*time.Minute))
+	assert.Equal(t, map[string]string{"Lightning Bolt": "{1}{R}"}, source.ManaCosts([]string{"Lightning Bolt"}), "new build id reloads")
+}
@@ -5,9 +5,11 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	mathrand "math/rand/v2"
 	"strings"
 	"time"
 
+	"github.com/lxing/battlebox/draftproto"
 	"github.com/lxing/battlebox/internal/buildtool"
 )
 
@@ -15,30 +17,43 @@ import (
 // packCount: number of packs each seat will see over the draft
 // packSize: number of cards in each pack
 // seatCount: number of seats in the room
+// seed: drives shuffling, collation and bot picks so a draft can be replayed
+// teamDraft: seats alternate between two teams that see each other's picks
+// deckSize: mainboard size a seat must register after the draft (0 = DefaultDeckSize)
 type DraftConfig struct {
 	PackCount   int
 	PackSize    int
 	SeatCount   int
 	PassPattern []int
+	Seed        uint64
+	TeamDraft   bool
+	DeckSize    int
 }
 
 // Pack tracks the cards in a single booster plus which indices have been taken.
 type Pack struct {
-	ID     string
-	Cards  []string
-	Picked []bool // picked[i] = true when card i has been taken
-}
-
-type SeatPicks struct {
-	Mainboard []string `json:"mainboard"`
-	Sideboard []string `json:"sideboard"`
-}
+	ID       string
+	Cards    []string
+	Picked   []bool // picked[i] = true when card i has been taken
+	PickedAt []int  // pickedAt[i] = 1-based position card i was picked from the pack; 0 if burned or unpicked
+}
+
+// Wire types shared with headless clients live in the draftproto package.
+type (
+	SeatPicks     = draftproto.SeatPicks
+	PackView      = draftproto.PackView
+	TeammateView  = draftproto.TeammateView
+	DraftPairing  = draftproto.DraftPairing
+	PlayerState   = draftproto.PlayerState
+	PickSelection = draftproto.PickSelection
+)
 
 // SeatState records everything the server knows about a drafter.
 type SeatState struct {
 	SeatNumber int
 	Name       string
-	Picks      SeatPicks // cards the drafter has picked so far, split by destination
+	Picks      SeatPicks  // cards the drafter has picked so far, split by destination
+	Deck       *SeatPicks // registered deck; nil until the seat locks in its deck
 }
 
 // DraftProgress captures where the table currently is.
@@ -48,29 +63,9 @@ type DraftProgress struct {
 	PickNumber int
 }
 
-// PackView is the seat-local view of an active pack.
-type PackView struct {
-	PackID string   `json:"pack_id"`
-	Cards  []string `json:"cards"`
-}
-
-// PlayerState is a seat-local snapshot.
-type PlayerState struct {
-	SeatID        int       `json:"seat_id"`
-	SeatCount     int       `json:"seat_count"`
-	State         string    `json:"state"`
-	Picks         SeatPicks `json:"picks"`
-	Active        *PackView `json:"active_pack,omitempty"`
-	PackNo        int       `json:"pack_no"`
-	PickNo        int       `json:"pick_no"`
-	ExpectedPicks int       `json:"expected_picks"`
-	CanPick       bool      `json:"can_pick"`
-	NextSeq       uint64    `json:"next_seq"`
-}
-
 const (
-	PickZoneMainboard = "mainboard"
-	PickZoneSideboard = "sideboard"
+	PickZoneMainboard = draftproto.ZoneMainboard
+	PickZoneSideboard = draftproto.ZoneSideboard
 
 	BasicLandMinCount = 0
 	BasicLandMaxCount = 20
@@ -93,10 +88,17 @@ type PickResult struct {
 	Duplicate bool
 }
 
-type PickSelection struct {
-	CardName string `json:"card_name"`
-	Zone     string `json:"zone"`
-}
+var (
+	errInvalidSeat       = errors.New("invalid seat")
+	errInvalidSeq        = errors.New("invalid seq")
+	errStaleSeq          = errors.New("stale seq")
+	errSeqGap            = errors.New("seq gap")
+	errPackMismatch      = errors.New("pack mismatch")
+	errSeatAlreadyPicked = errors.New("seat already picked this round")
+	errDraftComplete     = errors.New("draft already complete")
+	errDraftNotComplete  = errors.New("draft not complete")
+	errDraftNotStarted   = errors.New("draft not started")
+)
 
 // Event is a game-domain event emitted by picks.
 type Event interface{ isEvent() }
@@ -114,6 +116,23 @@ type DraftCompleted struct{}
 
 func (DraftCompleted) isEvent() {}
 
+// PickRetracted fires when a seat undoes its pick for the current pass.
+type PickRetracted struct {
+	Seat   int
+	PackID string
+	Cards  []string
+}
+
+func (PickRetracted) isEvent() {}
+
+// PassPick records one seat's picks in the current pass so they can be retracted
+// until the round advances.
+type PassPick struct {
+	PackID  string          `json:"pack_id"`
+	Indices []int           `json:"indices"`
+	Picks   []PickSelection `json:"picks"`
+}
+
 // Draft is the authoritative state for one draft. Once started, it is immutable
 // in structure; only progress, packs, and picks advance.
 type Draft struct {
@@ -123,17 +142,23 @@ type Draft struct {
 	Progress DraftProgress
 	Seats    []SeatState
 
-	seatPicked    []bool   // seatPicked[seat] is true after seat picks in current round
-	lastSeqBySeat []uint64 // monotonic command sequence per seat for idempotency
-	globalSeq     uint64   // global monotonically increasing mutation sequence for snapshot/version checks
+	seatPicked    []bool      // seatPicked[seat] is true after seat picks in current round
+	passPicks     []*PassPick // passPicks[seat] is the seat's retractable pick in current round
+	lastSeqBySeat []uint64    // monotonic command sequence per seat for idempotency
+	globalSeq     uint64      // global monotonically increasing mutation sequence for snapshot/version checks
+	waiting       bool        // packs are dealt but picks are rejected until Start
 }
 
 // NewDraft constructs and immediately starts a draft from a deck list.
-// The deck is shuffled internally so callers don't need to pre-shuffle.
+// The deck is shuffled internally from cfg.Seed so callers don't need to pre-shuffle,
+// and the same seed and deck list always produce the same packs.
 func NewDraft(cfg DraftConfig, deckList []string) (*Draft, error) {
 	if cfg.PackCount <= 0 || cfg.PackSize <= 0 || cfg.SeatCount <= 0 {
 		return nil, errors.New("invalid draft config")
 	}
+	if err := validateTeamSeatCount(cfg); err != nil {
+		return nil, err
+	}
 	passPattern, err := buildtool.NormalizeDraftPassPattern(cfg.PackSize, cfg.PassPattern)
 	if err != nil {
 		return nil, err
@@ -144,7 +169,7 @@ func NewDraft(cfg DraftConfig, deckList []string) (*Draft, error) {
 	if len(deckList) < requiredCards {
 		return nil, errors.New("deck too small for requested draft config")
 	}
-	shuffledDeck := shuffleStrings(deckList)
+	shuffledDeck := shuffleStrings(deckList, newDraftRand(cfg.Seed, 0))
 
 	packs := make([][]*Pack, cfg.PackCount)
 	deckIdx := 0
@@ -154,9 +179,10 @@ func NewDraft(cfg DraftConfig, deckList []string) (*Draft, error) {
 			cards := make([]string, cfg.PackSize)
 			copy(cards, shuffledDeck[deckIdx:deckIdx+cfg.PackSize])
 			packRow[originSeat] = &Pack{
-				ID:     fmt.Sprintf("p%d_s%d", packNo, originSeat),
-				Cards:  cards,
-				Picked: make([]bool, cfg.PackSize),
+				ID:       fmt.Sprintf("p%d_s%d", packNo, originSeat),
+				Cards:    cards,
+				Picked:   make([]bool, cfg.PackSize),
+				PickedAt: make([]int, cfg.PackSize),
 			}
 			deckIdx += cfg.PackSize
 		}
@@ -181,20 +207,44 @@ func NewDraft(cfg DraftConfig, deckList []string) (*Draft, error) {
 		Progress:      DraftProgress{PackNumber: 0, PickNumber: 0},
 		Seats:         seats,
 		seatPicked:    make([]bool, cfg.SeatCount),
+		passPicks:     make([]*PassPick, cfg.SeatCount),
 		lastSeqBySeat: make([]uint64, cfg.SeatCount),
 	}, nil
 }
 
-func shuffleStrings(values []string) []string {
+func shuffleStrings(values []string, rng *mathrand.Rand) []string {
 	shuffled := make([]string, len(values))
 	copy(shuffled, values)
 	for i := len(shuffled) - 1; i > 0; i-- {
-		j := randomIndex(i + 1)
+		j := rng.IntN(i + 1)
 		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
 	}
 	return shuffled
 }
 
+// newDraftRand returns a deterministic PRNG for one stream of a seeded draft.
+// Stream 0 is reserved for the initial shuffle.
+func newDraftRand(seed, stream uint64) *mathrand.Rand {
+	return mathrand.New(mathrand.NewPCG(seed, stream))
+}
+
+// botPickRand derives the PRNG for a seat's bot pick from the draft position,
+// so bot picks replay identically and survive snapshot restores.
+func (d *Draft) botPickRand(seat int) *mathrand.Rand {
+	stream := uint64(d.Progress.PackNumber)<<40 | uint64(d.Progress.PickNumber)<<20 | uint64(seat)
+	return newDraftRand(d.Config.Seed, stream+1)
+}
+
+// randomDraftSeed picks a fresh seed for drafts created without one. Seeds stay
+// within 53 bits so they survive a round trip through JavaScript numbers.
+func randomDraftSeed() uint64 {
+	var raw [8]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return uint64(time.Now().UnixNano()) & (1<<53 - 1)
+	}
+	return binary.BigEndian.Uint64(raw[:]) & (1<<53 - 1)
+}
+
 func randomIndex(max int) int {
 	if max <= 1 {
 		return 0
@@ -206,14 +256,104 @@ func randomIndex(max int) int {
 	return int(binary.BigEndian.Uint64(raw[:]) % uint64(max))
 }
 
-// State reports "drafting" until all packs are consumed, then "done".
+func validateTeamSeatCount(cfg DraftConfig) error {
+	if !cfg.TeamDraft {
+		return nil
+	}
+	if cfg.SeatCount < 4 || cfg.SeatCount%2 != 0 {
+		return errors.New("team draft requires an even seat count of at least 4")
+	}
+	return nil
+}
+
+// seatTeam returns the 1-based team for a seat. Teams alternate around the table
+// so every pass goes to an opponent. Non-team drafts report 0.
+func (d *Draft) seatTeam(seat int) int {
+	if !d.Config.TeamDraft {
+		return 0
+	}
+	return seat%2 + 1
+}
+
+// teammates lists the other seats on the same team, in seat order.
+func (d *Draft) teammates(seat int) []int {
+	team := d.seatTeam(seat)
+	if team == 0 {
+		return nil
+	}
+	out := make([]int, 0, d.Config.SeatCount/2)
+	for other := 0; other < d.Config.SeatCount; other++ {
+		if other != seat && d.seatTeam(other) == team {
+			out = append(out, other)
+		}
+	}
+	return out
+}
+
+// PendingSeats returns the seats that still have to pick in the current round.
+func (d *Draft) PendingSeats() []int {
+	pending := []int{}
+	if d.State() != "drafting" {
+		return pending
+	}
+	for seat, picked := range d.seatPicked {
+		if !picked {
+			pending = append(pending, seat)
+		}
+	}
+	return pending
+}
+
+// Pairings returns the post-draft team pairings: each round matches every
+// member of one team against a different member of the other team.
+func (d *Draft) Pairings() []DraftPairing {
+	if !d.Config.TeamDraft || d.State() != "done" {
+		return nil
+	}
+	teamSize := d.Config.SeatCount / 2
+	pairings := make([]DraftPairing, 0, teamSize*teamSize)
+	for round := 0; round < teamSize; round++ {
+		for i := 0; i < teamSize; i++ {
+			// Team 1 sits on even seats, team 2 on odd seats.
+			seatA := 2 * i
+			seatB := 2*((i+round)%teamSize) + 1
+			pairings = append(pairings, DraftPairing{Round: round + 1, Seats: [2]int{seatA, seatB}})
+		}
+	}
+	return pairings
+}
+
+// State reports "waiting" before Start, "drafting" until all packs are consumed, then "done".
 func (d *Draft) State() string {
 	if d.Progress.PackNumber >= d.Config.PackCount {
 		return "done"
 	}
+	if d.waiting {
+		return "waiting"
+	}
 	return "drafting"
 }
 
+// newWaitingDraft deals a draft that accepts no picks until Start is called.
+func newWaitingDraft(cfg DraftConfig, deckList []string) (*Draft, error) {
+	d, err := NewDraft(cfg, deckList)
+	if err != nil {
+		return nil, err
+	}
+	d.waiting = true
+	return d, nil
+}
+
+// Start opens a waiting draft for picks.
+func (d *Draft) Start() error {
+	if !d.waiting {
+		return errors.New("draft already started")
+	}
+	d.waiting = false
+	d.globalSeq++
+	return nil
+}
+
 func (d *Draft) picksThisPass() int {
 	if d.Progress.PackNumber >= d.Config.PackCount {
 		return 0
@@ -271,7 +411,7 @@ func (d *Draft) burnRemainingCurrentPack() bool {
 
 func (d *Draft) currentPackForSeat(seat int) (*Pack, error) {
 	if seat < 0 || seat >= d.Config.SeatCount {
-		return nil, errors.New("invalid seat")
+		return nil, errInvalidSeat
 	}
 	if d.State() == "done" {
 		return nil, errors.New("draft complete")
@@ -296,7 +436,7 @@ func (d *Draft) currentPackForSeat(seat int) (*Pack, error) {
 // PlayerState returns a seat-local snapshot.
 func (d *Draft) PlayerState(seat int) (PlayerState, error) {
 	if seat < 0 || seat >= d.Config.SeatCount {
-		return PlayerState{}, errors.New("invalid seat")
+		return PlayerState{}, errInvalidSeat
 	}
 
 	state := PlayerState{
@@ -308,6 +448,8 @@ func (d *Draft) PlayerState(seat int) (PlayerState, error) {
 		PickNo:        d.currentPickNo(),
 		ExpectedPicks: d.picksThisPass(),
 		NextSeq:       d.lastSeqBySeat[seat] + 1,
+		DeckSize:      d.deckSize(),
+		Deck:          cloneSeatPicks(d.Seats[seat].Deck),
 	}
 
 	mainboardCopy := make([]string, len(d.Seats[seat].Picks.Mainboard))
@@ -318,8 +460,24 @@ func (d *Draft) PlayerState(seat int) (PlayerState, error) {
 		Mainboard: mainboardCopy,
 		Sideboard: sideboardCopy,
 	}
+	state.Team = d.seatTeam(seat)
+	for _, other := range d.teammates(seat) {
+		state.Teammates = append(state.Teammates, TeammateView{
+			SeatID: other,
+			Name:   d.Seats[other].Name,
+			Picks: SeatPicks{
+				Mainboard: append([]string{}, d.Seats[other].Picks.Mainboard...),
+				Sideboard: append([]string{}, d.Seats[other].Picks.Sideboard...),
+			},
+			Deck: cloneSeatPicks(d.Seats[other].Deck),
+		})
+	}
 
 	if state.State == "done" {
+		state.Pairings = d.Pairings()
+		return state, nil
+	}
+	if state.State == "waiting" {
 		return state, nil
 	}
 
@@ -336,6 +494,7 @@ func (d *Draft) PlayerState(seat int) (PlayerState, error) {
 	}
 	state.Active = &PackView{PackID: pack.ID, Cards: visible}
 	state.CanPick = !d.seatPicked[seat] && len(visible) >= state.ExpectedPicks && state.ExpectedPicks > 0
+	state.CanRetract = d.seatPicked[seat] && d.passPicks[seat] != nil
 	return state, nil
 }
 
@@ -379,7 +538,7 @@ func clampBasicLandCount(value int) int {
 
 func (d *Draft) validateSeatIndex(seat int) error {
 	if seat < 0 || seat >= d.Config.SeatCount {
-		return errors.New("invalid seat")
+		return errInvalidSeat
 	}
 	return nil
 }
@@ -387,7 +546,7 @@ func (d *Draft) validateSeatIndex(seat int) error {
 func (d *Draft) validateMutationSeq(seat int, seq uint64) (PickResult, bool, error) {
 	lastSeq := d.lastSeqBySeat[seat]
 	if seq == 0 {
-		return PickResult{}, false, errors.New("invalid seq")
+		return PickResult{}, false, errInvalidSeq
 	}
 	if seq == lastSeq {
 		state, err := d.PlayerState(seat)
@@ -397,10 +556,10 @@ func (d *Draft) validateMutationSeq(seat int, seq uint64) (PickResult, bool, err
 		return PickResult{State: state, Events: nil, Duplicate: true}, true, nil
 	}
 	if seq < lastSeq {
-		return PickResult{}, false, errors.New("stale seq")
+		return PickResult{}, false, errStaleSeq
 	}
 	if seq != lastSeq+1 {
-		return PickResult{}, false, errors.New("seq gap")
+		return PickResult{}, false, errSeqGap
 	}
 	return PickResult{}, false, nil
 }
@@ -417,6 +576,9 @@ func (d *Draft) MovePick(seat int, seq uint64, cardName, fromZone, toZone string
 	if isBasicLandCardName(cardName) {
 		return PickResult{}, errors.New("basic lands cannot move zones")
 	}
+	if d.Seats[seat].Deck != nil {
+		return PickResult{}, errDeckLocked
+	}
 	if fromZone == toZone {
 		return PickResult{}, errors.New("source and destination zones must differ")
 	}
@@ -471,6 +633,9 @@ func (d *Draft) SetBasics(seat int, seq uint64, basics map[string]int) (PickResu
 	if len(basics) == 0 {
 		return PickResult{}, errors.New("basic counts required")
 	}
+	if d.Seats[seat].Deck != nil {
+		return PickResult{}, errDeckLocked
+	}
 
 	if duplicateResult, duplicate, err := d.validateMutationSeq(seat, seq); err != nil {
 		return PickResult{}, err
@@ -532,7 +697,10 @@ func (d *Draft) PickBatch(seat int, seq uint64, packID string, picks []PickSelec
 		return PickResult{}, err
 	}
 	if d.State() == "done" {
-		return PickResult{}, errors.New("draft already complete")
+		return PickResult{}, errDraftComplete
+	}
+	if d.waiting {
+		return PickResult{}, errDraftNotStarted
 	}
 	if duplicateResult, duplicate, err := d.validateMutationSeq(seat, seq); err != nil {
 		return PickResult{}, err
@@ -540,7 +708,7 @@ func (d *Draft) PickBatch(seat int, seq uint64, packID string, picks []PickSelec
 		return duplicateResult, nil
 	}
 	if d.seatPicked[seat] {
-		return PickResult{}, errors.New("seat already picked this round")
+		return PickResult{}, errSeatAlreadyPicked
 	}
 
 	pack, err := d.currentPackForSeat(seat)
@@ -548,7 +716,7 @@ func (d *Draft) PickBatch(seat int, seq uint64, packID string, picks []PickSelec
 		return PickResult{}, err
 	}
 	if pack.ID != packID {
-		return PickResult{}, errors.New("pack mismatch")
+		return PickResult{}, errPackMismatch
 	}
 
 	expectedPicks := d.picksThisPass()
@@ -589,9 +757,16 @@ func (d *Draft) PickBatch(seat int, seq uint64, packID string, picks []PickSelec
 		chosenIndices[i] = cardIdx
 	}
 
+	takenBefore := 0
+	for i := 0; i < d.Progress.PickNumber; i++ {
+		takenBefore += d.Config.PassPattern[i]
+	}
 	for i, pick := range picks {
 		cardName := pick.CardName
 		pack.Picked[chosenIndices[i]] = true
+		if pack.PickedAt != nil {
+			pack.PickedAt[chosenIndices[i]] = takenBefore + i + 1
+		}
 		if pick.Zone == PickZoneMainboard {
 			d.Seats[seat].Picks.Mainboard = append(d.Seats[seat].Picks.Mainboard, cardName)
 		} else {
@@ -600,6 +775,11 @@ func (d *Draft) PickBatch(seat int, seq uint64, packID string, picks []PickSelec
 	}
 
 	d.seatPicked[seat] = true
+	d.passPicks[seat] = &PassPick{
+		PackID:  pack.ID,
+		Indices: chosenIndices,
+		Picks:   append([]PickSelection(nil), picks...),
+	}
 	d.lastSeqBySeat[seat] = seq
 	d.globalSeq++
 
@@ -615,6 +795,7 @@ func (d *Draft) PickBatch(seat int, seq uint64, packID string, picks []PickSelec
 	if allPicked {
 		for i := range d.seatPicked {
 			d.seatPicked[i] = false
+			d.passPicks[i] = nil
 		}
 
 		d.Progress.PickNumber++
@@ -642,6 +823,93 @@ func (d *Draft) PickBatch(seat int, seq uint64, packID string, picks []PickSelec
 	return PickResult{State: ack, Events: events, Duplicate: false}, nil
 }
 
+// RetractPick undoes a seat's pick for the current pass. It is only allowed while
+// the round has not advanced: the cards go back into the pack and the seat may pick again.
+func (d *Draft) RetractPick(seat int, seq uint64, packID string) (PickResult, error) {
+	if err := d.validateSeatIndex(seat); err != nil {
+		return PickResult{}, err
+	}
+	if d.State() == "done" {
+		return PickResult{}, errDraftComplete
+	}
+	if duplicateResult, duplicate, err := d.validateMutationSeq(seat, seq); err != nil {
+		return PickResult{}, err
+	} else if duplicate {
+		return duplicateResult, nil
+	}
+
+	passPick := d.passPicks[seat]
+	if !d.seatPicked[seat] || passPick == nil {
+		return PickResult{}, errors.New("no pick to retract this round")
+	}
+	if passPick.PackID != packID {
+		return PickResult{}, errPackMismatch
+	}
+	pack, err := d.currentPackForSeat(seat)
+	if err != nil {
+		return PickResult{}, err
+	}
+	if pack.ID != passPick.PackID {
+		return PickResult{}, errPackMismatch
+	}
+
+	seatState := &d.Seats[seat]
+	nextMainboard := append([]string(nil), seatState.Picks.Mainboard...)
+	nextSideboard := append([]string(nil), seatState.Picks.Sideboard...)
+	for _, pick := range passPick.Picks {
+		// The card may have been moved between zones after the pick; prefer its original zone.
+		if removeLastCard(&nextMainboard, &nextSideboard, pick) {
+			continue
+		}
+		return PickResult{}, fmt.Errorf("picked card %q no longer in pool", pick.CardName)
+	}
+
+	for _, idx := range passPick.Indices {
+		pack.Picked[idx] = false
+		if pack.PickedAt != nil {
+			pack.PickedAt[idx] = 0
+		}
+	}
+	seatState.Picks.Mainboard = nextMainboard
+	seatState.Picks.Sideboard = nextSideboard
+
+	cards := make([]string, 0, len(passPick.Picks))
+	for _, pick := range passPick.Picks {
+		cards = append(cards, pick.CardName)
+	}
+	d.seatPicked[seat] = false
+	d.passPicks[seat] = nil
+	d.lastSeqBySeat[seat] = seq
+	d.globalSeq++
+
+	state, err := d.PlayerState(seat)
+	if err != nil {
+		return PickResult{}, err
+	}
+	return PickResult{
+		State:     state,
+		Events:    []Event{PickRetracted{Seat: seat, PackID: pack.ID, Cards: cards}},
+		Duplicate: false,
+	}, nil
+}
+
+func removeLastCard(mainboard, sideboard *[]string, pick PickSelection) bool {
+	zones := []*[]string{mainboard, sideboard}
+	if pick.Zone == PickZoneSideboard {
+		zones = []*[]string{sideboard, mainboard}
+	}
+	for _, zone := range zones {
+		for i := len(*zone) - 1; i >= 0; i-- {
+			if (*zone)[i] != pick.CardName {
+				continue
+			}
+			*zone = append((*zone)[:i], (*zone)[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
 // randomPickBatchForSeat performs one full pass worth of random picks for a seat.
 // Picks are always assigned to the provided zone.
 func (d *Draft) randomPickBatchForSeat(seat int, zone string) (PickResult, error) {
@@ -649,13 +917,16 @@ func (d *Draft) randomPickBatchForSeat(seat int, zone string) (PickResult, error
 		return PickResult{}, err
 	}
 	if d.State() == "done" {
-		return PickResult{}, errors.New("draft already complete")
+		return PickResult{}, errDraftComplete
+	}
+	if d.waiting {
+		return PickResult{}, errDraftNotStarted
 	}
 	if zone != PickZoneMainboard && zone != PickZoneSideboard {
 		return PickResult{}, errors.New("invalid pick zone")
 	}
 	if d.seatPicked[seat] {
-		return PickResult{}, errors.New("seat already picked this round")
+		return PickResult{}, errSeatAlreadyPicked
 	}
 
 	pack, err := d.currentPackForSeat(seat)
@@ -677,9 +948,10 @@ func (d *Draft) randomPickBatchForSeat(seat int, zone string) (PickResult, error
 		return PickResult{}, errors.New("not enough cards in pack for this pass")
 	}
 
+	rng := d.botPickRand(seat)
 	picks := make([]PickSelection, 0, expectedPicks)
 	for i := 0; i < expectedPicks; i++ {
-		roll := randomIndex(len(available))
+		roll := rng.IntN(len(available))
 		cardIdx := available[roll]
 		picks = append(picks, PickSelection{
 			CardName: pack.Cards[cardIdx],
@@ -471,3 +471,153 @@ func TestDraftPassDirectionAlternatesByPack(t *testing.T) {
 		assert.Equal(t, fmt.Sprintf("p1_s%d", expectedOrigin), st.Active.PackID, "pack routing mismatch for seat %d in pack 1", seat)
 	}
 }
+
+func TestDraftRetractPickRestoresPackAndAllowsRepick(t *testing.T) {
+	d := makeDraft(t, 1, 3, 2)
+
+	st, err := d.PlayerState(0)
+	require.NoError(t, err, "PlayerState error")
+	first := st.Active.Cards[0]
+	_, err = d.Pick(0, 1, st.Active.PackID, first, PickZoneSideboard)
+	require.NoError(t, err, "initial pick should succeed")
+
+	picked, err := d.PlayerState(0)
+	require.NoError(t, err, "PlayerState after pick")
+	assert.True(t, picked.CanRetract, "seat should be able to retract before the round advances")
+	assert.Len(t, picked.Active.Cards, 2, "picked card should leave the pack")
+
+	result, err := d.RetractPick(0, 2, st.Active.PackID)
+	require.NoError(t, err, "retract should succeed")
+	assert.False(t, result.Duplicate, "first retract should not be duplicate")
+	require.Len(t, result.Events, 1, "retract should emit one event")
+	assert.Equal(t, PickRetracted{Seat: 0, PackID: st.Active.PackID, Cards: []string{first}}, result.Events[0], "retract event mismatch")
+	assert.True(t, result.State.CanPick, "seat should be able to pick again after retract")
+	assert.False(t, result.State.CanRetract, "seat should not retract twice")
+	assert.Len(t, result.State.Active.Cards, 3, "retracted card should return to the pack")
+	assert.Empty(t, d.Seats[0].Picks.Sideboard, "retracted card should leave the pool")
+	assert.Equal(t, uint64(2), d.globalSeq, "retract should advance global seq")
+
+	duplicate, err := d.RetractPick(0, 2, st.Active.PackID)
+	require.NoError(t, err, "duplicate retract should be idempotent")
+	assert.True(t, duplicate.Duplicate, "duplicate retract should be flagged")
+
+	_, err = d.RetractPick(0, 3, st.Active.PackID)
+	require.Error(t, err, "retract without a pick should fail")
+
+	second := st.Active.Cards[1]
+	_, err = d.Pick(0, 3, st.Active.PackID, second, PickZoneMainboard)
+	require.NoError(t, err, "repick after retract should succeed")
+	assert.Equal(t, []string{second}, d.Seats[0].Picks.Mainboard, "repick mainboard mismatch")
+}
+
+func TestDraftRetractPickFollowsMovedCard(t *testing.T) {
+	d := makeDraft(t, 1, 2, 2)
+
+	st, err := d.PlayerState(0)
+	require.NoError(t, err, "PlayerState error")
+	card := st.Active.Cards[0]
+	_, err = d.Pick(0, 1, st.Active.PackID, card, PickZoneMainboard)
+	require.NoError(t, err, "pick should succeed")
+	_, err = d.MovePick(0, 2, card, PickZoneMainboard, PickZoneSideboard)
+	require.NoError(t, err, "move should succeed")
+
+	_, err = d.RetractPick(0, 3, st.Active.PackID)
+	require.NoError(t, err, "retract should find the moved card")
+	assert.Empty(t, d.Seats[0].Picks.Mainboard, "mainboard should be empty after retract")
+	assert.Empty(t, d.Seats[0].Picks.Sideboard, "sideboard should be empty after retract")
+}
+
+func TestDraftRetractPickRejectedAfterRoundAdvances(t *testing.T) {
+	d := makeDraft(t, 1, 2, 2)
+
+	s0, err := d.PlayerState(0)
+	require.NoError(t, err, "seat0 PlayerState")
+	s1, err := d.PlayerState(1)
+	require.NoError(t, err, "seat1 PlayerState")
+	_, err = d.Pick(0, 1, s0.Active.PackID, s0.Active.Cards[0], PickZoneMainboard)
+	require.NoError(t, err, "seat0 pick")
+	_, err = d.Pick(1, 1, s1.Active.PackID, s1.Active.Cards[0], PickZoneMainboard)
+	require.NoError(t, err, "seat1 pick")
+
+	_, err = d.RetractPick(0, 2, s0.Active.PackID)
+	require.Error(t, err, "retract after round advance should fail")
+	assert.Equal(t, uint64(1), d.lastSeqBySeat[0], "failed retract should not advance seq")
+	assert.Len(t, d.Seats[0].Picks.Mainboard, 1, "failed retract should keep the pick")
+}
+
+func TestDraftSeedReproducesPacksAndBotPicks(t *testing.T) {
+	cfg := DraftConfig{PackCount: 2, PackSize: 4, SeatCount: 3, Seed: 42}
+	first := makeDraftWithConfig(t, cfg)
+	second := makeDraftWithConfig(t, cfg)
+	assert.Equal(t, snapshotFromDraft(first).Packs, snapshotFromDraft(second).Packs, "same seed should collate identical packs")
+
+	other := makeDraftWithConfig(t, DraftConfig{PackCount: 2, PackSize: 4, SeatCount: 3, Seed: 43})
+	assert.NotEqual(t, snapshotFromDraft(first).Packs, snapshotFromDraft(other).Packs, "different seeds should collate different packs")
+
+	for first.State() != "done" {
+		for seat := 0; seat < cfg.SeatCount; seat++ {
+			_, err := first.randomPickBatchForSeat(seat, PickZoneMainboard)
+			require.NoErrorf(t, err, "first draft bot pick seat %d", seat)
+			_, err = second.randomPickBatchForSeat(seat, PickZoneMainboard)
+			require.NoErrorf(t, err, "second draft bot pick seat %d", seat)
+		}
+	}
+	assert.Equal(t, first.Seats, second.Seats, "same seed should replay identical bot picks")
+}
+
+func TestDraftTeamModeRequiresEvenSeats(t *testing.T) {
+	_, err := NewDraft(DraftConfig{PackCount: 1, PackSize: 1, SeatCount: 3, TeamDraft: true}, make([]string, 3))
+	require.Error(t, err, "odd seat count should be rejected for team drafts")
+	_, err = NewDraft(DraftConfig{PackCount: 1, PackSize: 1, SeatCount: 2, TeamDraft: true}, make([]string, 2))
+	require.Error(t, err, "two seats should be rejected for team drafts")
+}
+
+func TestDraftTeamModeSharesTeammatePicks(t *testing.T) {
+	d := makeDraftWithConfig(t, DraftConfig{PackCount: 1, PackSize: 2, SeatCount: 4, TeamDraft: true})
+
+	st, err := d.PlayerState(0)
+	require.NoError(t, err, "seat0 PlayerState")
+	assert.Equal(t, 1, st.Team, "seat 0 team mismatch")
+	require.Len(t, st.Teammates, 1, "seat 0 should have one teammate")
+	assert.Equal(t, 2, st.Teammates[0].SeatID, "teams should alternate around the table")
+
+	card := st.Active.Cards[0]
+	_, err = d.Pick(0, 1, st.Active.PackID, card, PickZoneMainboard)
+	require.NoError(t, err, "seat0 pick")
+
+	teammate, err := d.PlayerState(2)
+	require.NoError(t, err, "seat2 PlayerState")
+	require.Len(t, teammate.Teammates, 1, "seat 2 should have one teammate")
+	assert.Equal(t, []string{card}, teammate.Teammates[0].Picks.Mainboard, "teammate should see the pick live")
+
+	opponent, err := d.PlayerState(1)
+	require.NoError(t, err, "seat1 PlayerState")
+	assert.Equal(t, 2, opponent.Team, "seat 1 team mismatch")
+	for _, view := range opponent.Teammates {
+		assert.NotEqual(t, 0, view.SeatID, "opponents should not see seat 0 picks")
+	}
+}
+
+func TestDraftTeamModePairsOpposingTeams(t *testing.T) {
+	d := makeDraftWithConfig(t, DraftConfig{PackCount: 1, PackSize: 1, SeatCount: 6, TeamDraft: true})
+	assert.Nil(t, d.Pairings(), "pairings should wait for the draft to finish")
+
+	for seat := 0; seat < d.Config.SeatCount; seat++ {
+		_, err := d.randomPickBatchForSeat(seat, PickZoneMainboard)
+		require.NoErrorf(t, err, "bot pick seat %d", seat)
+	}
+	require.Equal(t, "done", d.State(), "draft should be done")
+
+	pairings := d.Pairings()
+	require.Len(t, pairings, 9, "3v3 should produce three rounds of three matches")
+	seen := map[[2]int]bool{}
+	for _, pairing := range pairings {
+		assert.NotEqual(t, d.seatTeam(pairing.Seats[0]), d.seatTeam(pairing.Seats[1]), "pairing should cross teams")
+		assert.False(t, seen[pairing.Seats], "each cross-team pairing should appear once")
+		seen[pairing.Seats] = true
+	}
+
+	st, err := d.PlayerState(0)
+	require.NoError(t, err, "seat0 done PlayerState")
+	assert.Equal(t, pairings, st.Pairings, "done state should carry pairings")
+}
@@ -0,0 +1,565 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A draft event groups several draft rooms (pods) drafting the same cube on one
+// night. Players are registered to a pod seat, matches are reported per round,
+// and standings are combined across every pod.
+
+type draftEventRecord struct {
+	EventID       string             `json:"event_id"`
+	Name          string             `json:"name"`
+	DeckSlug      string             `json:"deck_slug"`
+	OwnerDeviceID string             `json:"owner_device_id"`
+	CreatedAt     time.Time          `json:"created_at"`
+	Pods          []string           `json:"pods"` // room ids in creation order
+	Players       []draftEventPlayer `json:"players"`
+	Matches       []draftEventMatch  `json:"matches"`
+}
+
+type draftEventPlayer struct {
+	PlayerID string `json:"player_id"`
+	Name     string `json:"name"`
+	RoomID   string `json:"room_id"`
+	Seat     int    `json:"seat"`
+}
+
+type draftEventMatch struct {
+	Round   int       `json:"round"`
+	Players [2]string `json:"players"`
+	Wins    [2]int    `json:"wins"`
+	Draws   int       `json:"draws,omitempty"`
+}
+
+type draftEventPod struct {
+	RoomID    string   `json:"room_id"`
+	State     string   `json:"state"` // drafting, done, or missing once the room is deleted
+	PackNo    int      `json:"pack_no"`
+	PickNo    int      `json:"pick_no"`
+	PlayerIDs []string `json:"player_ids"`
+}
+
+type draftEventStanding struct {
+	Rank        int     `json:"rank"`
+	PlayerID    string  `json:"player_id"`
+	Name        string  `json:"name"`
+	RoomID      string  `json:"room_id"`
+	Points      int     `json:"points"`
+	MatchWins   int     `json:"match_wins"`
+	MatchLosses int     `json:"match_losses"`
+	MatchDraws  int     `json:"match_draws"`
+	GameWins    int     `json:"game_wins"`
+	GameLosses  int     `json:"game_losses"`
+	OMWPct      float64 `json:"omw_pct"`
+	GWPct       float64 `json:"gw_pct"`
+}
+
+type draftEventPodWinner struct {
+	RoomID   string `json:"room_id"`
+	PlayerID string `json:"player_id"`
+	Name     string `json:"name"`
+}
+
+type draftEventSummary struct {
+	Champion      draftEventStanding    `json:"champion"`
+	PodWinners    []draftEventPodWinner `json:"pod_winners"`
+	MatchesPlayed int                   `json:"matches_played"`
+}
+
+type draftEventPage struct {
+	EventID        string               `json:"event_id"`
+	Name           string               `json:"name"`
+	DeckSlug       string               `json:"deck_slug"`
+	CreatedAt      time.Time            `json:"created_at"`
+	OwnedByRequest bool                 `json:"owned_by_requester"`
+	Complete       bool                 `json:"complete"`
+	Pods           []draftEventPod      `json:"pods"`
+	Players        []draftEventPlayer   `json:"players"`
+	Matches        []draftEventMatch    `json:"matches"`
+	Standings      []draftEventStanding `json:"standings"`
+	// Summary is only set once every pod has finished drafting and a match was reported.
+	Summary *draftEventSummary `json:"summary,omitempty"`
+}
+
+type createDraftEventRequest struct {
+	Name     string   `json:"name"`
+	DeckSlug string   `json:"deck_slug"`
+	RoomIDs  []string `json:"room_ids"`
+}
+
+type addDraftEventPodRequest struct {
+	EventID string `json:"event_id"`
+	RoomID  string `json:"room_id"`
+}
+
+type addDraftEventPlayerRequest struct {
+	EventID string `json:"event_id"`
+	Name    string `json:"name"`
+	RoomID  string `json:"room_id"`
+	Seat    int    `json:"seat"`
+}
+
+type reportDraftEventMatchRequest struct {
+	EventID   string    `json:"event_id"`
+	Round     int       `json:"round"`
+	PlayerIDs [2]string `json:"player_ids"`
+	Wins      [2]int    `json:"wins"`
+	Draws     int       `json:"draws,omitempty"`
+}
+
+var errDraftEventNotFound = errors.New("event not found")
+
+const (
+	eventMatchWinPoints  = 3
+	eventMatchDrawPoints = 1
+	// eventMinOMWPct is the floor applied to each opponent's match-win percentage.
+	eventMinOMWPct = 1.0 / 3.0
+)
+
+func (h *draftHub) restoreEvents(records []draftEventRecord) {
+	h.eventsMu.Lock()
+	defer h.eventsMu.Unlock()
+	for i := range records {
+		record := records[i]
+		h.events[record.EventID] = &record
+	}
+}
+
+// updateEvent applies mutate to a copy of the event, persists it, and only then
+// makes it visible. Only the event owner may mutate.
+func (h *draftHub) updateEvent(ctx context.Context, eventID, requesterDeviceID string, mutate func(*draftEventRecord) error) (draftEventRecord, error) {
+	h.eventsMu.Lock()
+	defer h.eventsMu.Unlock()
+
+	current := h.events[eventID]
+	if current == nil {
+		return draftEventRecord{}, errDraftEventNotFound
+	}
+	if current.OwnerDeviceID != requesterDeviceID {
+		return draftEventRecord{}, errDraftRoomForbidden
+	}
+	next := cloneDraftEvent(*current)
+	if err := mutate(&next); err != nil {
+		return draftEventRecord{}, err
+	}
+	if err := h.saveEvent(ctx, next); err != nil {
+		return draftEventRecord{}, err
+	}
+	h.events[eventID] = &next
+	return next, nil
+}
+
+func (h *draftHub) saveEvent(ctx context.Context, event draftEventRecord) error {
+	h.mu.RLock()
+	store := h.roomStore
+	h.mu.RUnlock()
+	if store == nil {
+		return nil
+	}
+	return store.SaveEvent(ctx, event)
+}
+
+func cloneDraftEvent(event draftEventRecord) draftEventRecord {
+	event.Pods = append([]string(nil), event.Pods...)
+	event.Players = append([]draftEventPlayer(nil), event.Players...)
+	event.Matches = append([]draftEventMatch(nil), event.Matches...)
+	return event
+}
+
+func (h *draftHub) createEvent(ctx context.Context, requesterDeviceID string, req createDraftEventRequest) (draftEventRecord, error) {
+	name := normalizeHostName(req.Name)
+	if name == "" {
+		return draftEventRecord{}, errors.New("event name required")
+	}
+	event := draftEventRecord{
+		Name:          name,
+		DeckSlug:      normalizeSlug(req.DeckSlug),
+		OwnerDeviceID: requesterDeviceID,
+		CreatedAt:     time.Now(),
+		Pods:          []string{},
+		Players:       []draftEventPlayer{},
+		Matches:       []draftEventMatch{},
+	}
+	for _, roomID := range req.RoomIDs {
+		if err := h.addEventPod(&event, roomID); err != nil {
+			return draftEventRecord{}, err
+		}
+	}
+	if event.DeckSlug == "" {
+		return draftEventRecord{}, errors.New("deck_slug required")
+	}
+
+	h.eventsMu.Lock()
+	defer h.eventsMu.Unlock()
+	event.EventID = h.nextEventIDLocked()
+	if err := h.saveEvent(ctx, event); err != nil {
+		return draftEventRecord{}, err
+	}
+	h.events[event.EventID] = &event
+	return event, nil
+}
+
+func (h *draftHub) nextEventIDLocked() string {
+	for attempt := 0; attempt < 32; attempt++ {
+		candidate := "event-" + randomRoomID()
+		if _, exists := h.events[candidate]; !exists {
+			return candidate
+		}
+	}
+	return fmt.Sprintf("event-%d", time.Now().UnixNano())
+}
+
+// addEventPod attaches a room as a pod. Every pod must draft the event's cube.
+func (h *draftHub) addEventPod(event *draftEventRecord, roomID string) error {
+	room := h.room(roomID)
+	if room == nil {
+		return fmt.Errorf("room %q not found", roomID)
+	}
+	for _, existing := range event.Pods {
+		if existing == roomID {
+			return fmt.Errorf("room %q already in event", roomID)
+		}
+	}
+	if event.DeckSlug == "" {
+		event.DeckSlug = room.deckSlug
+	}
+	if room.deckSlug != event.DeckSlug {
+		return fmt.Errorf("room %q drafts %q, event uses %q", roomID, room.deckSlug, event.DeckSlug)
+	}
+	event.Pods = append(event.Pods, roomID)
+	return nil
+}
+
+func addEventPlayer(event *draftEventRecord, req addDraftEventPlayerRequest, seatCount int) error {
+	name := normalizeHostName(req.Name)
+	playerID := normalizeSlug(strings.ReplaceAll(strings.ToLower(name), " ", "-"))
+	if playerID == "" {
+		return errors.New("player name must contain only letters, digits, spaces or dashes")
+	}
+	inEvent := false
+	for _, roomID := range event.Pods {
+		inEvent = inEvent || roomID == req.RoomID
+	}
+	if !inEvent {
+		return fmt.Errorf("room %q is not a pod of this event", req.RoomID)
+	}
+	if req.Seat < 0 || req.Seat >= seatCount {
+		return errInvalidSeat
+	}
+	for _, player := range event.Players {
+		if player.PlayerID == playerID {
+			return fmt.Errorf("player %q already registered", name)
+		}
+		if player.RoomID == req.RoomID && player.Seat == req.Seat {
+			return fmt.Errorf("seat %d already taken by %q", req.Seat, player.Name)
+		}
+	}
+	event.Players = append(event.Players, draftEventPlayer{PlayerID: playerID, Name: name, RoomID: req.RoomID, Seat: req.Seat})
+	return nil
+}
+
+func reportEventMatch(event *draftEventRecord, req reportDraftEventMatchRequest) error {
+	if req.Round <= 0 {
+		return errors.New("round must be > 0")
+	}
+	if req.PlayerIDs[0] == req.PlayerIDs[1] {
+		return errors.New("a match needs two different players")
+	}
+	if req.Wins[0] < 0 || req.Wins[1] < 0 || req.Draws < 0 || req.Wins[0]+req.Wins[1]+req.Draws == 0 {
+		return errors.New("invalid match result")
+	}
+	for _, playerID := range req.PlayerIDs {
+		if eventPlayerIndex(*event, playerID) < 0 {
+			return fmt.Errorf("player %q not registered", playerID)
+		}
+	}
+	for _, match := range event.Matches {
+		if match.Round != req.Round {
+			continue
+		}
+		for _, playerID := range match.Players {
+			if playerID == req.PlayerIDs[0] || playerID == req.PlayerIDs[1] {
+				return fmt.Errorf("player %q already has a result in round %d", playerID, req.Round)
+			}
+		}
+	}
+	event.Matches = append(event.Matches, draftEventMatch{Round: req.Round, Players: req.PlayerIDs, Wins: req.Wins, Draws: req.Draws})
+	return nil
+}
+
+func eventPlayerIndex(event draftEventRecord, playerID string) int {
+	for i, player := range event.Players {
+		if player.PlayerID == playerID {
+			return i
+		}
+	}
+	return -1
+}
+
+// eventStandings ranks players across all pods by match points, then opponents'
+// match-win percentage, then game-win percentage.
+func eventStandings(event draftEventRecord) []draftEventStanding {
+	standings := make([]draftEventStanding, len(event.Players))
+	opponents := make([][]int, len(event.Players))
+	for i, player := range event.Players {
+		standings[i] = draftEventStanding{PlayerID: player.PlayerID, Name: player.Name, RoomID: player.RoomID}
+	}
+	for _, match := range event.Matches {
+		a := eventPlayerIndex(event, match.Players[0])
+		b := eventPlayerIndex(event, match.Players[1])
+		if a < 0 || b < 0 {
+			continue
+		}
+		opponents[a] = append(opponents[a], b)
+		opponents[b] = append(opponents[b], a)
+		standings[a].GameWins += match.Wins[0]
+		standings[a].GameLosses += match.Wins[1]
+		standings[b].GameWins += match.Wins[1]
+		standings[b].GameLosses += match.Wins[0]
+		switch {
+		case match.Wins[0] > match.Wins[1]:
+			standings[a].MatchWins++
+			standings[b].MatchLosses++
+		case match.Wins[1] > match.Wins[0]:
+			standings[b].MatchWins++
+			standings[a].MatchLosses++
+		default:
+			standings[a].MatchDraws++
+			standings[b].MatchDraws++
+		}
+	}
+
+	matchWinPct := make([]float64, len(standings))
+	for i := range standings {
+		s := &standings[i]
+		s.Points = s.MatchWins*eventMatchWinPoints + s.MatchDraws*eventMatchDrawPoints
+		if played := s.MatchWins + s.MatchLosses + s.MatchDraws; played > 0 {
+			matchWinPct[i] = max(float64(s.Points)/float64(played*eventMatchWinPoints), eventMinOMWPct)
+		}
+		if games := s.GameWins + s.GameLosses; games > 0 {
+			s.GWPct = float64(s.GameWins) / float64(games)
+		}
+	}
+	for i := range standings {
+		if len(opponents[i]) == 0 {
+			continue
+		}
+		total := 0.0
+		for _, opp := range opponents[i] {
+			total += matchWinPct[opp]
+		}
+		standings[i].OMWPct = total / float64(len(opponents[i]))
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		if a.OMWPct != b.OMWPct {
+			return a.OMWPct > b.OMWPct
+		}
+		if a.GWPct != b.GWPct {
+			return a.GWPct > b.GWPct
+		}
+		return a.Name < b.Name
+	})
+	for i := range standings {
+		standings[i].Rank = i + 1
+	}
+	return standings
+}
+
+func (h *draftHub) eventPage(event draftEventRecord, requesterDeviceID string) draftEventPage {
+	page := draftEventPage{
+		EventID:        event.EventID,
+		Name:           event.Name,
+		DeckSlug:       event.DeckSlug,
+		CreatedAt:      event.CreatedAt,
+		OwnedByRequest: requesterDeviceID != "" && requesterDeviceID == event.OwnerDeviceID,
+		Pods:           make([]draftEventPod, 0, len(event.Pods)),
+		Players:        event.Players,
+		Matches:        event.Matches,
+		Standings:      eventStandings(event),
+	}
+
+	page.Complete = len(event.Pods) > 0
+	for _, roomID := range event.Pods {
+		pod := draftEventPod{RoomID: roomID, State: "missing", PlayerIDs: []string{}}
+		if room := h.room(roomID); room != nil {
+			progress := room.progress()
+			pod.State = progress.State
+			pod.PackNo = progress.PackNo
+			pod.PickNo = progress.PickNo
+		}
+		for _, player := range event.Players {
+			if player.RoomID == roomID {
+				pod.PlayerIDs = append(pod.PlayerIDs, player.PlayerID)
+			}
+		}
+		page.Complete = page.Complete && pod.State != "drafting"
+		page.Pods = append(page.Pods, pod)
+	}
+
+	if page.Complete && len(event.Matches) > 0 && len(page.Standings) > 0 {
+		summary := &draftEventSummary{
+			Champion:      page.Standings[0],
+			PodWinners:    []draftEventPodWinner{},
+			MatchesPlayed: len(event.Matches),
+		}
+		for _, roomID := range event.Pods {
+			for _, standing := range page.Standings {
+				if standing.RoomID == roomID {
+					summary.PodWinners = append(summary.PodWinners, draftEventPodWinner{RoomID: roomID, PlayerID: standing.PlayerID, Name: standing.Name})
+					break
+				}
+			}
+		}
+		page.Summary = summary
+	}
+	return page
+}
+
+func (h *draftHub) getEvent(eventID string) (draftEventRecord, bool) {
+	h.eventsMu.Lock()
+	defer h.eventsMu.Unlock()
+	event := h.events[eventID]
+	if event == nil {
+		return draftEventRecord{}, false
+	}
+	return cloneDraftEvent(*event), true
+}
+
+func writeDraftEventError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errDraftEventNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, errDraftRoomForbidden):
+		http.Error(w, "only the organiser may change this event", http.StatusForbidden)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
+
+func (h *draftHub) writeEventPage(w http.ResponseWriter, event draftEventRecord, requesterDeviceID string) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(h.eventPage(event, requesterDeviceID))
+}
+
+// handleEvents serves GET /api/draft/events?event_id= (the event page) and
+// POST /api/draft/events (create an event from existing rooms).
+func (h *draftHub) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		eventID := r.URL.Query().Get("event_id")
+		if eventID == "" {
+			http.Error(w, "event_id query param required", http.StatusBadRequest)
+			return
+		}
+		event, ok := h.getEvent(eventID)
+		if !ok {
+			http.Error(w, "event not found", http.StatusNotFound)
+			return
+		}
+		requesterDeviceID, _ := requesterDeviceIDFromRequest(r)
+		h.writeEventPage(w, event, requesterDeviceID)
+		return
+	}
+
+	requesterDeviceID, err := requesterDeviceIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+	var req createDraftEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json body", http.StatusBadRequest)
+		return
+	}
+	event, err := h.createEvent(r.Context(), requesterDeviceID, req)
+	if err != nil {
+		writeDraftEventError(w, err)
+		return
+	}
+	h.writeEventPage(w, event, requesterDeviceID)
+}
+
+// handleEventMutation decodes a POST body into req and applies mutate as the event
+// owner. It reports whether the event was updated.
+func handleEventMutation[T any](h *draftHub, w http.ResponseWriter, r *http.Request, eventID func(T) string, mutate func(T, *draftEventRecord) error) (draftEventRecord, bool) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return draftEventRecord{}, false
+	}
+	requesterDeviceID, err := requesterDeviceIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return draftEventRecord{}, false
+	}
+	defer r.Body.Close()
+	var req T
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json body", http.StatusBadRequest)
+		return draftEventRecord{}, false
+	}
+	event, err := h.updateEvent(r.Context(), eventID(req), requesterDeviceID, func(event *draftEventRecord) error {
+		return mutate(req, event)
+	})
+	if err != nil {
+		writeDraftEventError(w, err)
+		return draftEventRecord{}, false
+	}
+	h.writeEventPage(w, event, requesterDeviceID)
+	return event, true
+}
+
+// handleEventPods serves POST /api/draft/events/pods.
+func (h *draftHub) handleEventPods(w http.ResponseWriter, r *http.Request) {
+	_, _ = handleEventMutation(h, w, r,
+		func(req addDraftEventPodRequest) string { return req.EventID },
+		func(req addDraftEventPodRequest, event *draftEventRecord) error {
+			return h.addEventPod(event, req.RoomID)
+		})
+}
+
+// handleEventPlayers serves POST /api/draft/events/players.
+func (h *draftHub) handleEventPlayers(w http.ResponseWriter, r *http.Request) {
+	_, _ = handleEventMutation(h, w, r,
+		func(req addDraftEventPlayerRequest) string { return req.EventID },
+		func(req addDraftEventPlayerRequest, event *draftEventRecord) error {
+			seatCount := 0
+			if room := h.room(req.RoomID); room != nil {
+				seatCount = room.draft.Config.SeatCount
+			}
+			return addEventPlayer(event, req, seatCount)
+		})
+}
+
+// handleEventMatches serves POST /api/draft/events/matches.
+func (h *draftHub) handleEventMatches(w http.ResponseWriter, r *http.Request) {
+	event, ok := handleEventMutation(h, w, r,
+		func(req reportDraftEventMatchRequest) string { return req.EventID },
+		func(req reportDraftEventMatchRequest, event *draftEventRecord) error {
+			return reportEventMatch(event, req)
+		})
+	if ok {
+		h.announceMatchResult(event)
+		h.refreshEventRatings(r.Context(), event)
+	}
+}
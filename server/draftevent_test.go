@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postDraftEventJSON(t *testing.T, handler http.HandlerFunc, path, deviceID string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	raw, err := json.Marshal(body)
+	require.NoError(t, err, "marshal body")
+	rr := httptest.NewRecorder()
+	handler(rr, httptest.NewRequest(http.MethodPost, withDeviceID(path, deviceID), bytes.NewReader(raw)))
+	return rr
+}
+
+func decodeDraftEventPage(t *testing.T, rr *httptest.ResponseRecorder) draftEventPage {
+	t.Helper()
+	require.Equal(t, http.StatusOK, rr.Code, "status: %s", rr.Body.String())
+	var page draftEventPage
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &page), "decode event page")
+	return page
+}
+
+func TestEventStandingsCombineAcrossPods(t *testing.T) {
+	event := draftEventRecord{
+		Players: []draftEventPlayer{
+			{PlayerID: "ada", Name: "Ada", RoomID: "pod-a"},
+			{PlayerID: "bo", Name: "Bo", RoomID: "pod-a"},
+			{PlayerID: "cy", Name: "Cy", RoomID: "pod-b"},
+			{PlayerID: "di", Name: "Di", RoomID: "pod-b"},
+		},
+		Matches: []draftEventMatch{
+			{Round: 1, Players: [2]string{"ada", "bo"}, Wins: [2]int{2, 0}},
+			{Round: 1, Players: [2]string{"cy", "di"}, Wins: [2]int{2, 1}},
+			{Round: 2, Players: [2]string{"ada", "cy"}, Wins: [2]int{1, 1}, Draws: 1},
+			{Round: 2, Players: [2]string{"bo", "di"}, Wins: [2]int{0, 2}},
+		},
+	}
+
+	standings := eventStandings(event)
+	require.Len(t, standings, 4, "standings length")
+	assert.Equal(t, "cy", standings[0].PlayerID, "cy should lead on opponents' match-win tiebreak")
+	assert.Equal(t, 4, standings[0].Points, "win + draw points")
+	assert.Equal(t, "ada", standings[1].PlayerID, "ada second")
+	assert.Equal(t, 4, standings[1].Points, "ada ties on points")
+	assert.Equal(t, "di", standings[2].PlayerID, "di third")
+	assert.Equal(t, "bo", standings[3].PlayerID, "bo last")
+	assert.InDelta(t, (1.0/3.0+4.0/6.0)/2, standings[1].OMWPct, 1e-9, "opponent match-win pct uses the 33% floor")
+	assert.Equal(t, 1, standings[1].MatchDraws, "draw recorded")
+}
+
+func TestDraftEventLifecyclePersists(t *testing.T) {
+	store, err := openDraftRoomStore(filepath.Join(t.TempDir(), "db.sqlite"))
+	require.NoError(t, err, "openDraftRoomStore")
+	defer func() {
+		_ = store.Close()
+	}()
+
+	hub := newDraftHub()
+	hub.setRoomStore(store)
+	podA := addTestRoom(t, hub, "pod-a", DraftConfig{PackCount: 1, PackSize: 1, SeatCount: 2})
+	podB := addTestRoom(t, hub, "pod-b", DraftConfig{PackCount: 1, PackSize: 1, SeatCount: 2})
+	other := addTestRoom(t, hub, "other", DraftConfig{PackCount: 1, PackSize: 1, SeatCount: 2})
+	podA.deckSlug, podB.deckSlug, other.deckSlug = "cube", "cube", "legacy"
+
+	mismatch := postDraftEventJSON(t, hub.handleEvents, "/api/draft/events", "host", createDraftEventRequest{Name: "Friday", RoomIDs: []string{"pod-a", "other"}})
+	assert.Equal(t, http.StatusBadRequest, mismatch.Code, "pods must share a cube")
+
+	page := decodeDraftEventPage(t, postDraftEventJSON(t, hub.handleEvents, "/api/draft/events", "host", createDraftEventRequest{Name: "Friday", RoomIDs: []string{"pod-a"}}))
+	assert.Equal(t, "cube", page.DeckSlug, "deck slug taken from the first pod")
+	eventID := page.EventID
+
+	forbidden := postDraftEventJSON(t, hub.handleEventPods, "/api/draft/events/pods", "guest", addDraftEventPodRequest{EventID: eventID, RoomID: "pod-b"})
+	assert.Equal(t, http.StatusForbidden, forbidden.Code, "only the organiser may add pods")
+	decodeDraftEventPage(t, postDraftEventJSON(t, hub.handleEventPods, "/api/draft/events/pods", "host", addDraftEventPodRequest{EventID: eventID, RoomID: "pod-b"}))
+
+	for _, player := range []addDraftEventPlayerRequest{
+		{Name: "Ada", RoomID: "pod-a", Seat: 0},
+		{Name: "Bo", RoomID: "pod-a", Seat: 1},
+		{Name: "Cy", RoomID: "pod-b", Seat: 0},
+	} {
+		player.EventID = eventID
+		decodeDraftEventPage(t, postDraftEventJSON(t, hub.handleEventPlayers, "/api/draft/events/players", "host", player))
+	}
+	taken := postDraftEventJSON(t, hub.handleEventPlayers, "/api/draft/events/players", "host", addDraftEventPlayerRequest{EventID: eventID, Name: "Di", RoomID: "pod-b", Seat: 0})
+	assert.Equal(t, http.StatusBadRequest, taken.Code, "seat already taken")
+
+	page = decodeDraftEventPage(t, postDraftEventJSON(t, hub.handleEventMatches, "/api/draft/events/matches", "host", reportDraftEventMatchRequest{
+		EventID: eventID, Round: 1, PlayerIDs: [2]string{"ada", "cy"}, Wins: [2]int{2, 1},
+	}))
+	again := postDraftEventJSON(t, hub.handleEventMatches, "/api/draft/events/matches", "host", reportDraftEventMatchRequest{
+		EventID: eventID, Round: 1, PlayerIDs: [2]string{"cy", "bo"}, Wins: [2]int{2, 0},
+	})
+	assert.Equal(t, http.StatusBadRequest, again.Code, "one result per player per round")
+	assert.False(t, page.Complete, "pods still drafting")
+	assert.Nil(t, page.Summary, "no summary while drafting")
+
+	for _, room := range []*draftRoom{podA, podB} {
+		for seat := 0; seat < room.draft.Config.SeatCount; seat++ {
+			_, err := room.draft.randomPickBatchForSeat(seat, PickZoneMainboard)
+			require.NoError(t, err, "bot pick")
+		}
+	}
+
+	restored := newDraftHub()
+	restored.rooms = hub.rooms
+	events, err := store.LoadEvents(context.Background())
+	require.NoError(t, err, "LoadEvents")
+	restored.restoreEvents(events)
+
+	getRes := httptest.NewRecorder()
+	restored.handleEvents(getRes, httptest.NewRequest(http.MethodGet, "/api/draft/events?event_id="+eventID, nil))
+	page = decodeDraftEventPage(t, getRes)
+	assert.True(t, page.Complete, "every pod is done")
+	require.Len(t, page.Players, 3, "players survive reload")
+	require.NotNil(t, page.Summary, "summary once complete")
+	assert.Equal(t, "ada", page.Summary.Champion.PlayerID, "champion")
+	assert.Equal(t, []draftEventPodWinner{
+		{RoomID: "pod-a", PlayerID: "ada", Name: "Ada"},
+		{RoomID: "pod-b", PlayerID: "cy", Name: "Cy"},
+	}, page.Summary.PodWinners, "pod winners")
+}
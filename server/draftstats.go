@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/lxing/battlebox/internal/buildtool"
+)
+
+// Pick-rate analytics. When a draft with a cube deck slug completes, its per-card
+// counts are written to draft_card_stats keyed by draft, so they outlive the room
+// and can be summed per cube.
+
+// cardPickCount holds one card's raw counts for a draft, or summed across drafts.
+type cardPickCount struct {
+	Name            string
+	Seen            int
+	Picked          int
+	PickPositionSum int
+	FirstPicks      int
+	Burned          int
+	Mainboard       int
+	Sideboard       int
+}
+
+// cardPickCounts tallies every dealt card of a completed draft. Packs restored
+// from snapshots without pick positions are skipped. Mainboard and sideboard use
+// the registered deck when there is one, otherwise the seat's pool zones.
+func (d *Draft) cardPickCounts() ([]cardPickCount, error) {
+	if d.State() != "done" {
+		return nil, errDraftNotComplete
+	}
+	byName := map[string]*cardPickCount{}
+	countFor := func(name string) *cardPickCount {
+		count := byName[name]
+		if count == nil {
+			count = &cardPickCount{Name: name}
+			byName[name] = count
+		}
+		return count
+	}
+
+	for _, row := range d.Packs {
+		for _, pack := range row {
+			if pack == nil || len(pack.PickedAt) != len(pack.Cards) {
+				continue
+			}
+			for i, name := range pack.Cards {
+				count := countFor(name)
+				count.Seen++
+				position := pack.PickedAt[i]
+				if position == 0 {
+					count.Burned++
+					continue
+				}
+				count.Picked++
+				count.PickPositionSum += position
+				if position == 1 {
+					count.FirstPicks++
+				}
+			}
+		}
+	}
+
+	for _, seat := range d.Seats {
+		pool := seat.Picks
+		if seat.Deck != nil {
+			pool = *seat.Deck
+		}
+		for _, name := range pool.Mainboard {
+			if count := byName[name]; count != nil && !isBasicLandCardName(name) {
+				count.Mainboard++
+			}
+		}
+		for _, name := range pool.Sideboard {
+			if count := byName[name]; count != nil && !isBasicLandCardName(name) {
+				count.Sideboard++
+			}
+		}
+	}
+
+	counts := make([]cardPickCount, 0, len(byName))
+	for _, count := range byName {
+		counts = append(counts, *count)
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].Name < counts[j].Name })
+	return counts, nil
+}
+
+// draftStatsKey identifies one draft of a room; room ids are reused after deletion.
+func (r *draftRoom) draftStatsKey() string {
+	return r.id + "-" + strconv.FormatInt(r.createdAt.UnixNano(), 36)
+}
+
+// roomPickStats is a completed draft's counts captured under draftHub.mu so
+// they can be written without holding it.
+type roomPickStats struct {
+	room     *draftRoom
+	key      string
+	deckSlug string
+	seq      uint64
+	counts   []cardPickCount
+}
+
+// savePickStats records every completed cube draft that changed since it was
+// last recorded and returns how many were written.
+func (h *draftHub) savePickStats(ctx context.Context) (int, error) {
+	h.mu.Lock()
+	store := h.roomStore
+	var pending []roomPickStats
+	for _, room := range h.rooms {
+		stats, ok, err := h.roomPickStatsLocked(room)
+		if err != nil {
+			h.mu.Unlock()
+			return 0, err
+		}
+		if ok {
+			pending = append(pending, stats)
+		}
+	}
+	h.mu.Unlock()
+
+	saved := 0
+	for _, stats := range pending {
+		if err := h.writeRoomPickStats(ctx, store, stats); err != nil {
+			return saved, err
+		}
+		saved++
+	}
+	return saved, nil
+}
+
+// roomPickStatsLocked captures one room's counts if it is a completed cube
+// draft that changed since the last write. Callers hold draftHub.mu.
+func (h *draftHub) roomPickStatsLocked(room *draftRoom) (roomPickStats, bool, error) {
+	if h.roomStore == nil || room.deckSlug == "" {
+		return roomPickStats{}, false, nil
+	}
+	room.mu.Lock()
+	defer room.mu.Unlock()
+	if room.draft.State() != "done" || room.draft.globalSeq == room.statsSeq {
+		return roomPickStats{}, false, nil
+	}
+	counts, err := room.draft.cardPickCounts()
+	if err != nil {
+		return roomPickStats{}, false, err
+	}
+	return roomPickStats{room: room, key: room.draftStatsKey(), deckSlug: room.deckSlug, seq: room.draft.globalSeq, counts: counts}, true, nil
+}
+
+// writeRoomPickStats writes captured counts, then marks the room recorded up to
+// their seq. Callers must not hold draftHub.mu.
+func (h *draftHub) writeRoomPickStats(ctx context.Context, store *draftRoomStore, stats roomPickStats) error {
+	if err := store.SaveCardStats(ctx, stats.key, stats.deckSlug, stats.counts); err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	stats.room.statsSeq = max(stats.room.statsSeq, stats.seq)
+	return nil
+}
+
+// draftStatsFromCounts derives averages and rates, ordering cards by earliest
+// average pick. Cards never picked sort last.
+func draftStatsFromCounts(deckSlug string, drafts int, counts []cardPickCount) buildtool.DraftStats {
+	stats := buildtool.DraftStats{DeckSlug: deckSlug, Drafts: drafts, Cards: make([]buildtool.DraftCardStats, 0, len(counts))}
+	for _, count := range counts {
+		card := buildtool.DraftCardStats{
+			Name:       count.Name,
+			Seen:       count.Seen,
+			Picked:     count.Picked,
+			FirstPicks: count.FirstPicks,
+			Burned:     count.Burned,
+			Mainboard:  count.Mainboard,
+			Sideboard:  count.Sideboard,
+		}
+		if count.Picked > 0 {
+			card.AvgPick = float64(count.PickPositionSum) / float64(count.Picked)
+		}
+		if count.Seen > 0 {
+			card.FirstPickRate = float64(count.FirstPicks) / float64(count.Seen)
+			card.BurnRate = float64(count.Burned) / float64(count.Seen)
+		}
+		if pooled := count.Mainboard + count.Sideboard; pooled > 0 {
+			card.MainboardRate = float64(count.Mainboard) / float64(pooled)
+		}
+		stats.Cards = append(stats.Cards, card)
+	}
+	sort.SliceStable(stats.Cards, func(i, j int) bool {
+		a, b := stats.Cards[i], stats.Cards[j]
+		if (a.Picked == 0) != (b.Picked == 0) {
+			return a.Picked > 0
+		}
+		if a.AvgPick != b.AvgPick {
+			return a.AvgPick < b.AvgPick
+		}
+		return a.Name < b.Name
+	})
+	return stats
+}
+
+// handleDraftStats serves GET /api/draft/stats?deck_slug=<slug>. It only reads:
+// the snapshot loop and deleteRoom record finished drafts, so a draft shows up
+// within one snapshot interval. The response can be saved as
+// data/<battlebox>/<deck>/draft-stats.json to include it in the build.
+func (h *draftHub) handleDraftStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	deckSlug := normalizeSlug(r.URL.Query().Get("deck_slug"))
+	if deckSlug == "" {
+		http.Error(w, "deck_slug query param required", http.StatusBadRequest)
+		return
+	}
+
+	h.mu.RLock()
+	store := h.roomStore
+	h.mu.RUnlock()
+	if store == nil {
+		http.Error(w, "draft stats unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	drafts, counts, err := store.LoadCardStats(r.Context(), deckSlug)
+	if err != nil {
+		http.Error(w, "failed to load draft stats", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(draftStatsFromCounts(deckSlug, drafts, counts))
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/lxing/battlebox/internal/buildtool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// draftToCompletion picks the first card of every pack, sending seat 1's last
+// pick to the sideboard, and returns the cards each seat took first.
+func draftToCompletion(t *testing.T, d *Draft) []string {
+	t.Helper()
+	firstPicks := make([]string, 0, d.Config.SeatCount)
+	seq := make([]uint64, d.Config.SeatCount)
+	for d.State() != "done" {
+		for seat := 0; seat < d.Config.SeatCount; seat++ {
+			st, err := d.PlayerState(seat)
+			require.NoError(t, err, "PlayerState")
+			zone := PickZoneMainboard
+			if seat == 1 && d.Progress.PickNumber == len(d.Config.PassPattern)-1 {
+				zone = PickZoneSideboard
+			}
+			if d.Progress.PackNumber == 0 && d.Progress.PickNumber == 0 {
+				firstPicks = append(firstPicks, st.Active.Cards[0])
+			}
+			seq[seat]++
+			_, err = d.PickBatch(seat, seq[seat], st.Active.PackID, []PickSelection{{CardName: st.Active.Cards[0], Zone: zone}})
+			require.NoError(t, err, "PickBatch")
+		}
+	}
+	return firstPicks
+}
+
+func TestCardPickCounts(t *testing.T) {
+	d := makeDraftWithConfig(t, DraftConfig{PackCount: 1, PackSize: 3, SeatCount: 2, PassPattern: []int{1, 1}})
+	_, err := d.cardPickCounts()
+	assert.ErrorIs(t, err, errDraftNotComplete, "counts need a finished draft")
+
+	firstPicks := draftToCompletion(t, d)
+	counts, err := d.cardPickCounts()
+	require.NoError(t, err, "cardPickCounts")
+
+	var total cardPickCount
+	byName := map[string]cardPickCount{}
+	for _, count := range counts {
+		byName[count.Name] = count
+		total.Seen += count.Seen
+		total.Picked += count.Picked
+		total.FirstPicks += count.FirstPicks
+		total.Burned += count.Burned
+		total.Mainboard += count.Mainboard
+		total.Sideboard += count.Sideboard
+		total.PickPositionSum += count.PickPositionSum
+	}
+	assert.Equal(t, cardPickCount{Seen: 6, Picked: 4, FirstPicks: 2, Burned: 2, Mainboard: 3, Sideboard: 1, PickPositionSum: 6}, total, "totals")
+	for _, name := range firstPicks {
+		assert.Equal(t, 1, byName[name].FirstPicks, "%s taken first", name)
+	}
+}
+
+func TestDraftStatsRecordedPerCubeAndServed(t *testing.T) {
+	store, err := openDraftRoomStore(filepath.Join(t.TempDir(), "db.sqlite"))
+	require.NoError(t, err, "openDraftRoomStore")
+	defer func() {
+		_ = store.Close()
+	}()
+	hub := newDraftHub()
+	hub.setRoomStore(store)
+
+	cfg := DraftConfig{PackCount: 1, PackSize: 3, SeatCount: 2, PassPattern: []int{1, 1}}
+	roomA := addTestRoom(t, hub, "room-a", cfg)
+	roomB := addTestRoom(t, hub, "room-b", cfg)
+	other := addTestRoom(t, hub, "room-c", cfg)
+	roomA.deckSlug, roomB.deckSlug, other.deckSlug = "cube", "cube", "legacy"
+	roomA.ownerDeviceID = "host"
+
+	saved, err := hub.savePickStats(context.Background())
+	require.NoError(t, err, "savePickStats")
+	assert.Zero(t, saved, "unfinished drafts are not recorded")
+
+	firstPicks := draftToCompletion(t, roomA.draft)
+	draftToCompletion(t, roomB.draft)
+	draftToCompletion(t, other.draft)
+	unsaved := httptest.NewRecorder()
+	hub.handleDraftStats(unsaved, httptest.NewRequest(http.MethodGet, "/api/draft/stats?deck_slug=cube", nil))
+	require.Equal(t, http.StatusOK, unsaved.Code, "status: %s", unsaved.Body.String())
+	var before buildtool.DraftStats
+	require.NoError(t, json.Unmarshal(unsaved.Body.Bytes(), &before), "decode stats")
+	assert.Zero(t, before.Drafts, "reading stats does not record drafts")
+	saved, err = hub.savePickStats(context.Background())
+	require.NoError(t, err, "savePickStats")
+	assert.Equal(t, 3, saved, "finished drafts recorded")
+	saved, err = hub.savePickStats(context.Background())
+	require.NoError(t, err, "savePickStats")
+	assert.Zero(t, saved, "unchanged drafts are not rewritten")
+
+	require.NoError(t, hub.deleteRoom(context.Background(), "room-a", "host"), "deleteRoom")
+
+	rr := httptest.NewRecorder()
+	hub.handleDraftStats(rr, httptest.NewRequest(http.MethodGet, "/api/draft/stats?deck_slug=cube", nil))
+	require.Equal(t, http.StatusOK, rr.Code, "status: %s", rr.Body.String())
+	var stats buildtool.DraftStats
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &stats), "decode stats")
+	assert.Equal(t, "cube", stats.DeckSlug, "deck slug")
+	assert.Equal(t, 2, stats.Drafts, "stats outlive the deleted room and exclude other cubes")
+
+	seen := 0
+	for _, card := range stats.Cards {
+		seen += card.Seen
+	}
+	assert.Equal(t, 12, seen, "cards dealt across both drafts")
+	require.NotEmpty(t, stats.Cards, "cards")
+	first := stats.Cards[0]
+	assert.Contains(t, firstPicks, first.Name, "earliest average pick sorts first")
+	assert.InDelta(t, 1.0, first.AvgPick, 1e-9, "first pick average position")
+	assert.InDelta(t, 1.0, first.FirstPickRate, 1e-9, "first pick rate")
+	assert.InDelta(t, 1.0, first.MainboardRate, 1e-9, "first picks went to the mainboard")
+	last := stats.Cards[len(stats.Cards)-1]
+	assert.Zero(t, last.Picked, "burned cards sort last")
+	assert.InDelta(t, 1.0, last.BurnRate, 1e-9, "burn rate")
+
+	missing := httptest.NewRecorder()
+	hub.handleDraftStats(missing, httptest.NewRequest(http.MethodGet, "/api/draft/stats", nil))
+	assert.Equal(t, http.StatusBadRequest, missing.Code, "deck_slug required")
+}
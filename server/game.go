@@ -0,0 +1,250 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Game sessions share the life tracker between devices at a table. The state
+// mirrors the life tracker's local storage shape (battlebox.life.v1 in life.js)
+// and changes only through versioned mutations.
+
+const (
+	defaultGameStartingLife = 20
+	gameTokenMax            = 20
+	gameManaMax             = 99
+	gameLifeLimit           = 9999
+	gameActionLogLimit      = 200
+)
+
+var gamePlayerIDs = []string{"p1", "p2"}
+
+// gameTokenIDs and gameManaIDs match TOKEN_TYPES and MANA_TYPES in life.js.
+var gameTokenIDs = []string{"blood", "treasure", "food", "clue", "map"}
+var gameManaIDs = []string{"w", "u", "b", "r", "g", "c", "storm"}
+
+// undercityTransitions mirrors ROOM_TRANSITIONS in initiative.js. Room 0 is the
+// entrance before a player first ventures.
+var undercityTransitions = map[int][]int{
+	0: {1},
+	1: {2, 3},
+	2: {4, 5},
+	3: {5, 6},
+	4: {7},
+	5: {7, 8},
+	6: {8},
+	7: {9},
+	8: {9},
+	9: {1},
+}
+
+const (
+	gameMutationAdjustLife    = "adjust_life"
+	gameMutationSetLife       = "set_life"
+	gameMutationAdjustCounter = "adjust_counter"
+	gameMutationSetCounter    = "set_counter"
+	gameMutationSetMonarch    = "set_monarch"
+	gameMutationSetInitiative = "set_initiative"
+	gameMutationVenture       = "venture"
+	gameMutationReset         = "reset"
+)
+
+var errGameStaleVersion = errors.New("game state changed; retry against the current version")
+var errGameInvalidMutation = errors.New("invalid game mutation")
+
+type gamePlayerState struct {
+	Life int `json:"life"`
+	// Tokens holds the token counters a player has shown; hidden tokens are absent.
+	Tokens map[string]int `json:"tokens"`
+	Mana   map[string]int `json:"mana"`
+}
+
+type gameInitiativeRooms struct {
+	P1 int `json:"p1"`
+	P2 int `json:"p2"`
+}
+
+type gameInitiative struct {
+	Owner string              `json:"owner,omitempty"`
+	Rooms gameInitiativeRooms `json:"rooms"`
+}
+
+type gameState struct {
+	// Version increases by one with every applied mutation.
+	Version      uint64          `json:"version"`
+	StartingLife int             `json:"starting_life"`
+	P1           gamePlayerState `json:"p1"`
+	P2           gamePlayerState `json:"p2"`
+	Monarch      string          `json:"monarch,omitempty"`
+	Initiative   gameInitiative  `json:"initiative"`
+}
+
+// gameMutation is one requested change. Fields other than Type are used
+// depending on the mutation type.
+type gameMutation struct {
+	Type    string `json:"type"`
+	Player  string `json:"player,omitempty"`
+	Counter string `json:"counter,omitempty"`
+	Delta   int    `json:"delta,omitempty"`
+	// Value sets a life total or counter; a null counter value hides a token.
+	Value *int `json:"value,omitempty"`
+	Room  int  `json:"room,omitempty"`
+}
+
+// gameAction is an applied mutation in the session's action log.
+type gameAction struct {
+	gameMutation
+	Version uint64    `json:"version"`
+	Name    string    `json:"name,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+func newGameState(startingLife int) gameState {
+	if startingLife <= 0 {
+		startingLife = defaultGameStartingLife
+	}
+	return gameState{
+		StartingLife: startingLife,
+		P1:           newGamePlayerState(startingLife),
+		P2:           newGamePlayerState(startingLife),
+	}
+}
+
+func newGamePlayerState(life int) gamePlayerState {
+	mana := make(map[string]int, len(gameManaIDs))
+	for _, id := range gameManaIDs {
+		mana[id] = 0
+	}
+	return gamePlayerState{Life: life, Tokens: map[string]int{}, Mana: mana}
+}
+
+func (p gamePlayerState) clone() gamePlayerState {
+	out := p
+	out.Tokens = make(map[string]int, len(p.Tokens))
+	for id, n := range p.Tokens {
+		out.Tokens[id] = n
+	}
+	out.Mana = make(map[string]int, len(p.Mana))
+	for id, n := range p.Mana {
+		out.Mana[id] = n
+	}
+	return out
+}
+
+func (s gameState) clone() gameState {
+	out := s
+	out.P1 = s.P1.clone()
+	out.P2 = s.P2.clone()
+	return out
+}
+
+func (s *gameState) player(id string) *gamePlayerState {
+	switch id {
+	case "p1":
+		return &s.P1
+	case "p2":
+		return &s.P2
+	default:
+		return nil
+	}
+}
+
+func (r *gameInitiativeRooms) room(player string) *int {
+	if player == "p2" {
+		return &r.P2
+	}
+	return &r.P1
+}
+
+func clampInt(value, lo, hi int) int {
+	return max(lo, min(hi, value))
+}
+
+func invalidGameMutation(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", errGameInvalidMutation, fmt.Sprintf(format, args...))
+}
+
+// apply validates m and applies it, bumping the version. The state is left
+// unchanged when m is rejected.
+func (s *gameState) apply(m gameMutation) error {
+	var player *gamePlayerState
+	switch m.Type {
+	case gameMutationReset:
+	case gameMutationSetMonarch, gameMutationSetInitiative:
+		if m.Player != "" && !slices.Contains(gamePlayerIDs, m.Player) {
+			return invalidGameMutation("unknown player %q", m.Player)
+		}
+	default:
+		player = s.player(m.Player)
+		if player == nil {
+			return invalidGameMutation("unknown player %q", m.Player)
+		}
+	}
+
+	switch m.Type {
+	case gameMutationAdjustLife:
+		player.Life = clampInt(player.Life+m.Delta, -gameLifeLimit, gameLifeLimit)
+	case gameMutationSetLife:
+		if m.Value == nil {
+			return invalidGameMutation("set_life needs a value")
+		}
+		player.Life = clampInt(*m.Value, -gameLifeLimit, gameLifeLimit)
+	case gameMutationAdjustCounter, gameMutationSetCounter:
+		if err := applyGameCounter(player, m); err != nil {
+			return err
+		}
+	case gameMutationSetMonarch:
+		s.Monarch = m.Player
+	case gameMutationSetInitiative:
+		s.Initiative.Owner = m.Player
+	case gameMutationVenture:
+		current := s.Initiative.Rooms.room(m.Player)
+		if !slices.Contains(undercityTransitions[*current], m.Room) {
+			return invalidGameMutation("cannot venture from room %d to %d", *current, m.Room)
+		}
+		*current = m.Room
+		// Any successful move grants the initiative, as in initiative.js.
+		s.Initiative.Owner = m.Player
+	case gameMutationReset:
+		startingLife := s.StartingLife
+		if m.Value != nil {
+			if *m.Value <= 0 || *m.Value > gameLifeLimit {
+				return invalidGameMutation("starting life must be between 1 and %d", gameLifeLimit)
+			}
+			startingLife = *m.Value
+		}
+		next := newGameState(startingLife)
+		next.Version = s.Version
+		*s = next
+	default:
+		return invalidGameMutation("unknown mutation %q", m.Type)
+	}
+	s.Version++
+	return nil
+}
+
+func applyGameCounter(player *gamePlayerState, m gameMutation) error {
+	switch {
+	case slices.Contains(gameTokenIDs, m.Counter):
+		if m.Type == gameMutationAdjustCounter {
+			player.Tokens[m.Counter] = clampInt(player.Tokens[m.Counter]+m.Delta, 0, gameTokenMax)
+		} else if m.Value == nil {
+			delete(player.Tokens, m.Counter)
+		} else {
+			player.Tokens[m.Counter] = clampInt(*m.Value, 0, gameTokenMax)
+		}
+	case slices.Contains(gameManaIDs, m.Counter):
+		next := 0
+		if m.Type == gameMutationAdjustCounter {
+			next = player.Mana[m.Counter] + m.Delta
+		} else if m.Value != nil {
+			next = *m.Value
+		}
+		player.Mana[m.Counter] = clampInt(next, 0, gameManaMax)
+	default:
+		return invalidGameMutation("unknown counter %q", m.Counter)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestGameStateMutations(t *testing.T) {
+	state := newGameState(0)
+	assert.Equal(t, defaultGameStartingLife, state.P1.Life, "default starting life")
+
+	require.NoError(t, state.apply(gameMutation{Type: gameMutationAdjustLife, Player: "p2", Delta: -3}), "adjust life")
+	assert.Equal(t, 17, state.P2.Life, "p2 life")
+	require.NoError(t, state.apply(gameMutation{Type: gameMutationAdjustCounter, Player: "p1", Counter: "treasure", Delta: 25}), "adjust token")
+	assert.Equal(t, gameTokenMax, state.P1.Tokens["treasure"], "tokens clamp")
+	require.NoError(t, state.apply(gameMutation{Type: gameMutationSetCounter, Player: "p1", Counter: "treasure"}), "hide token")
+	assert.NotContains(t, state.P1.Tokens, "treasure", "null value hides a token")
+	require.NoError(t, state.apply(gameMutation{Type: gameMutationAdjustCounter, Player: "p1", Counter: "storm", Delta: -1}), "adjust mana")
+	assert.Zero(t, state.P1.Mana["storm"], "mana clamps at zero")
+	require.NoError(t, state.apply(gameMutation{Type: gameMutationSetMonarch, Player: "p2"}), "monarch")
+	assert.Equal(t, "p2", state.Monarch, "monarch")
+
+	require.NoError(t, state.apply(gameMutation{Type: gameMutationVenture, Player: "p1", Room: 1}), "venture into the undercity")
+	assert.Equal(t, "p1", state.Initiative.Owner, "venturing takes the initiative")
+	assert.ErrorIs(t, state.apply(gameMutation{Type: gameMutationVenture, Player: "p1", Room: 4}), errGameInvalidMutation, "room 4 is not reachable from room 1")
+	require.NoError(t, state.apply(gameMutation{Type: gameMutationVenture, Player: "p1", Room: 3}), "venture")
+	assert.Equal(t, gameInitiativeRooms{P1: 3}, state.Initiative.Rooms, "rooms")
+	assert.Equal(t, uint64(7), state.Version, "one version per applied mutation")
+
+	assert.ErrorIs(t, state.apply(gameMutation{Type: gameMutationAdjustLife, Player: "p3"}), errGameInvalidMutation, "unknown player")
+	assert.ErrorIs(t, state.apply(gameMutation{Type: gameMutationAdjustCounter, Player: "p1", Counter: "poison"}), errGameInvalidMutation, "unknown counter")
+
+	require.NoError(t, state.apply(gameMutation{Type: gameMutationReset, Value: intPtr(30)}), "reset")
+	assert.Equal(t, 30, state.P1.Life, "reset to new starting life")
+	assert.Empty(t, state.Monarch, "reset clears the monarch")
+	assert.Equal(t, uint64(8), state.Version, "reset keeps counting versions")
+}
+
+func TestGameSessionRejectsStaleVersion(t *testing.T) {
+	hub := newGameHub()
+	session := hub.createSession("host", 20, time.Now())
+
+	action, err := session.mutate(0, gameMutation{Type: gameMutationAdjustLife, Player: "p1", Delta: -2}, "Ada", time.Now())
+	require.NoError(t, err, "mutate")
+	assert.Equal(t, uint64(1), action.Version, "action version")
+	_, err = session.mutate(0, gameMutation{Type: gameMutationAdjustLife, Player: "p1", Delta: -2}, "Bo", time.Now())
+	assert.ErrorIs(t, err, errGameStaleVersion, "mutation against an old version")
+
+	view := session.view("host")
+	assert.True(t, view.OwnedByRequest, "owner")
+	assert.Equal(t, 18, view.State.P1.Life, "only the first mutation applied")
+	require.Len(t, view.Log, 1, "action log")
+	assert.Equal(t, "Ada", view.Log[0].Name, "action author")
+}
+
+func readGameMessage(t *testing.T, conn *websocket.Conn) gameWSMessage {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)), "read deadline")
+	var msg gameWSMessage
+	require.NoError(t, conn.ReadJSON(&msg), "read game message")
+	return msg
+}
+
+func TestCreateGameSessionStartingLife(t *testing.T) {
+	hub := newGameHub()
+	create := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/game/sessions", strings.NewReader(body))
+		req.Header.Set("X-Device-ID", "host")
+		rec := httptest.NewRecorder()
+		hub.handleGameSessions(rec, req)
+		return rec
+	}
+
+	rec := create(`{"starting_life":0}`)
+	require.Equal(t, http.StatusCreated, rec.Code, "zero uses the default")
+	var created gameSessionView
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&created), "decode session")
+	assert.Equal(t, defaultGameStartingLife, created.State.P1.Life, "default starting life")
+
+	rec = create(`{"starting_life":-1}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "negative starting life")
+	assert.Contains(t, rec.Body.String(), "0 for the default", "error mentions the default")
+}
+
+func TestGameSessionWebsocketBroadcastsActions(t *testing.T) {
+	hub := newGameHub()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/game/sessions", hub.handleGameSessions)
+	mux.HandleFunc("/api/game/ws", hub.handleGameWS)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/api/game/sessions", strings.NewReader(`{"starting_life":25}`))
+	require.NoError(t, err, "new request")
+	req.Header.Set("X-Device-ID", "host")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err, "create session")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode, "create status")
+	var created gameSessionView
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created), "decode session")
+	assert.Equal(t, 25, created.State.P2.Life, "starting life")
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/game/ws?session=" + created.SessionID
+	p1, _, err := websocket.DefaultDialer.Dial(wsURL+"&name=Ada", nil)
+	require.NoError(t, err, "dial p1")
+	defer p1.Close()
+	p2, _, err := websocket.DefaultDialer.Dial(wsURL+"&name=Bo", nil)
+	require.NoError(t, err, "dial p2")
+	defer p2.Close()
+	assert.Equal(t, gameWSTypeState, readGameMessage(t, p1).Type, "p1 initial state")
+	assert.Equal(t, gameWSTypeState, readGameMessage(t, p2).Type, "p2 initial state")
+
+	version := uint64(0)
+	require.NoError(t, p1.WriteJSON(gameWSMessage{Type: gameWSTypeMutate, Version: &version, RequestID: "r1", Mutation: &gameMutation{Type: gameMutationAdjustLife, Player: "p2", Delta: -5}}), "p1 mutate")
+	for _, conn := range []*websocket.Conn{p1, p2} {
+		msg := readGameMessage(t, conn)
+		require.Equal(t, gameWSTypeAction, msg.Type, "broadcast action")
+		assert.Equal(t, "r1", msg.RequestID, "request id echoed")
+		assert.Equal(t, "Ada", msg.Action.Name, "action author")
+		assert.Equal(t, 20, msg.State.P2.Life, "state after action")
+	}
+
+	require.NoError(t, p2.WriteJSON(gameWSMessage{Type: gameWSTypeMutate, Version: &version, RequestID: "r2", Mutation: &gameMutation{Type: gameMutationSetMonarch, Player: "p2"}}), "p2 stale mutate")
+	rejected := readGameMessage(t, p2)
+	assert.Equal(t, gameCodeStaleVersion, rejected.Code, "stale version code")
+	require.NotNil(t, rejected.State, "current state sent with the rejection")
+	assert.Equal(t, uint64(1), rejected.State.Version, "current version")
+
+	missing, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/api/game/ws?session=nope", nil)
+	require.NoError(t, err, "dial missing session")
+	defer missing.Close()
+	assert.Equal(t, gameCodeMissing, readGameMessage(t, missing).Code, "missing session")
+}
+
+func TestGameSessionsRestoreFromStore(t *testing.T) {
+	store, err := openDraftRoomStore(filepath.Join(t.TempDir(), "db.sqlite"))
+	require.NoError(t, err, "openDraftRoomStore")
+	defer func() {
+		_ = store.Close()
+	}()
+
+	hub := newGameHub()
+	hub.setStore(store)
+	session := hub.createSession("host", 20, time.Now())
+	_, err = session.mutate(0, gameMutation{Type: gameMutationAdjustCounter, Player: "p1", Counter: "clue", Delta: 2}, "Ada", time.Now())
+	require.NoError(t, err, "mutate")
+
+	saved, err := hub.snapshotAndSaveSessions(context.Background())
+	require.NoError(t, err, "snapshotAndSaveSessions")
+	assert.Equal(t, 1, saved, "changed session saved")
+	saved, err = hub.snapshotAndSaveSessions(context.Background())
+	require.NoError(t, err, "snapshotAndSaveSessions")
+	assert.Zero(t, saved, "unchanged session skipped")
+
+	records, err := store.LoadGameSessions(context.Background())
+	require.NoError(t, err, "LoadGameSessions")
+	restored := newGameHub()
+	require.NoError(t, restored.restoreSessions(records), "restoreSessions")
+	view := restored.session(session.id).view("host")
+	assert.True(t, view.OwnedByRequest, "owner restored")
+	assert.Equal(t, 2, view.State.P1.Tokens["clue"], "tokens restored")
+	assert.Equal(t, uint64(1), view.State.Version, "version restored")
+	require.Len(t, view.Log, 1, "action log restored")
+
+	require.NoError(t, hub.deleteSession(context.Background(), session.id, "host"), "deleteSession")
+	records, err = store.LoadGameSessions(context.Background())
+	require.NoError(t, err, "LoadGameSessions")
+	assert.Empty(t, records, "deleted session removed from the store")
+}
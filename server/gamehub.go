@@ -0,0 +1,448 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// gameHub hosts shared life tracker sessions and shared-library sessions. Like
+// draftHub it keeps sessions in memory behind per-session mutexes and snapshots
+// them to SQLite on the ticker.
+type gameHub struct {
+	mu        sync.RWMutex
+	sessions  map[string]*gameSession
+	libraries map[string]*librarySession
+	store     *draftRoomStore
+}
+
+type gameSession struct {
+	id            string
+	ownerDeviceID string
+
+	mu        sync.Mutex
+	closed    bool
+	state     gameState
+	log       []gameAction // most recent gameActionLogLimit actions, oldest first
+	clients   map[*websocket.Conn]struct{}
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+// gameWSMessage is the game session websocket frame. Clients send "state" to
+// resync and "mutate" with the version they last saw; the server answers with
+// "state" and broadcasts "action" to every connection after each mutation.
+type gameWSMessage struct {
+	Type string `json:"type"`
+	// Version is the state version a mutation was made against.
+	Version  *uint64       `json:"version,omitempty"`
+	Mutation *gameMutation `json:"mutation,omitempty"`
+	// RequestID is echoed on the resulting action or error so clients can match replies.
+	RequestID string       `json:"request_id,omitempty"`
+	State     *gameState   `json:"state,omitempty"`
+	Action    *gameAction  `json:"action,omitempty"`
+	Log       []gameAction `json:"log,omitempty"`
+	Error     string       `json:"error,omitempty"`
+	Code      string       `json:"code,omitempty"`
+}
+
+const (
+	gameWSTypeState  = "state"
+	gameWSTypeMutate = "mutate"
+	gameWSTypeAction = "action"
+	gameWSTypeError  = "error"
+
+	gameCodeStaleVersion   = "stale_version"
+	gameCodeInvalidMessage = "invalid_message"
+	gameCodeRejected       = "rejected"
+	gameCodeMissing        = "session_missing"
+)
+
+type createGameSessionRequest struct {
+	StartingLife int `json:"starting_life,omitempty"`
+}
+
+type gameSessionView struct {
+	SessionID      string       `json:"session_id"`
+	OwnedByRequest bool         `json:"owned_by_requester"`
+	Connections    int          `json:"connections"`
+	State          gameState    `json:"state"`
+	Log            []gameAction `json:"log"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+type gameSessionSnapshot struct {
+	OwnerDeviceID string       `json:"owner_device_id,omitempty"`
+	CreatedAt     time.Time    `json:"created_at,omitzero"`
+	UpdatedAt     time.Time    `json:"updated_at,omitzero"`
+	State         gameState    `json:"state"`
+	Log           []gameAction `json:"log,omitempty"`
+}
+
+type gameSessionRecord struct {
+	SessionID string
+	Snapshot  gameSessionSnapshot
+}
+
+var errGameSessionNotFound = errors.New("game session not found")
+
+func newGameHub() *gameHub {
+	return &gameHub{
+		sessions:  make(map[string]*gameSession),
+		libraries: make(map[string]*librarySession),
+	}
+}
+
+func (h *gameHub) setStore(store *draftRoomStore) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.store = store
+}
+
+func (h *gameHub) session(sessionID string) *gameSession {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.sessions[sessionID]
+}
+
+func (h *gameHub) nextSessionIDLocked() string {
+	for attempt := 0; attempt < 32; attempt++ {
+		candidate := randomRoomID()
+		if h.sessions[candidate] == nil && h.libraries[candidate] == nil {
+			return candidate
+		}
+	}
+	return fmt.Sprintf("game-%d", time.Now().UnixNano())
+}
+
+func (h *gameHub) createSession(ownerDeviceID string, startingLife int, now time.Time) *gameSession {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	session := &gameSession{
+		id:            h.nextSessionIDLocked(),
+		ownerDeviceID: ownerDeviceID,
+		state:         newGameState(startingLife),
+		log:           []gameAction{},
+		clients:       make(map[*websocket.Conn]struct{}),
+		createdAt:     now,
+		updatedAt:     now,
+	}
+	h.sessions[session.id] = session
+	return session
+}
+
+func (h *gameHub) deleteSession(ctx context.Context, sessionID, requesterDeviceID string) error {
+	h.mu.Lock()
+	session, ok := h.sessions[sessionID]
+	if !ok {
+		h.mu.Unlock()
+		return errGameSessionNotFound
+	}
+	if session.ownerDeviceID == "" || session.ownerDeviceID != requesterDeviceID {
+		h.mu.Unlock()
+		return errDraftRoomForbidden
+	}
+	if h.store != nil {
+		if err := h.store.DeleteGameSession(ctx, sessionID); err != nil {
+			h.mu.Unlock()
+			return fmt.Errorf("delete game session snapshot: %w", err)
+		}
+	}
+	delete(h.sessions, sessionID)
+	h.mu.Unlock()
+
+	session.mu.Lock()
+	session.closed = true
+	for conn := range session.clients {
+		_ = conn.Close()
+		delete(session.clients, conn)
+	}
+	session.mu.Unlock()
+	return nil
+}
+
+// mutate applies m if version matches the current state version and appends it
+// to the action log.
+func (s *gameSession) mutate(version uint64, m gameMutation, name string, now time.Time) (gameAction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mutateLocked(version, m, name, now)
+}
+
+func (s *gameSession) mutateLocked(version uint64, m gameMutation, name string, now time.Time) (gameAction, error) {
+	if s.closed {
+		return gameAction{}, errGameSessionNotFound
+	}
+	if version != s.state.Version {
+		return gameAction{}, errGameStaleVersion
+	}
+	next := s.state.clone()
+	if err := next.apply(m); err != nil {
+		return gameAction{}, err
+	}
+	s.state = next
+	action := gameAction{gameMutation: m, Version: next.Version, Name: name, At: now}
+	s.log = append(s.log, action)
+	if len(s.log) > gameActionLogLimit {
+		s.log = append([]gameAction(nil), s.log[len(s.log)-gameActionLogLimit:]...)
+	}
+	s.updatedAt = now
+	return action, nil
+}
+
+func (s *gameSession) view(requesterDeviceID string) gameSessionView {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return gameSessionView{
+		SessionID:      s.id,
+		OwnedByRequest: requesterDeviceID != "" && requesterDeviceID == s.ownerDeviceID,
+		Connections:    len(s.clients),
+		State:          s.state.clone(),
+		Log:            append([]gameAction{}, s.log...),
+		CreatedAt:      s.createdAt,
+		UpdatedAt:      s.updatedAt,
+	}
+}
+
+func (s *gameSession) addConn(conn *websocket.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.clients[conn] = struct{}{}
+	return true
+}
+
+func (s *gameSession) removeConn(conn *websocket.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.clients, conn)
+}
+
+func (s *gameSession) writeToConn(conn *websocket.Conn, msg gameWSMessage) {
+	if err := conn.WriteJSON(msg); err != nil {
+		_ = conn.Close()
+	}
+}
+
+func (s *gameSession) sendState(conn *websocket.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.state.clone()
+	s.writeToConn(conn, gameWSMessage{Type: gameWSTypeState, State: &state, Log: append([]gameAction{}, s.log...)})
+}
+
+// handleMutate applies a client mutation and broadcasts the result. Rejected
+// mutations are answered with an error frame carrying the current state.
+func (s *gameSession) handleMutate(conn *websocket.Conn, msg gameWSMessage, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if msg.Version == nil || msg.Mutation == nil {
+		s.writeToConn(conn, gameWSMessage{Type: gameWSTypeError, Error: "missing mutation fields", Code: gameCodeInvalidMessage, RequestID: msg.RequestID})
+		return
+	}
+	action, err := s.mutateLocked(*msg.Version, *msg.Mutation, name, time.Now())
+	if err != nil {
+		state := s.state.clone()
+		code := gameCodeRejected
+		if errors.Is(err, errGameStaleVersion) {
+			code = gameCodeStaleVersion
+		}
+		s.writeToConn(conn, gameWSMessage{Type: gameWSTypeError, Error: err.Error(), Code: code, RequestID: msg.RequestID, State: &state})
+		return
+	}
+	state := s.state.clone()
+	for client := range s.clients {
+		s.writeToConn(client, gameWSMessage{Type: gameWSTypeAction, Action: &action, State: &state, RequestID: msg.RequestID})
+	}
+}
+
+// handleGameSessions serves GET (one session by session_id), POST (create) and
+// DELETE (owner only) on /api/game/sessions.
+func (h *gameHub) handleGameSessions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodPost, http.MethodDelete:
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	requesterDeviceID, err := requesterDeviceIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		defer r.Body.Close()
+		var req createGameSessionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid json body", http.StatusBadRequest)
+			return
+		}
+		if req.StartingLife < 0 || req.StartingLife > gameLifeLimit {
+			http.Error(w, fmt.Sprintf("starting_life must be between 1 and %d, or 0 for the default", gameLifeLimit), http.StatusBadRequest)
+			return
+		}
+		session := h.createSession(requesterDeviceID, req.StartingLife, time.Now())
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(session.view(requesterDeviceID))
+		return
+	}
+
+	sessionID := r.URL.Query().Get("session_id")
+	if sessionID == "" {
+		http.Error(w, "session_id query param required", http.StatusBadRequest)
+		return
+	}
+	if r.Method == http.MethodDelete {
+		if err := h.deleteSession(r.Context(), sessionID, requesterDeviceID); err != nil {
+			switch {
+			case errors.Is(err, errGameSessionNotFound):
+				http.Error(w, "game session not found", http.StatusNotFound)
+			case errors.Is(err, errDraftRoomForbidden):
+				http.Error(w, "only the creator may delete this game session", http.StatusForbidden)
+			default:
+				http.Error(w, "failed to delete game session", http.StatusInternalServerError)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	session := h.session(sessionID)
+	if session == nil {
+		http.Error(w, "game session not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(session.view(requesterDeviceID))
+}
+
+// handleGameWS serves GET /api/game/ws?session=<id>&name=<display name>. Every
+// device at the table connects to the same session; name labels its actions.
+func (h *gameHub) handleGameWS(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.URL.Query().Get("session")
+	name := normalizeHostName(r.URL.Query().Get("name"))
+
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	session := h.session(sessionID)
+	if session == nil || !session.addConn(conn) {
+		_ = conn.WriteJSON(gameWSMessage{Type: gameWSTypeError, Error: "Game session not found", Code: gameCodeMissing})
+		return
+	}
+	defer session.removeConn(conn)
+
+	session.sendState(conn)
+	for {
+		var msg gameWSMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		switch msg.Type {
+		case gameWSTypeState:
+			session.sendState(conn)
+		case gameWSTypeMutate:
+			session.handleMutate(conn, msg, name)
+		default:
+			continue
+		}
+	}
+}
+
+func (s *gameSession) snapshotRecord() gameSessionRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return gameSessionRecord{
+		SessionID: s.id,
+		Snapshot: gameSessionSnapshot{
+			OwnerDeviceID: s.ownerDeviceID,
+			CreatedAt:     s.createdAt,
+			UpdatedAt:     s.updatedAt,
+			State:         s.state.clone(),
+			Log:           append([]gameAction(nil), s.log...),
+		},
+	}
+}
+
+// snapshotAndSaveSessions writes sessions whose version changed since the last
+// save and returns how many were written.
+func (h *gameHub) snapshotAndSaveSessions(ctx context.Context) (int, error) {
+	h.mu.RLock()
+	store := h.store
+	records := make([]gameSessionRecord, 0, len(h.sessions))
+	for _, session := range h.sessions {
+		records = append(records, session.snapshotRecord())
+	}
+	h.mu.RUnlock()
+	if store == nil {
+		return 0, errors.New("game session store not initialized")
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].SessionID < records[j].SessionID
+	})
+	return store.SaveGameSessions(ctx, records)
+}
+
+func (h *gameHub) restoreSessions(records []gameSessionRecord) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, record := range records {
+		if record.SessionID == "" {
+			return errors.New("cannot restore game session with empty id")
+		}
+		snapshot := record.Snapshot
+		state := snapshot.State
+		if state.StartingLife <= 0 {
+			return fmt.Errorf("restore game session %q: missing starting life", record.SessionID)
+		}
+		for _, player := range []*gamePlayerState{&state.P1, &state.P2} {
+			if player.Tokens == nil {
+				player.Tokens = map[string]int{}
+			}
+			if player.Mana == nil {
+				player.Mana = newGamePlayerState(state.StartingLife).Mana
+			}
+		}
+		createdAt := snapshot.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+		updatedAt := snapshot.UpdatedAt
+		if updatedAt.IsZero() {
+			updatedAt = createdAt
+		}
+		log := snapshot.Log
+		if log == nil {
+			log = []gameAction{}
+		}
+		h.sessions[record.SessionID] = &gameSession{
+			id:            record.SessionID,
+			ownerDeviceID: snapshot.OwnerDeviceID,
+			state:         state,
+			log:           log,
+			clients:       make(map[*websocket.Conn]struct{}),
+			createdAt:     createdAt,
+			updatedAt:     updatedAt,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Shared-library sessions let two players draw from one shuffled library, as
+// the shared battlebox decks (Dandan, draft chaff) are played. The server holds
+// every zone; each player only ever receives views of what they may see.
+
+const sharedLibraryBattlebox = "shared"
+
+const (
+	libraryMutationDraw        = "draw"
+	libraryMutationMill        = "mill"
+	libraryMutationScry        = "scry"
+	libraryMutationScryResolve = "scry_resolve"
+	libraryMutationShuffle     = "shuffle"
+	libraryMutationReveal      = "reveal"
+	libraryMutationDiscard     = "discard"
+)
+
+var errLibraryInvalidMutation = errors.New("invalid library action")
+
+// libraryCard is one physical card. IDs tell duplicate copies apart.
+type libraryCard struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type librarySeat struct {
+	Hand      []libraryCard `json:"hand"`
+	Graveyard []libraryCard `json:"graveyard"`
+	// Scry holds the ids of the top cards this player is looking at, in library
+	// order, until the scry is resolved.
+	Scry []int `json:"scry,omitempty"`
+}
+
+type libraryReveal struct {
+	Player string        `json:"player"`
+	From   string        `json:"from"` // hand or library
+	Cards  []libraryCard `json:"cards"`
+}
+
+// libraryState is the full hidden state. It is persisted but never sent to
+// clients; the seed in particular would expose the library order.
+type libraryState struct {
+	Version  uint64         `json:"version"`
+	DeckSlug string         `json:"deck_slug"`
+	Seed     uint64         `json:"seed"`
+	Shuffles uint64         `json:"shuffles"`
+	Library  []libraryCard  `json:"library"` // top first
+	P1       librarySeat    `json:"p1"`
+	P2       librarySeat    `json:"p2"`
+	Reveal   *libraryReveal `json:"reveal,omitempty"`
+}
+
+// libraryMutation is one player action. The acting player comes from the
+// connection, never from the message.
+type libraryMutation struct {
+	Type  string `json:"type"`
+	Count int    `json:"count,omitempty"`
+	// Cards are hand card ids for reveal and discard.
+	Cards []int `json:"cards,omitempty"`
+	// Top and Bottom order the scried cards for scry_resolve; Top[0] becomes the top card.
+	Top    []int `json:"top,omitempty"`
+	Bottom []int `json:"bottom,omitempty"`
+}
+
+// libraryAction is the public record of an applied mutation. Cards only lists
+// cards both players can see.
+type libraryAction struct {
+	Version uint64        `json:"version"`
+	Type    string        `json:"type"`
+	Player  string        `json:"player"`
+	Count   int           `json:"count,omitempty"`
+	Bottom  int           `json:"bottom,omitempty"`
+	Cards   []libraryCard `json:"cards,omitempty"`
+	Name    string        `json:"name,omitempty"`
+	At      time.Time     `json:"at"`
+}
+
+// libraryView is what one connection sees. Spectators have no player and see
+// only public zones.
+type libraryView struct {
+	Version      uint64                   `json:"version"`
+	DeckSlug     string                   `json:"deck_slug"`
+	Player       string                   `json:"player,omitempty"`
+	LibraryCount int                      `json:"library_count"`
+	Hand         []libraryCard            `json:"hand,omitempty"`
+	Scry         []libraryCard            `json:"scry,omitempty"`
+	HandCounts   map[string]int           `json:"hand_counts"`
+	Graveyards   map[string][]libraryCard `json:"graveyards"`
+	Reveal       *libraryReveal           `json:"reveal,omitempty"`
+}
+
+func newLibraryState(deckSlug string, cards []string, seed uint64) libraryState {
+	library := make([]libraryCard, len(cards))
+	for i, name := range cards {
+		library[i] = libraryCard{ID: i + 1, Name: name}
+	}
+	shuffleLibrary(library, seed, 0)
+	return libraryState{
+		DeckSlug: deckSlug,
+		Seed:     seed,
+		Library:  library,
+		P1:       librarySeat{Hand: []libraryCard{}, Graveyard: []libraryCard{}},
+		P2:       librarySeat{Hand: []libraryCard{}, Graveyard: []libraryCard{}},
+	}
+}
+
+// shuffleLibrary shuffles in place. Stream 0 is the opening shuffle and each
+// later shuffle uses the next stream, so a library replays from its seed.
+func shuffleLibrary(cards []libraryCard, seed, stream uint64) {
+	rng := newDraftRand(seed, stream)
+	for i := len(cards) - 1; i > 0; i-- {
+		j := rng.IntN(i + 1)
+		cards[i], cards[j] = cards[j], cards[i]
+	}
+}
+
+func cloneLibraryCards(cards []libraryCard) []libraryCard {
+	return append([]libraryCard{}, cards...)
+}
+
+func (s librarySeat) clone() librarySeat {
+	return librarySeat{
+		Hand:      cloneLibraryCards(s.Hand),
+		Graveyard: cloneLibraryCards(s.Graveyard),
+		Scry:      append([]int(nil), s.Scry...),
+	}
+}
+
+func (s libraryState) clone() libraryState {
+	out := s
+	out.Library = cloneLibraryCards(s.Library)
+	out.P1 = s.P1.clone()
+	out.P2 = s.P2.clone()
+	if s.Reveal != nil {
+		reveal := *s.Reveal
+		reveal.Cards = cloneLibraryCards(s.Reveal.Cards)
+		out.Reveal = &reveal
+	}
+	return out
+}
+
+func (s *libraryState) seat(player string) *librarySeat {
+	switch player {
+	case "p1":
+		return &s.P1
+	case "p2":
+		return &s.P2
+	default:
+		return nil
+	}
+}
+
+func invalidLibraryMutation(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", errLibraryInvalidMutation, fmt.Sprintf(format, args...))
+}
+
+// takeTop removes the top count cards of the library.
+func (s *libraryState) takeTop(count int) ([]libraryCard, error) {
+	if count <= 0 {
+		return nil, invalidLibraryMutation("count must be > 0")
+	}
+	if count > len(s.Library) {
+		return nil, invalidLibraryMutation("library has %d cards", len(s.Library))
+	}
+	top := cloneLibraryCards(s.Library[:count])
+	s.Library = s.Library[count:]
+	return top, nil
+}
+
+// takeFromHand removes the given ids from a hand, in the order given.
+func takeFromHand(seat *librarySeat, ids []int) ([]libraryCard, error) {
+	if len(ids) == 0 {
+		return nil, invalidLibraryMutation("cards required")
+	}
+	hand := cloneLibraryCards(seat.Hand)
+	taken := make([]libraryCard, 0, len(ids))
+	for _, id := range ids {
+		idx := slices.IndexFunc(hand, func(card libraryCard) bool { return card.ID == id })
+		if idx < 0 {
+			return nil, invalidLibraryMutation("card %d is not in hand", id)
+		}
+		taken = append(taken, hand[idx])
+		hand = slices.Delete(hand, idx, idx+1)
+	}
+	seat.Hand = hand
+	return taken, nil
+}
+
+// clearScries drops pending scries; it runs whenever the library changes.
+func (s *libraryState) clearScries() {
+	s.P1.Scry = nil
+	s.P2.Scry = nil
+}
+
+// apply validates and applies one player's mutation and returns its public
+// record. A player with a pending scry must resolve it before touching the
+// library again.
+func (s *libraryState) apply(player string, m libraryMutation) (libraryAction, error) {
+	seat := s.seat(player)
+	if seat == nil {
+		return libraryAction{}, invalidLibraryMutation("only players may act")
+	}
+	action := libraryAction{Type: m.Type, Player: player}
+	if len(seat.Scry) > 0 && m.Type != libraryMutationScryResolve && m.Type != libraryMutationReveal && m.Type != libraryMutationDiscard {
+		return libraryAction{}, invalidLibraryMutation("resolve your scry first")
+	}
+
+	switch m.Type {
+	case libraryMutationDraw:
+		cards, err := s.takeTop(m.Count)
+		if err != nil {
+			return libraryAction{}, err
+		}
+		seat.Hand = append(seat.Hand, cards...)
+		action.Count = len(cards)
+		s.clearScries()
+	case libraryMutationMill:
+		cards, err := s.takeTop(m.Count)
+		if err != nil {
+			return libraryAction{}, err
+		}
+		seat.Graveyard = append(seat.Graveyard, cards...)
+		action.Count = len(cards)
+		action.Cards = cards
+		s.clearScries()
+	case libraryMutationScry:
+		if m.Count <= 0 || m.Count > len(s.Library) {
+			return libraryAction{}, invalidLibraryMutation("scry count must be between 1 and %d", len(s.Library))
+		}
+		seat.Scry = make([]int, m.Count)
+		for i, card := range s.Library[:m.Count] {
+			seat.Scry[i] = card.ID
+		}
+		action.Count = m.Count
+	case libraryMutationScryResolve:
+		if len(seat.Scry) == 0 {
+			return libraryAction{}, invalidLibraryMutation("no scry to resolve")
+		}
+		order := append(append([]int(nil), m.Top...), m.Bottom...)
+		if len(order) != len(seat.Scry) {
+			return libraryAction{}, invalidLibraryMutation("order every scried card")
+		}
+		byID := make(map[int]libraryCard, len(seat.Scry))
+		for _, card := range s.Library[:len(seat.Scry)] {
+			byID[card.ID] = card
+		}
+		placed := make([]libraryCard, 0, len(order))
+		for _, id := range order {
+			card, ok := byID[id]
+			if !ok {
+				return libraryAction{}, invalidLibraryMutation("card %d was not scried", id)
+			}
+			delete(byID, id)
+			placed = append(placed, card)
+		}
+		rest := s.Library[len(seat.Scry):]
+		library := make([]libraryCard, 0, len(s.Library))
+		library = append(library, placed[:len(m.Top)]...)
+		library = append(library, rest...)
+		library = append(library, placed[len(m.Top):]...)
+		s.Library = library
+		action.Count = len(m.Top)
+		action.Bottom = len(m.Bottom)
+		s.clearScries()
+	case libraryMutationShuffle:
+		s.Shuffles++
+		shuffleLibrary(s.Library, s.Seed, s.Shuffles)
+		action.Count = len(s.Library)
+		s.clearScries()
+	case libraryMutationReveal:
+		reveal := &libraryReveal{Player: player}
+		if m.Count > 0 {
+			if m.Count > len(s.Library) {
+				return libraryAction{}, invalidLibraryMutation("library has %d cards", len(s.Library))
+			}
+			reveal.From = "library"
+			reveal.Cards = cloneLibraryCards(s.Library[:m.Count])
+		} else {
+			// takeFromHand on a copy only checks the ids; revealed cards stay in hand.
+			check := librarySeat{Hand: seat.Hand}
+			cards, err := takeFromHand(&check, m.Cards)
+			if err != nil {
+				return libraryAction{}, err
+			}
+			reveal.From = "hand"
+			reveal.Cards = cards
+		}
+		s.Reveal = reveal
+		action.Count = len(reveal.Cards)
+		action.Cards = cloneLibraryCards(reveal.Cards)
+	case libraryMutationDiscard:
+		cards, err := takeFromHand(seat, m.Cards)
+		if err != nil {
+			return libraryAction{}, err
+		}
+		seat.Graveyard = append(seat.Graveyard, cards...)
+		action.Count = len(cards)
+		action.Cards = cards
+	default:
+		return libraryAction{}, invalidLibraryMutation("unknown action %q", m.Type)
+	}
+	s.Version++
+	action.Version = s.Version
+	return action, nil
+}
+
+// view returns what player sees; an empty player is a spectator.
+func (s *libraryState) view(player string) libraryView {
+	view := libraryView{
+		Version:      s.Version,
+		DeckSlug:     s.DeckSlug,
+		LibraryCount: len(s.Library),
+		HandCounts:   map[string]int{"p1": len(s.P1.Hand), "p2": len(s.P2.Hand)},
+		Graveyards:   map[string][]libraryCard{"p1": cloneLibraryCards(s.P1.Graveyard), "p2": cloneLibraryCards(s.P2.Graveyard)},
+	}
+	if s.Reveal != nil {
+		reveal := *s.Reveal
+		reveal.Cards = cloneLibraryCards(s.Reveal.Cards)
+		view.Reveal = &reveal
+	}
+	if seat := s.seat(player); seat != nil {
+		view.Player = player
+		view.Hand = cloneLibraryCards(seat.Hand)
+		if len(seat.Scry) > 0 {
+			view.Scry = cloneLibraryCards(s.Library[:len(seat.Scry)])
+		}
+	}
+	return view
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLibraryDecks struct{}
+
+func (fakeLibraryDecks) LibraryDeck(deckSlug string) ([]string, error) {
+	if deckSlug != "dandan" {
+		return nil, fmt.Errorf("shared deck %q not found", deckSlug)
+	}
+	names := make([]string, 0, 20)
+	for i := 0; i < 20; i++ {
+		names = append(names, fmt.Sprintf("Card %02d", i))
+	}
+	return names, nil
+}
+
+func useFakeLibraryDecks(t *testing.T) {
+	t.Helper()
+	previous := libraryDecks
+	libraryDecks = fakeLibraryDecks{}
+	t.Cleanup(func() { libraryDecks = previous })
+}
+
+func testLibraryState(t *testing.T) libraryState {
+	t.Helper()
+	cards, err := fakeLibraryDecks{}.LibraryDeck("dandan")
+	require.NoError(t, err, "LibraryDeck")
+	return newLibraryState("dandan", cards, 7)
+}
+
+func TestLibraryStateMutations(t *testing.T) {
+	state := testLibraryState(t)
+	again := testLibraryState(t)
+	assert.Equal(t, state.Library, again.Library, "same seed shuffles the same")
+	top := cloneLibraryCards(state.Library[:5])
+
+	action, err := state.apply("p1", libraryMutation{Type: libraryMutationDraw, Count: 2})
+	require.NoError(t, err, "draw")
+	assert.Equal(t, top[:2], state.P1.Hand, "draw takes the top cards")
+	assert.Empty(t, action.Cards, "drawn cards stay private")
+
+	action, err = state.apply("p2", libraryMutation{Type: libraryMutationMill, Count: 1})
+	require.NoError(t, err, "mill")
+	assert.Equal(t, top[2:3], state.P2.Graveyard, "mill to graveyard")
+	assert.Equal(t, top[2:3], action.Cards, "milled cards are public")
+
+	_, err = state.apply("p1", libraryMutation{Type: libraryMutationScry, Count: 2})
+	require.NoError(t, err, "scry")
+	_, err = state.apply("p1", libraryMutation{Type: libraryMutationDraw, Count: 1})
+	assert.ErrorIs(t, err, errLibraryInvalidMutation, "pending scry blocks draw")
+	_, err = state.apply("p1", libraryMutation{Type: libraryMutationScryResolve, Top: []int{top[4].ID}})
+	assert.ErrorIs(t, err, errLibraryInvalidMutation, "every scried card must be placed")
+	action, err = state.apply("p1", libraryMutation{Type: libraryMutationScryResolve, Top: []int{top[4].ID}, Bottom: []int{top[3].ID}})
+	require.NoError(t, err, "scry_resolve")
+	assert.Equal(t, 1, action.Count, "cards kept on top")
+	assert.Equal(t, 1, action.Bottom, "cards sent to the bottom")
+	assert.Equal(t, top[4], state.Library[0], "kept card on top")
+	assert.Equal(t, top[3], state.Library[len(state.Library)-1], "bottomed card last")
+
+	_, err = state.apply("p1", libraryMutation{Type: libraryMutationReveal, Cards: []int{top[0].ID}})
+	require.NoError(t, err, "reveal from hand")
+	require.NotNil(t, state.Reveal, "reveal recorded")
+	assert.Equal(t, "hand", state.Reveal.From, "reveal source")
+	assert.Len(t, state.P1.Hand, 2, "revealed card stays in hand")
+
+	_, err = state.apply("p1", libraryMutation{Type: libraryMutationDiscard, Cards: []int{top[1].ID}})
+	require.NoError(t, err, "discard")
+	assert.Equal(t, []libraryCard{top[0]}, state.P1.Hand, "discarded card left hand")
+	_, err = state.apply("p1", libraryMutation{Type: libraryMutationDiscard, Cards: []int{top[1].ID}})
+	assert.ErrorIs(t, err, errLibraryInvalidMutation, "card no longer in hand")
+
+	before := cloneLibraryCards(state.Library)
+	replay := state.clone()
+	_, err = state.apply("p2", libraryMutation{Type: libraryMutationShuffle})
+	require.NoError(t, err, "shuffle")
+	assert.ElementsMatch(t, before, state.Library, "shuffle keeps the cards")
+	_, err = replay.apply("p2", libraryMutation{Type: libraryMutationShuffle})
+	require.NoError(t, err, "replayed shuffle")
+	assert.Equal(t, state.Library, replay.Library, "shuffles replay from the seed")
+
+	_, err = state.apply("", libraryMutation{Type: libraryMutationDraw, Count: 1})
+	assert.ErrorIs(t, err, errLibraryInvalidMutation, "spectators cannot act")
+	_, err = state.apply("p1", libraryMutation{Type: libraryMutationDraw, Count: 99})
+	assert.ErrorIs(t, err, errLibraryInvalidMutation, "cannot draw past the library")
+	assert.Equal(t, uint64(7), state.Version, "one version per applied mutation")
+}
+
+func TestLibraryViewHidesOpponentCards(t *testing.T) {
+	state := testLibraryState(t)
+	_, err := state.apply("p1", libraryMutation{Type: libraryMutationDraw, Count: 3})
+	require.NoError(t, err, "draw")
+	_, err = state.apply("p2", libraryMutation{Type: libraryMutationScry, Count: 2})
+	require.NoError(t, err, "scry")
+
+	p1 := state.view("p1")
+	assert.Len(t, p1.Hand, 3, "own hand")
+	assert.Empty(t, p1.Scry, "opponent scry hidden")
+	assert.Equal(t, map[string]int{"p1": 3, "p2": 0}, p1.HandCounts, "hand counts")
+
+	p2 := state.view("p2")
+	assert.Empty(t, p2.Hand, "opponent hand hidden")
+	assert.Equal(t, state.Library[:2], p2.Scry, "own scry")
+
+	spectator := state.view("")
+	assert.Empty(t, spectator.Player, "spectator has no seat")
+	assert.Empty(t, spectator.Hand, "spectator sees no hand")
+	assert.Empty(t, spectator.Scry, "spectator sees no scry")
+	assert.Equal(t, 17, spectator.LibraryCount, "library count is public")
+
+	raw, err := json.Marshal(p1)
+	require.NoError(t, err, "marshal view")
+	assert.NotContains(t, string(raw), "seed", "views never carry the seed")
+}
+
+func readLibraryMessage(t *testing.T, conn *websocket.Conn) libraryWSMessage {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)), "read deadline")
+	var msg libraryWSMessage
+	require.NoError(t, conn.ReadJSON(&msg), "read library message")
+	return msg
+}
+
+func TestLibraryWebsocketSendsPerPlayerViews(t *testing.T) {
+	useFakeLibraryDecks(t)
+	hub := newGameHub()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/game/libraries", hub.handleLibrarySessions)
+	mux.HandleFunc("/api/game/library/ws", hub.handleLibraryWS)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/api/game/libraries", strings.NewReader(`{"deck_slug":"dandan","seed":7}`))
+	require.NoError(t, err, "new request")
+	req.Header.Set("X-Device-ID", "host")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err, "create session")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode, "create status")
+	var created librarySessionView
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created), "decode session")
+	assert.Equal(t, 20, created.View.LibraryCount, "library size")
+	assert.NotEqual(t, uint64(7), hub.library(created.SessionID).state.Seed, "client seed ignored")
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/game/library/ws?session=" + created.SessionID
+	_, _, err = websocket.DefaultDialer.Dial(wsURL+"&player=p1&name=Ada", nil)
+	assert.Error(t, err, "players need a device id")
+	p1, _, err := websocket.DefaultDialer.Dial(wsURL+"&player=p1&name=Ada&device_id=device-a", nil)
+	require.NoError(t, err, "dial p1")
+	defer p1.Close()
+	p2, _, err := websocket.DefaultDialer.Dial(wsURL+"&player=p2&name=Bo&device_id=device-b", nil)
+	require.NoError(t, err, "dial p2")
+	defer p2.Close()
+	assert.Equal(t, "p1", readLibraryMessage(t, p1).View.Player, "p1 initial view")
+	assert.Equal(t, "p2", readLibraryMessage(t, p2).View.Player, "p2 initial view")
+
+	dup, _, err := websocket.DefaultDialer.Dial(wsURL+"&player=p1&device_id=device-a", nil)
+	require.NoError(t, err, "dial duplicate p1")
+	defer dup.Close()
+	assert.Equal(t, gameCodeSeatOccupied, readLibraryMessage(t, dup).Code, "seat occupied")
+	thief, _, err := websocket.DefaultDialer.Dial(wsURL+"&player=p2&device_id=device-c", nil)
+	require.NoError(t, err, "dial p2 from another device")
+	defer thief.Close()
+	assert.Equal(t, gameCodeSeatClaimed, readLibraryMessage(t, thief).Code, "seat bound to device-b")
+
+	version := uint64(0)
+	require.NoError(t, p1.WriteJSON(libraryWSMessage{Type: gameWSTypeMutate, Version: &version, RequestID: "r1", Mutation: &libraryMutation{Type: libraryMutationDraw, Count: 7}}), "p1 draw")
+	mine := readLibraryMessage(t, p1)
+	require.Equal(t, gameWSTypeAction, mine.Type, "p1 action")
+	assert.Equal(t, "Ada", mine.Action.Name, "action author")
+	assert.Len(t, mine.View.Hand, 7, "p1 sees their hand")
+	theirs := readLibraryMessage(t, p2)
+	require.Equal(t, gameWSTypeAction, theirs.Type, "p2 action")
+	assert.Empty(t, theirs.View.Hand, "p2 does not see p1's hand")
+	assert.Equal(t, 7, theirs.View.HandCounts["p1"], "p2 sees the hand size")
+	assert.Empty(t, theirs.Action.Cards, "draws carry no card names")
+
+	require.NoError(t, p2.WriteJSON(libraryWSMessage{Type: gameWSTypeMutate, Version: &version, RequestID: "r2", Mutation: &libraryMutation{Type: libraryMutationDraw, Count: 1}}), "p2 stale draw")
+	rejected := readLibraryMessage(t, p2)
+	assert.Equal(t, gameCodeStaleVersion, rejected.Code, "stale version code")
+	require.NotNil(t, rejected.View, "current view sent with the rejection")
+	assert.Equal(t, uint64(1), rejected.View.Version, "current version")
+}
+
+func TestLibrarySessionsRestoreFromStore(t *testing.T) {
+	useFakeLibraryDecks(t)
+	store, err := openDraftRoomStore(filepath.Join(t.TempDir(), "db.sqlite"))
+	require.NoError(t, err, "openDraftRoomStore")
+	defer func() {
+		_ = store.Close()
+	}()
+
+	hub := newGameHub()
+	hub.setStore(store)
+	_, err = hub.createLibrary("host", "missing", 1, time.Now())
+	assert.Error(t, err, "unknown shared deck")
+	session, err := hub.createLibrary("host", "dandan", 7, time.Now())
+	require.NoError(t, err, "createLibrary")
+	session.seatDevices["p1"] = "device-a"
+	_, err = session.mutate(0, "p1", libraryMutation{Type: libraryMutationDraw, Count: 2}, "Ada", time.Now())
+	require.NoError(t, err, "draw")
+
+	saved, err := hub.snapshotAndSaveLibraries(context.Background())
+	require.NoError(t, err, "snapshotAndSaveLibraries")
+	assert.Equal(t, 1, saved, "changed session saved")
+	saved, err = hub.snapshotAndSaveLibraries(context.Background())
+	require.NoError(t, err, "snapshotAndSaveLibraries")
+	assert.Zero(t, saved, "unchanged session skipped")
+
+	records, err := store.LoadLibrarySessions(context.Background())
+	require.NoError(t, err, "LoadLibrarySessions")
+	restored := newGameHub()
+	require.NoError(t, restored.restoreLibraries(records), "restoreLibraries")
+	restoredSession := restored.library(session.id)
+	require.NotNil(t, restoredSession, "session restored")
+	assert.Equal(t, session.state, restoredSession.state, "hidden state restored")
+	assert.True(t, restoredSession.sessionView("host").OwnedByRequest, "owner restored")
+	assert.Equal(t, map[string]string{"p1": "device-a"}, restoredSession.seatDevices, "seat bindings restored")
+
+	require.NoError(t, hub.deleteLibrary(context.Background(), session.id, "host"), "deleteLibrary")
+	records, err = store.LoadLibrarySessions(context.Background())
+	require.NoError(t, err, "LoadLibrarySessions")
+	assert.Empty(t, records, "deleted session removed from the store")
+}
+
+func TestLibrarySeatClaimSurvivesRestartWithoutAction(t *testing.T) {
+	useFakeLibraryDecks(t)
+	store, err := openDraftRoomStore(filepath.Join(t.TempDir(), "db.sqlite"))
+	require.NoError(t, err, "openDraftRoomStore")
+	defer func() {
+		_ = store.Close()
+	}()
+
+	hub := newGameHub()
+	hub.setStore(store)
+	session, err := hub.createLibrary("host", "dandan", 7, time.Now())
+	require.NoError(t, err, "createLibrary")
+	saved, err := hub.snapshotAndSaveLibraries(context.Background())
+	require.NoError(t, err, "snapshotAndSaveLibraries")
+	require.Equal(t, 1, saved, "new session saved")
+
+	srv := httptest.NewServer(http.HandlerFunc(hub.handleLibraryWS))
+	defer srv.Close()
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "?session=" + session.id
+	p1, _, err := websocket.DefaultDialer.Dial(wsURL+"&player=p1&device_id=device-a", nil)
+	require.NoError(t, err, "dial p1")
+	defer p1.Close()
+	assert.Equal(t, "p1", readLibraryMessage(t, p1).View.Player, "p1 initial view")
+
+	// The claim is on disk before any action or snapshot tick.
+	records, err := store.LoadLibrarySessions(context.Background())
+	require.NoError(t, err, "LoadLibrarySessions")
+	restored := newGameHub()
+	require.NoError(t, restored.restoreLibraries(records), "restoreLibraries")
+	assert.Equal(t, map[string]string{"p1": "device-a"}, restored.library(session.id).seatDevices, "claim restored")
+
+	saved, err = hub.snapshotAndSaveLibraries(context.Background())
+	require.NoError(t, err, "snapshotAndSaveLibraries")
+	assert.Zero(t, saved, "saved claim is not rewritten")
+}
@@ -0,0 +1,507 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// librarySession is a shared-library game hosted by gameHub. Each player seat
+// allows one connection, like draft seats, and belongs to the device that first
+// claimed it; spectators may connect without a player and only see public zones.
+type librarySession struct {
+	id            string
+	ownerDeviceID string
+
+	mu      sync.Mutex
+	closed  bool
+	state   libraryState
+	log     []libraryAction // most recent gameActionLogLimit actions, oldest first
+	clients map[*websocket.Conn]string
+	// seatDevices maps each claimed player seat to its device id.
+	seatDevices map[string]string
+	createdAt   time.Time
+	updatedAt   time.Time
+}
+
+// libraryWSMessage is the shared-library websocket frame. It follows
+// gameWSMessage: "state" resyncs, "mutate" carries the version the client last
+// saw, and every applied action is sent to each connection with its own view.
+type libraryWSMessage struct {
+	Type      string           `json:"type"`
+	Version   *uint64          `json:"version,omitempty"`
+	Mutation  *libraryMutation `json:"mutation,omitempty"`
+	RequestID string           `json:"request_id,omitempty"`
+	View      *libraryView     `json:"view,omitempty"`
+	Action    *libraryAction   `json:"action,omitempty"`
+	Log       []libraryAction  `json:"log,omitempty"`
+	Error     string           `json:"error,omitempty"`
+	Code      string           `json:"code,omitempty"`
+}
+
+const (
+	gameCodeSeatOccupied = "seat_occupied"
+	gameCodeSeatClaimed  = "seat_claimed"
+)
+
+type createLibrarySessionRequest struct {
+	DeckSlug string `json:"deck_slug"`
+}
+
+type librarySessionView struct {
+	SessionID      string          `json:"session_id"`
+	OwnedByRequest bool            `json:"owned_by_requester"`
+	OccupiedSeats  []string        `json:"occupied_seats"`
+	View           libraryView     `json:"view"`
+	Log            []libraryAction `json:"log"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+type librarySessionSnapshot struct {
+	OwnerDeviceID string          `json:"owner_device_id,omitempty"`
+	CreatedAt     time.Time       `json:"created_at,omitzero"`
+	UpdatedAt     time.Time       `json:"updated_at,omitzero"`
+	State         libraryState    `json:"state"`
+	Log           []libraryAction `json:"log,omitempty"`
+	// SeatDevices is saved as soon as a seat is claimed, so a restart cannot
+	// free a claimed seat.
+	SeatDevices map[string]string `json:"seat_devices,omitempty"`
+}
+
+type librarySessionRecord struct {
+	SessionID string
+	Snapshot  librarySessionSnapshot
+}
+
+var (
+	errLibrarySessionNotFound = errors.New("library session not found")
+	errLibrarySeatClaimed     = errors.New("library seat claimed by another device")
+)
+
+func (h *gameHub) library(sessionID string) *librarySession {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.libraries[sessionID]
+}
+
+// createLibrary shuffles a shared battlebox deck's mainboard into a new session.
+func (h *gameHub) createLibrary(ownerDeviceID, deckSlug string, seed uint64, now time.Time) (*librarySession, error) {
+	cards, err := libraryDecks.LibraryDeck(deckSlug)
+	if err != nil {
+		return nil, err
+	}
+	if len(cards) == 0 {
+		return nil, fmt.Errorf("shared deck %q has no cards", deckSlug)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	id := h.nextSessionIDLocked()
+	session := &librarySession{
+		id:            id,
+		ownerDeviceID: ownerDeviceID,
+		state:         newLibraryState(deckSlug, cards, seed),
+		log:           []libraryAction{},
+		clients:       make(map[*websocket.Conn]string),
+		seatDevices:   make(map[string]string),
+		createdAt:     now,
+		updatedAt:     now,
+	}
+	h.libraries[id] = session
+	return session, nil
+}
+
+func (h *gameHub) deleteLibrary(ctx context.Context, sessionID, requesterDeviceID string) error {
+	h.mu.Lock()
+	session, ok := h.libraries[sessionID]
+	if !ok {
+		h.mu.Unlock()
+		return errLibrarySessionNotFound
+	}
+	if session.ownerDeviceID == "" || session.ownerDeviceID != requesterDeviceID {
+		h.mu.Unlock()
+		return errDraftRoomForbidden
+	}
+	if h.store != nil {
+		if err := h.store.DeleteLibrarySession(ctx, sessionID); err != nil {
+			h.mu.Unlock()
+			return fmt.Errorf("delete library session snapshot: %w", err)
+		}
+	}
+	delete(h.libraries, sessionID)
+	h.mu.Unlock()
+
+	session.mu.Lock()
+	session.closed = true
+	for conn := range session.clients {
+		_ = conn.Close()
+		delete(session.clients, conn)
+	}
+	session.mu.Unlock()
+	return nil
+}
+
+// mutateLocked applies m for player if version matches. Callers hold s.mu.
+func (s *librarySession) mutateLocked(version uint64, player string, m libraryMutation, name string, now time.Time) (libraryAction, error) {
+	if s.closed {
+		return libraryAction{}, errLibrarySessionNotFound
+	}
+	if version != s.state.Version {
+		return libraryAction{}, errGameStaleVersion
+	}
+	next := s.state.clone()
+	action, err := next.apply(player, m)
+	if err != nil {
+		return libraryAction{}, err
+	}
+	s.state = next
+	action.Name = name
+	action.At = now
+	s.log = append(s.log, action)
+	if len(s.log) > gameActionLogLimit {
+		s.log = append([]libraryAction(nil), s.log[len(s.log)-gameActionLogLimit:]...)
+	}
+	s.updatedAt = now
+	return action, nil
+}
+
+func (s *librarySession) mutate(version uint64, player string, m libraryMutation, name string, now time.Time) (libraryAction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mutateLocked(version, player, m, name, now)
+}
+
+// addConn registers deviceID's connection for player, or as a spectator when
+// player is empty. A player seat accepts one connection at a time and only
+// from the device that first claimed it.
+func (s *librarySession) addConn(conn *websocket.Conn, player, deviceID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false, errLibrarySessionNotFound
+	}
+	if player != "" {
+		if claimed, ok := s.seatDevices[player]; ok && claimed != deviceID {
+			return false, errLibrarySeatClaimed
+		}
+		for _, occupant := range s.clients {
+			if occupant == player {
+				return false, nil
+			}
+		}
+		s.seatDevices[player] = deviceID
+	}
+	s.clients[conn] = player
+	return true, nil
+}
+
+func (s *librarySession) removeConn(conn *websocket.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.clients, conn)
+}
+
+func (s *librarySession) writeToConn(conn *websocket.Conn, msg libraryWSMessage) {
+	if err := conn.WriteJSON(msg); err != nil {
+		_ = conn.Close()
+	}
+}
+
+func (s *librarySession) sendState(conn *websocket.Conn, player string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	view := s.state.view(player)
+	s.writeToConn(conn, libraryWSMessage{Type: gameWSTypeState, View: &view, Log: append([]libraryAction{}, s.log...)})
+}
+
+func (s *librarySession) handleMutate(conn *websocket.Conn, player string, msg libraryWSMessage, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if msg.Version == nil || msg.Mutation == nil {
+		s.writeToConn(conn, libraryWSMessage{Type: gameWSTypeError, Error: "missing mutation fields", Code: gameCodeInvalidMessage, RequestID: msg.RequestID})
+		return
+	}
+	action, err := s.mutateLocked(*msg.Version, player, *msg.Mutation, name, time.Now())
+	if err != nil {
+		view := s.state.view(player)
+		code := gameCodeRejected
+		if errors.Is(err, errGameStaleVersion) {
+			code = gameCodeStaleVersion
+		}
+		s.writeToConn(conn, libraryWSMessage{Type: gameWSTypeError, Error: err.Error(), Code: code, RequestID: msg.RequestID, View: &view})
+		return
+	}
+	for client, clientPlayer := range s.clients {
+		view := s.state.view(clientPlayer)
+		s.writeToConn(client, libraryWSMessage{Type: gameWSTypeAction, Action: &action, View: &view, RequestID: msg.RequestID})
+	}
+}
+
+func (s *librarySession) sessionView(requesterDeviceID string) librarySessionView {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	occupied := make([]string, 0, 2)
+	for _, player := range s.clients {
+		if player != "" {
+			occupied = append(occupied, player)
+		}
+	}
+	sort.Strings(occupied)
+	return librarySessionView{
+		SessionID:      s.id,
+		OwnedByRequest: requesterDeviceID != "" && requesterDeviceID == s.ownerDeviceID,
+		OccupiedSeats:  occupied,
+		View:           s.state.view(""),
+		Log:            append([]libraryAction{}, s.log...),
+		CreatedAt:      s.createdAt,
+		UpdatedAt:      s.updatedAt,
+	}
+}
+
+// handleLibrarySessions serves GET (public view by session_id), POST (create
+// from a shared battlebox deck) and DELETE (owner only) on /api/game/libraries.
+func (h *gameHub) handleLibrarySessions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodPost, http.MethodDelete:
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	requesterDeviceID, err := requesterDeviceIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		defer r.Body.Close()
+		var req createLibrarySessionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid json body", http.StatusBadRequest)
+			return
+		}
+		deckSlug := normalizeSlug(req.DeckSlug)
+		if deckSlug == "" {
+			http.Error(w, "deck_slug required", http.StatusBadRequest)
+			return
+		}
+		// The seed fixes every hidden card, so it never comes from a client.
+		session, err := h.createLibrary(requesterDeviceID, deckSlug, randomDraftSeed(), time.Now())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(session.sessionView(requesterDeviceID))
+		return
+	}
+
+	sessionID := r.URL.Query().Get("session_id")
+	if sessionID == "" {
+		http.Error(w, "session_id query param required", http.StatusBadRequest)
+		return
+	}
+	if r.Method == http.MethodDelete {
+		if err := h.deleteLibrary(r.Context(), sessionID, requesterDeviceID); err != nil {
+			switch {
+			case errors.Is(err, errLibrarySessionNotFound):
+				http.Error(w, "library session not found", http.StatusNotFound)
+			case errors.Is(err, errDraftRoomForbidden):
+				http.Error(w, "only the creator may delete this library session", http.StatusForbidden)
+			default:
+				http.Error(w, "failed to delete library session", http.StatusInternalServerError)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	session := h.library(sessionID)
+	if session == nil {
+		http.Error(w, "library session not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(session.sessionView(requesterDeviceID))
+}
+
+// handleLibraryWS serves GET /api/game/library/ws?session=<id>&player=p1|p2&name=&device_id=.
+// Omitting player joins as a spectator; players need a device id, which binds
+// the seat on first connect.
+func (h *gameHub) handleLibraryWS(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.URL.Query().Get("session")
+	player := r.URL.Query().Get("player")
+	name := normalizeHostName(r.URL.Query().Get("name"))
+	if player != "" && player != "p1" && player != "p2" {
+		http.Error(w, "invalid player", http.StatusBadRequest)
+		return
+	}
+	var deviceID string
+	if player != "" {
+		var err error
+		deviceID, err = requesterDeviceIDFromRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	session := h.library(sessionID)
+	if session == nil {
+		_ = conn.WriteJSON(libraryWSMessage{Type: gameWSTypeError, Error: "Library session not found", Code: gameCodeMissing})
+		return
+	}
+	accepted, err := session.addConn(conn, player, deviceID)
+	if errors.Is(err, errLibrarySeatClaimed) {
+		_ = conn.WriteJSON(libraryWSMessage{Type: gameWSTypeError, Error: "Seat belongs to another device", Code: gameCodeSeatClaimed})
+		return
+	}
+	if err != nil {
+		_ = conn.WriteJSON(libraryWSMessage{Type: gameWSTypeError, Error: "Library session not found", Code: gameCodeMissing})
+		return
+	}
+	if !accepted {
+		_ = conn.WriteJSON(libraryWSMessage{Type: gameWSTypeError, Error: "Seat already occupied", Code: gameCodeSeatOccupied})
+		return
+	}
+	defer session.removeConn(conn)
+	if player != "" {
+		h.saveLibrary(r.Context(), session)
+	}
+
+	session.sendState(conn, player)
+	for {
+		var msg libraryWSMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		switch msg.Type {
+		case gameWSTypeState:
+			session.sendState(conn, player)
+		case gameWSTypeMutate:
+			session.handleMutate(conn, player, msg, name)
+		default:
+			continue
+		}
+	}
+}
+
+func (s *librarySession) snapshotRecord() librarySessionRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return librarySessionRecord{
+		SessionID: s.id,
+		Snapshot: librarySessionSnapshot{
+			OwnerDeviceID: s.ownerDeviceID,
+			CreatedAt:     s.createdAt,
+			UpdatedAt:     s.updatedAt,
+			State:         s.state.clone(),
+			Log:           append([]libraryAction(nil), s.log...),
+			SeatDevices:   maps.Clone(s.seatDevices),
+		},
+	}
+}
+
+// saveLibrary writes one session right away, as after a seat claim, and logs
+// failures; the snapshot loop retries. Unchanged sessions are skipped.
+func (h *gameHub) saveLibrary(ctx context.Context, session *librarySession) {
+	h.mu.RLock()
+	store := h.store
+	h.mu.RUnlock()
+	if store == nil {
+		return
+	}
+	if _, err := store.SaveLibrarySessions(ctx, []librarySessionRecord{session.snapshotRecord()}); err != nil {
+		log.Printf("Failed to save library session %s: %v", session.id, err)
+	}
+}
+
+// snapshotAndSaveLibraries writes library sessions whose version or seat claims
+// changed since the last save and returns how many were written.
+func (h *gameHub) snapshotAndSaveLibraries(ctx context.Context) (int, error) {
+	h.mu.RLock()
+	store := h.store
+	records := make([]librarySessionRecord, 0, len(h.libraries))
+	for _, session := range h.libraries {
+		records = append(records, session.snapshotRecord())
+	}
+	h.mu.RUnlock()
+	if store == nil {
+		return 0, errors.New("game session store not initialized")
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].SessionID < records[j].SessionID
+	})
+	return store.SaveLibrarySessions(ctx, records)
+}
+
+func (h *gameHub) restoreLibraries(records []librarySessionRecord) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, record := range records {
+		if record.SessionID == "" {
+			return errors.New("cannot restore library session with empty id")
+		}
+		snapshot := record.Snapshot
+		state := snapshot.State
+		for _, seat := range []*librarySeat{&state.P1, &state.P2} {
+			if seat.Hand == nil {
+				seat.Hand = []libraryCard{}
+			}
+			if seat.Graveyard == nil {
+				seat.Graveyard = []libraryCard{}
+			}
+			if len(seat.Scry) > len(state.Library) {
+				seat.Scry = nil
+			}
+		}
+		createdAt := snapshot.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+		updatedAt := snapshot.UpdatedAt
+		if updatedAt.IsZero() {
+			updatedAt = createdAt
+		}
+		log := snapshot.Log
+		if log == nil {
+			log = []libraryAction{}
+		}
+		seatDevices := snapshot.SeatDevices
+		if seatDevices == nil {
+			seatDevices = make(map[string]string)
+		}
+		h.libraries[record.SessionID] = &librarySession{
+			id:            record.SessionID,
+			ownerDeviceID: snapshot.OwnerDeviceID,
+			state:         state,
+			log:           log,
+			clients:       make(map[*websocket.Conn]string),
+			seatDevices:   seatDevices,
+			createdAt:     createdAt,
+			updatedAt:     updatedAt,
+		}
+	}
+	return nil
+}
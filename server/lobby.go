@@ -6,12 +6,15 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"sort"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/lxing/battlebox/draftproto"
 )
 
 type draftHub struct {
@@ -19,33 +22,61 @@ type draftHub struct {
 	rooms     map[string]*draftRoom
 	lobbySubs map[chan struct{}]struct{}
 	roomStore *draftRoomStore
+
+	lobbyEpoch      string           // distinguishes event ids across server restarts
+	lobbySeq        uint64           // last lobby event sequence number
+	lobbyTombstones []lobbyTombstone // recent room deletions, oldest first
+	lobbyFloor      uint64           // oldest sequence a Last-Event-ID may resume from
+	webhooks        *webhookDispatcher
+
+	eventsMu sync.Mutex
+	events   map[string]*draftEventRecord
+
+	schedulesMu sync.Mutex
+	schedules   map[string]*draftScheduleRecord
 }
 
 type draftRoom struct {
 	id            string
 	deckSlug      string
 	ownerDeviceID string
+	hostName      string
 	closed        bool
-
-	mu      sync.Mutex
-	draft   *Draft
-	clients map[int]map[*websocket.Conn]struct{}
+	lobbySeq      uint64 // lobby sequence of the last change, guarded by draftHub.mu
+	createdSeq    uint64 // lobby sequence of the first change, guarded by draftHub.mu
+	webhookState  string // last state announced to webhooks, guarded by draftHub.mu
+	statsSeq      uint64 // draft global seq when pick stats were last recorded, guarded by draftHub.mu
+
+	mu        sync.Mutex
+	draft     *Draft
+	clients   map[int]map[*websocket.Conn]struct{}
+	createdAt time.Time
+	updatedAt time.Time
+	// overlayTokens authorize each seat's stream overlay feed. They are issued
+	// lazily and not persisted, so a restart invalidates them.
+	overlayTokens map[int]string
 }
 
 type draftRoomSummary struct {
-	RoomID         string `json:"room_id"`
-	DeckSlug       string `json:"deck_slug,omitempty"`
-	SeatCount      int    `json:"seat_count"`
-	PackCount      int    `json:"pack_count"`
-	PackSize       int    `json:"pack_size"`
-	State          string `json:"state"`
-	PackNo         int    `json:"pack_no"`
-	PickNo         int    `json:"pick_no"`
-	ExpectedPicks  int    `json:"expected_picks"`
-	OwnedByRequest bool   `json:"owned_by_requester"`
-	ConnectedSeats int    `json:"connected_seats"`
-	Connections    int    `json:"connections"`
-	OccupiedSeats  []int  `json:"occupied_seats"`
+	RoomID         string    `json:"room_id"`
+	DeckSlug       string    `json:"deck_slug,omitempty"`
+	SeatCount      int       `json:"seat_count"`
+	PackCount      int       `json:"pack_count"`
+	PackSize       int       `json:"pack_size"`
+	State          string    `json:"state"`
+	PackNo         int       `json:"pack_no"`
+	PickNo         int       `json:"pick_no"`
+	ExpectedPicks  int       `json:"expected_picks"`
+	OwnedByRequest bool      `json:"owned_by_requester"`
+	ConnectedSeats int       `json:"connected_seats"`
+	Connections    int       `json:"connections"`
+	OccupiedSeats  []int     `json:"occupied_seats"`
+	TeamDraft      bool      `json:"team_draft,omitempty"`
+	HostName       string    `json:"host_name,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	// Seed is only revealed to the room owner once the draft is done.
+	Seed *uint64 `json:"seed,omitempty"`
 }
 
 type listDraftRoomsResponse struct {
@@ -57,8 +88,11 @@ var errDraftRoomForbidden = errors.New("forbidden")
 
 func newDraftHub() *draftHub {
 	return &draftHub{
-		rooms:     make(map[string]*draftRoom),
-		lobbySubs: make(map[chan struct{}]struct{}),
+		rooms:      make(map[string]*draftRoom),
+		lobbySubs:  make(map[chan struct{}]struct{}),
+		lobbyEpoch: strconv.FormatInt(time.Now().UnixNano(), 36),
+		events:     make(map[string]*draftEventRecord),
+		schedules:  make(map[string]*draftScheduleRecord),
 	}
 }
 
@@ -86,6 +120,11 @@ func (h *draftHub) deleteRoom(ctx context.Context, roomID, requesterDeviceID str
 		h.mu.Unlock()
 		return errDraftRoomForbidden
 	}
+	store := h.roomStore
+	stats, hasStats, err := h.roomPickStatsLocked(room)
+	if err != nil {
+		log.Printf("Failed to record pick stats for room %s: %v", roomID, err)
+	}
 	room.mu.Lock()
 	room.closed = true
 	room.mu.Unlock()
@@ -98,8 +137,13 @@ func (h *draftHub) deleteRoom(ctx context.Context, roomID, requesterDeviceID str
 	delete(h.rooms, roomID)
 	h.mu.Unlock()
 
+	if hasStats {
+		if err := h.writeRoomPickStats(ctx, store, stats); err != nil {
+			log.Printf("Failed to record pick stats for room %s: %v", roomID, err)
+		}
+	}
 	room.closeAllConnections()
-	h.notifyLobbySubscribers()
+	h.notifyLobbySubscribers(roomID)
 	return nil
 }
 
@@ -116,17 +160,28 @@ func (h *draftHub) ownerAlreadyHasRoomLocked(deviceID string) bool {
 }
 
 func (h *draftHub) listRoomSummaries(requesterDeviceID string) []draftRoomSummary {
+	rooms, _ := h.lobbyView(requesterDeviceID, lobbyFilter{})
+	return rooms
+}
+
+// lobbyView returns the filtered room summaries together with the lobby sequence
+// they reflect, so event streams can continue from exactly that point.
+func (h *draftHub) lobbyView(requesterDeviceID string, filter lobbyFilter) ([]draftRoomSummary, uint64) {
 	h.mu.RLock()
 	rooms := make([]draftRoomSummary, 0, len(h.rooms))
 	for _, room := range h.rooms {
-		rooms = append(rooms, room.summary(requesterDeviceID))
+		summary := room.summary(requesterDeviceID)
+		if filter.matches(summary) {
+			rooms = append(rooms, summary)
+		}
 	}
+	seq := h.lobbySeq
 	h.mu.RUnlock()
 
 	sort.Slice(rooms, func(i, j int) bool {
 		return rooms[i].RoomID < rooms[j].RoomID
 	})
-	return rooms
+	return rooms, seq
 }
 
 func (h *draftHub) handleListRooms(w http.ResponseWriter, r *http.Request) {
@@ -135,10 +190,20 @@ func (h *draftHub) handleListRooms(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	filter, err := lobbyFilterFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rooms, _ := h.lobbyView(requesterDeviceID, filter)
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(listDraftRoomsResponse{Rooms: h.listRoomSummaries(requesterDeviceID)})
+	_ = json.NewEncoder(w).Encode(listDraftRoomsResponse{Rooms: rooms})
 }
 
+// handleLobbyEvents streams the lobby as SSE. A fresh connection gets the filtered
+// room list as an unnamed event, then typed room_created/room_updated/room_deleted
+// events carrying only the changed room. Reconnects with Last-Event-ID resume from
+// the missed events when they are still retained, and fall back to the full list otherwise.
 func (h *draftHub) handleLobbyEvents(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		w.Header().Set("Allow", "GET")
@@ -150,7 +215,51 @@ func (h *draftHub) handleLobbyEvents(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	filter, err := lobbyFilterFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
+	stream := &lobbyStream{
+		hub:               h,
+		requesterDeviceID: requesterDeviceID,
+		filter:            filter,
+		lastEventID:       r.Header.Get("Last-Event-ID"),
+	}
+	h.serveEvents(w, r, stream.next)
+}
+
+// sseFrame is one server-sent event; ID and Event are omitted when empty.
+type sseFrame struct {
+	ID    string
+	Event string
+	Data  any
+}
+
+func writeSSEFrame(w io.Writer, frame sseFrame) error {
+	data, err := json.Marshal(frame.Data)
+	if err != nil {
+		return err
+	}
+	if frame.ID != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", frame.ID); err != nil {
+			return err
+		}
+	}
+	if frame.Event != "" {
+		if _, err := fmt.Fprintf(w, "event: %s\n", frame.Event); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+	return err
+}
+
+// serveEvents streams the frames returned by next: once on connect and again whenever
+// lobby subscribers are notified, with keepalive pings in between. The stream ends when
+// next fails or the client goes away.
+func (h *draftHub) serveEvents(w http.ResponseWriter, r *http.Request, next func() ([]sseFrame, error)) {
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
@@ -171,19 +280,21 @@ func (h *draftHub) handleLobbyEvents(w http.ResponseWriter, r *http.Request) {
 		h.mu.Unlock()
 	}()
 
-	writeRooms := func() bool {
-		payload, err := json.Marshal(listDraftRoomsResponse{Rooms: h.listRoomSummaries(requesterDeviceID)})
+	writeFrames := func() bool {
+		frames, err := next()
 		if err != nil {
 			return false
 		}
-		if _, err := fmt.Fprintf(w, "data: %s\n\n", payload); err != nil {
-			return false
+		for _, frame := range frames {
+			if err := writeSSEFrame(w, frame); err != nil {
+				return false
+			}
 		}
 		flusher.Flush()
 		return true
 	}
 
-	if !writeRooms() {
+	if !writeFrames() {
 		return
 	}
 
@@ -200,16 +311,51 @@ func (h *draftHub) handleLobbyEvents(w http.ResponseWriter, r *http.Request) {
 			}
 			flusher.Flush()
 		case <-sub:
-			if !writeRooms() {
+			if !writeFrames() {
 				return
 			}
 		}
 	}
 }
 
-func (h *draftHub) notifyLobbySubscribers() {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+// notifyLobbySubscribers records a lobby change for roomID and wakes every event
+// stream. A room missing from the hub is recorded as deleted.
+//
+// It also drives outgoing webhooks: the room's state is compared with the last
+// one announced, and created/started/completed events are dispatched after the
+// hub lock is released.
+func (h *draftHub) notifyLobbySubscribers(roomID string) {
+	h.mu.Lock()
+	var webhookEvents []webhookPayload
+	dispatcher := h.webhooks
+	defer func() {
+		h.mu.Unlock()
+		for _, payload := range webhookEvents {
+			dispatcher.dispatch(payload)
+		}
+	}()
+
+	h.lobbySeq++
+	if room := h.rooms[roomID]; room != nil {
+		if room.createdSeq == 0 {
+			room.createdSeq = h.lobbySeq
+		}
+		room.lobbySeq = h.lobbySeq
+		now := time.Now()
+		room.mu.Lock()
+		room.updatedAt = now
+		room.mu.Unlock()
+		if dispatcher != nil {
+			webhookEvents = room.roomWebhookEventsLocked(now)
+		}
+	} else {
+		h.lobbyTombstones = append(h.lobbyTombstones, lobbyTombstone{seq: h.lobbySeq, roomID: roomID})
+		if len(h.lobbyTombstones) > lobbyTombstoneLimit {
+			h.lobbyFloor = h.lobbyTombstones[0].seq
+			h.lobbyTombstones = append([]lobbyTombstone(nil), h.lobbyTombstones[1:]...)
+		}
+	}
+
 	for ch := range h.lobbySubs {
 		select {
 		case ch <- struct{}{}:
@@ -276,10 +422,29 @@ func (r *draftRoom) sendSeatState(seat int, conn *websocket.Conn) {
 	defer r.mu.Unlock()
 	state, err := r.draft.PlayerState(seat)
 	if err != nil {
-		r.writeToConn(conn, draftWSMessage{Type: "error", Error: err.Error()})
+		r.writeToConn(conn, wsErrorMessage(err))
 		return
 	}
-	r.writeToConn(conn, draftWSMessage{Type: "state", State: &state})
+	r.writeToConn(conn, draftWSMessage{Type: draftproto.TypeState, State: &state})
+}
+
+// handleHello answers the protocol handshake. It returns false when the client
+// speaks an unsupported version and the connection should be closed.
+func (r *draftRoom) handleHello(conn *websocket.Conn, msg draftWSMessage) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if msg.ProtocolVersion != draftproto.ProtocolVersion {
+		r.writeToConn(conn, draftWSMessage{
+			Type:            draftproto.TypeError,
+			Error:           fmt.Sprintf("unsupported protocol version %d", msg.ProtocolVersion),
+			Code:            draftproto.CodeUnsupportedProtocol,
+			ProtocolVersion: draftproto.ProtocolVersion,
+		})
+		return false
+	}
+	r.writeToConn(conn, draftWSMessage{Type: draftproto.TypeHello, ProtocolVersion: draftproto.ProtocolVersion})
+	return true
 }
 
 func (r *draftRoom) handlePick(seat int, conn *websocket.Conn, msg draftWSMessage) bool {
@@ -289,23 +454,23 @@ func (r *draftRoom) handlePick(seat int, conn *websocket.Conn, msg draftWSMessag
 	defer r.mu.Unlock()
 
 	if msg.Seq == 0 || msg.PackID == "" {
-		r.writeToConn(conn, draftWSMessage{Type: "error", Error: "missing pick fields"})
+		r.writeToConn(conn, draftWSMessage{Type: draftproto.TypeError, Error: "missing pick fields", Code: draftproto.CodeInvalidMessage})
 		return false
 	}
 	picks := append([]PickSelection(nil), msg.Picks...)
 	if len(picks) == 0 {
-		r.writeToConn(conn, draftWSMessage{Type: "error", Error: "missing pick fields"})
+		r.writeToConn(conn, draftWSMessage{Type: draftproto.TypeError, Error: "missing pick fields", Code: draftproto.CodeInvalidMessage})
 		return false
 	}
 
 	result, err := r.draft.PickBatch(seat, msg.Seq, msg.PackID, picks)
 	if err != nil {
-		r.writeToConn(conn, draftWSMessage{Type: "error", Error: err.Error()})
+		r.writeToConn(conn, wsErrorMessage(err))
 		return false
 	}
 
 	r.writeToConn(conn, draftWSMessage{
-		Type:      "pick_accepted",
+		Type:      draftproto.TypePickAccepted,
 		State:     &result.State,
 		Duplicate: result.Duplicate,
 	})
@@ -319,16 +484,18 @@ func (r *draftRoom) handlePick(seat int, conn *websocket.Conn, msg draftWSMessag
 		case RoundAdvanced:
 			roundAdvanced = true
 			r.broadcast(draftWSMessage{
-				Type:   "round_advanced",
+				Type:   draftproto.TypeRoundAdvanced,
 				PackNo: evt.PackNumber,
 				PickNo: evt.PickNumber,
 			})
 		case DraftCompleted:
-			r.broadcast(draftWSMessage{Type: "draft_completed"})
+			r.broadcast(draftWSMessage{Type: draftproto.TypeDraftCompleted})
 		}
 	}
 	if roundAdvanced {
 		r.broadcastSeatStates()
+	} else {
+		r.broadcastTeammateStates(seat)
 	}
 	return true
 }
@@ -340,21 +507,24 @@ func (r *draftRoom) handleMovePick(seat int, conn *websocket.Conn, msg draftWSMe
 	defer r.mu.Unlock()
 
 	if msg.Seq == 0 || msg.CardName == "" || msg.FromZone == "" || msg.ToZone == "" {
-		r.writeToConn(conn, draftWSMessage{Type: "error", Error: "missing move fields"})
+		r.writeToConn(conn, draftWSMessage{Type: draftproto.TypeError, Error: "missing move fields", Code: draftproto.CodeInvalidMessage})
 		return
 	}
 
 	result, err := r.draft.MovePick(seat, msg.Seq, msg.CardName, msg.FromZone, msg.ToZone)
 	if err != nil {
-		r.writeToConn(conn, draftWSMessage{Type: "error", Error: err.Error()})
+		r.writeToConn(conn, wsErrorMessage(err))
 		return
 	}
 
 	r.writeToConn(conn, draftWSMessage{
-		Type:      "move_accepted",
+		Type:      draftproto.TypeMoveAccepted,
 		State:     &result.State,
 		Duplicate: result.Duplicate,
 	})
+	if !result.Duplicate {
+		r.broadcastTeammateStates(seat)
+	}
 }
 
 func (r *draftRoom) handleSetBasics(seat int, conn *websocket.Conn, msg draftWSMessage) {
@@ -364,21 +534,107 @@ func (r *draftRoom) handleSetBasics(seat int, conn *websocket.Conn, msg draftWSM
 	defer r.mu.Unlock()
 
 	if msg.Seq == 0 || len(msg.Basics) == 0 {
-		r.writeToConn(conn, draftWSMessage{Type: "error", Error: "missing basics fields"})
+		r.writeToConn(conn, draftWSMessage{Type: draftproto.TypeError, Error: "missing basics fields", Code: draftproto.CodeInvalidMessage})
 		return
 	}
 
 	result, err := r.draft.SetBasics(seat, msg.Seq, msg.Basics)
 	if err != nil {
-		r.writeToConn(conn, draftWSMessage{Type: "error", Error: err.Error()})
+		r.writeToConn(conn, wsErrorMessage(err))
+		return
+	}
+
+	r.writeToConn(conn, draftWSMessage{
+		Type:      draftproto.TypeSetBasicsAccepted,
+		State:     &result.State,
+		Duplicate: result.Duplicate,
+	})
+	if !result.Duplicate {
+		r.broadcastTeammateStates(seat)
+	}
+}
+
+func (r *draftRoom) handleRegisterDeck(seat int, conn *websocket.Conn, msg draftWSMessage) {
+	// TODO(remote-draft): avoid holding room mutex while writing to sockets.
+	// Move to per-connection outbound queues so slow clients cannot stall picks.
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if msg.Seq == 0 || len(msg.Cards) == 0 {
+		r.writeToConn(conn, draftWSMessage{Type: draftproto.TypeError, Error: "missing deck fields", Code: draftproto.CodeInvalidMessage})
+		return
+	}
+
+	result, err := r.draft.RegisterDeck(seat, msg.Seq, msg.Cards)
+	if err != nil {
+		r.writeToConn(conn, wsErrorMessage(err))
 		return
 	}
 
 	r.writeToConn(conn, draftWSMessage{
-		Type:      "set_basics_accepted",
+		Type:      draftproto.TypeRegisterDeckAccepted,
 		State:     &result.State,
 		Duplicate: result.Duplicate,
 	})
+	if !result.Duplicate {
+		r.broadcastTeammateStates(seat)
+	}
+}
+
+func (r *draftRoom) handleSuggestBasics(seat int, conn *websocket.Conn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	manaCosts := cardManaCosts.ManaCosts(r.draft.Seats[seat].Picks.Mainboard)
+	basics, err := r.draft.SuggestBasics(seat, manaCosts)
+	if err != nil {
+		r.writeToConn(conn, wsErrorMessage(err))
+		return
+	}
+	r.writeToConn(conn, draftWSMessage{Type: draftproto.TypeBasicsSuggested, Basics: basics})
+}
+
+func (r *draftRoom) handleRetractPick(seat int, conn *websocket.Conn, msg draftWSMessage) bool {
+	// TODO(remote-draft): avoid holding room mutex while writing to sockets.
+	// Move to per-connection outbound queues so slow clients cannot stall picks.
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if msg.Seq == 0 || msg.PackID == "" {
+		r.writeToConn(conn, draftWSMessage{Type: draftproto.TypeError, Error: "missing retract fields", Code: draftproto.CodeInvalidMessage})
+		return false
+	}
+
+	result, err := r.draft.RetractPick(seat, msg.Seq, msg.PackID)
+	if err != nil {
+		r.writeToConn(conn, wsErrorMessage(err))
+		return false
+	}
+
+	r.writeToConn(conn, draftWSMessage{
+		Type:      draftproto.TypeRetractAccepted,
+		State:     &result.State,
+		Duplicate: result.Duplicate,
+	})
+	if result.Duplicate {
+		return false
+	}
+	// The retracted cards are a hidden pick: only the retracting seat sees
+	// them, and every other seat just gets its refreshed state.
+	for _, event := range result.Events {
+		if evt, ok := event.(PickRetracted); ok {
+			msg := draftWSMessage{
+				Type:   draftproto.TypePickRetracted,
+				PackID: evt.PackID,
+				Cards:  evt.Cards,
+			}
+			for seatConn := range r.clients[seat] {
+				r.writeToConn(seatConn, msg)
+			}
+		}
+	}
+	r.broadcastOtherSeatStates(seat)
+	return true
 }
 
 func (r *draftRoom) hasOccupiedOtherSeatsLocked(requesterSeat int) bool {
@@ -436,18 +692,22 @@ func (r *draftRoom) handleBotPick(requesterSeat int) bool {
 			case RoundAdvanced:
 				roundAdvanced = true
 				r.broadcast(draftWSMessage{
-					Type:   "round_advanced",
+					Type:   draftproto.TypeRoundAdvanced,
 					PackNo: evt.PackNumber,
 					PickNo: evt.PickNumber,
 				})
 			case DraftCompleted:
-				r.broadcast(draftWSMessage{Type: "draft_completed"})
+				r.broadcast(draftWSMessage{Type: draftproto.TypeDraftCompleted})
 			}
 		}
 	}
 
 	if roundAdvanced {
 		r.broadcastSeatStates()
+	} else {
+		for _, targetSeat := range targetSeats {
+			r.broadcastTeammateStates(targetSeat)
+		}
 	}
 	return changed
 }
@@ -457,11 +717,47 @@ func (r *draftRoom) broadcastSeatStates() {
 		state, err := r.draft.PlayerState(seat)
 		if err != nil {
 			for conn := range conns {
-				r.writeToConn(conn, draftWSMessage{Type: "error", Error: err.Error()})
+				r.writeToConn(conn, wsErrorMessage(err))
 			}
 			continue
 		}
-		msg := draftWSMessage{Type: "state", State: &state}
+		msg := draftWSMessage{Type: draftproto.TypeState, State: &state}
+		for conn := range conns {
+			r.writeToConn(conn, msg)
+		}
+	}
+}
+
+// broadcastOtherSeatStates pushes fresh state to every seat except seat.
+func (r *draftRoom) broadcastOtherSeatStates(seat int) {
+	for other, conns := range r.clients {
+		if other == seat || len(conns) == 0 {
+			continue
+		}
+		state, err := r.draft.PlayerState(other)
+		if err != nil {
+			continue
+		}
+		msg := draftWSMessage{Type: draftproto.TypeState, State: &state}
+		for conn := range conns {
+			r.writeToConn(conn, msg)
+		}
+	}
+}
+
+// broadcastTeammateStates pushes fresh state to a seat's teammates so team drafts
+// see each other's picks live. It is a no-op outside team drafts.
+func (r *draftRoom) broadcastTeammateStates(seat int) {
+	for _, teammate := range r.draft.teammates(seat) {
+		conns := r.clients[teammate]
+		if len(conns) == 0 {
+			continue
+		}
+		state, err := r.draft.PlayerState(teammate)
+		if err != nil {
+			continue
+		}
+		msg := draftWSMessage{Type: draftproto.TypeState, State: &state}
 		for conn := range conns {
 			r.writeToConn(conn, msg)
 		}
@@ -502,6 +798,13 @@ func (r *draftRoom) summary(requesterDeviceID string) draftRoomSummary {
 	}
 	sort.Ints(occupiedSeats)
 
+	ownedByRequest := requesterDeviceID != "" && requesterDeviceID == r.ownerDeviceID
+	var seed *uint64
+	if ownedByRequest && r.draft.State() == "done" {
+		revealed := r.draft.Config.Seed
+		seed = &revealed
+	}
+
 	return draftRoomSummary{
 		RoomID:         r.id,
 		DeckSlug:       r.deckSlug,
@@ -512,9 +815,14 @@ func (r *draftRoom) summary(requesterDeviceID string) draftRoomSummary {
 		PackNo:         r.draft.Progress.PackNumber,
 		PickNo:         r.draft.currentPickNo(),
 		ExpectedPicks:  r.draft.picksThisPass(),
-		OwnedByRequest: requesterDeviceID != "" && requesterDeviceID == r.ownerDeviceID,
+		OwnedByRequest: ownedByRequest,
 		ConnectedSeats: connectedSeats,
 		Connections:    connections,
 		OccupiedSeats:  occupiedSeats,
+		TeamDraft:      r.draft.Config.TeamDraft,
+		HostName:       r.hostName,
+		CreatedAt:      r.createdAt,
+		UpdatedAt:      r.updatedAt,
+		Seed:           seed,
 	}
 }
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// lobbyTombstoneLimit bounds how many deletions are retained for Last-Event-ID resume.
+const lobbyTombstoneLimit = 256
+
+const (
+	lobbyEventRoomCreated = "room_created"
+	lobbyEventRoomUpdated = "room_updated"
+	lobbyEventRoomDeleted = "room_deleted"
+)
+
+type lobbyTombstone struct {
+	seq    uint64
+	roomID string
+}
+
+// lobbyRoomEvent is the payload of room_created and room_updated events.
+type lobbyRoomEvent struct {
+	Room draftRoomSummary `json:"room"`
+}
+
+// lobbyRoomDeletedEvent is the payload of room_deleted events. A room that stops
+// matching a stream's filter is reported as deleted on that stream.
+type lobbyRoomDeletedEvent struct {
+	RoomID string `json:"room_id"`
+}
+
+// lobbyFilter narrows lobby lists and streams. Zero values match everything.
+type lobbyFilter struct {
+	deckSlug string
+	state    string
+	owned    *bool
+}
+
+func lobbyFilterFromRequest(r *http.Request) (lobbyFilter, error) {
+	q := r.URL.Query()
+	filter := lobbyFilter{}
+	if raw := strings.TrimSpace(q.Get("deck_slug")); raw != "" {
+		filter.deckSlug = normalizeSlug(raw)
+		if filter.deckSlug == "" {
+			return lobbyFilter{}, errors.New("invalid deck_slug filter")
+		}
+	}
+	switch state := strings.TrimSpace(q.Get("state")); state {
+	case "", "waiting", "drafting", "done":
+		filter.state = state
+	default:
+		return lobbyFilter{}, errors.New("state filter must be waiting, drafting or done")
+	}
+	if raw := strings.TrimSpace(q.Get("owned")); raw != "" {
+		owned, err := strconv.ParseBool(raw)
+		if err != nil {
+			return lobbyFilter{}, errors.New("owned filter must be true or false")
+		}
+		filter.owned = &owned
+	}
+	return filter, nil
+}
+
+func (f lobbyFilter) matches(summary draftRoomSummary) bool {
+	if f.deckSlug != "" && summary.DeckSlug != f.deckSlug {
+		return false
+	}
+	if f.state != "" && summary.State != f.state {
+		return false
+	}
+	if f.owned != nil && summary.OwnedByRequest != *f.owned {
+		return false
+	}
+	return true
+}
+
+func (h *draftHub) lobbyEventID(seq uint64) string {
+	return fmt.Sprintf("%s-%d", h.lobbyEpoch, seq)
+}
+
+// resumeSeqLocked parses a Last-Event-ID and reports whether every event after it
+// is still retained. Callers hold h.mu.
+func (h *draftHub) resumeSeqLocked(lastEventID string) (uint64, bool) {
+	epoch, rawSeq, ok := strings.Cut(lastEventID, "-")
+	if !ok || epoch != h.lobbyEpoch {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(rawSeq, 10, 64)
+	if err != nil || seq < h.lobbyFloor || seq > h.lobbySeq {
+		return 0, false
+	}
+	return seq, true
+}
+
+// lobbyStream is one subscriber's position in the lobby event sequence.
+type lobbyStream struct {
+	hub               *draftHub
+	requesterDeviceID string
+	filter            lobbyFilter
+	lastEventID       string
+
+	started bool
+	cursor  uint64
+	known   map[string]bool // rooms the client may hold; deletions are only sent for these
+}
+
+type lobbyChange struct {
+	seq    uint64
+	roomID string
+	room   *draftRoom // nil for deletions
+}
+
+func (s *lobbyStream) next() ([]sseFrame, error) {
+	h := s.hub
+	if !s.started {
+		s.started = true
+		h.mu.RLock()
+		seq, resumed := h.resumeSeqLocked(s.lastEventID)
+		if resumed {
+			// The client's list reflects seq: it may hold any room that existed then,
+			// plus rooms deleted since.
+			s.cursor = seq
+			s.known = map[string]bool{}
+			for id, room := range h.rooms {
+				if room.createdSeq <= seq {
+					s.known[id] = true
+				}
+			}
+			for _, tombstone := range h.lobbyTombstones {
+				if tombstone.seq > seq {
+					s.known[tombstone.roomID] = true
+				}
+			}
+		}
+		h.mu.RUnlock()
+		if !resumed {
+			rooms, seq := h.lobbyView(s.requesterDeviceID, s.filter)
+			s.cursor = seq
+			s.known = make(map[string]bool, len(rooms))
+			for _, room := range rooms {
+				s.known[room.RoomID] = true
+			}
+			return []sseFrame{{ID: h.lobbyEventID(seq), Data: listDraftRoomsResponse{Rooms: rooms}}}, nil
+		}
+	}
+
+	h.mu.RLock()
+	changes := make([]lobbyChange, 0)
+	for id, room := range h.rooms {
+		if room.lobbySeq > s.cursor {
+			changes = append(changes, lobbyChange{seq: room.lobbySeq, roomID: id, room: room})
+		}
+	}
+	for _, tombstone := range h.lobbyTombstones {
+		if tombstone.seq > s.cursor {
+			if _, exists := h.rooms[tombstone.roomID]; !exists {
+				changes = append(changes, lobbyChange{seq: tombstone.seq, roomID: tombstone.roomID})
+			}
+		}
+	}
+	summaries := make(map[string]draftRoomSummary, len(changes))
+	for _, change := range changes {
+		if change.room != nil {
+			summaries[change.roomID] = change.room.summary(s.requesterDeviceID)
+		}
+	}
+	s.cursor = h.lobbySeq
+	h.mu.RUnlock()
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].seq < changes[j].seq
+	})
+	frames := make([]sseFrame, 0, len(changes))
+	for _, change := range changes {
+		id := h.lobbyEventID(change.seq)
+		summary, exists := summaries[change.roomID]
+		if !exists || !s.filter.matches(summary) {
+			if s.known[change.roomID] {
+				delete(s.known, change.roomID)
+				frames = append(frames, sseFrame{ID: id, Event: lobbyEventRoomDeleted, Data: lobbyRoomDeletedEvent{RoomID: change.roomID}})
+			}
+			continue
+		}
+		event := lobbyEventRoomUpdated
+		if !s.known[change.roomID] {
+			event = lobbyEventRoomCreated
+		}
+		s.known[change.roomID] = true
+		frames = append(frames, sseFrame{ID: id, Event: event, Data: lobbyRoomEvent{Room: summary}})
+	}
+	return frames, nil
+}
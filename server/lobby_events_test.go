@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSSEFrame struct {
+	id    string
+	event string
+	data  string
+}
+
+type testSSEStream struct {
+	t     *testing.T
+	lines *bufio.Scanner
+	close func()
+}
+
+func openTestSSE(t *testing.T, ctx context.Context, url, lastEventID string) *testSSEStream {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err, "new request")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err, "open stream")
+	require.Equal(t, http.StatusOK, res.StatusCode, "stream status")
+	return &testSSEStream{t: t, lines: bufio.NewScanner(res.Body), close: func() { _ = res.Body.Close() }}
+}
+
+func (s *testSSEStream) next() testSSEFrame {
+	s.t.Helper()
+	var frame testSSEFrame
+	for s.lines.Scan() {
+		line := s.lines.Text()
+		switch {
+		case line == "" && frame.data != "":
+			return frame
+		case strings.HasPrefix(line, "id: "):
+			frame.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			frame.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			frame.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	require.FailNow(s.t, "stream ended", "%v", s.lines.Err())
+	return frame
+}
+
+func newLobbyTestServer(t *testing.T) (*draftHub, *httptest.Server) {
+	t.Helper()
+	hub := newDraftHub()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/draft/rooms", hub.handleCreateRoom)
+	mux.HandleFunc("/api/draft/lobby/events", hub.handleLobbyEvents)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return hub, srv
+}
+
+func createTestRoomOverHTTP(t *testing.T, srv *httptest.Server, deviceID string, body createDraftRoomRequest) string {
+	t.Helper()
+	raw, err := json.Marshal(body)
+	require.NoError(t, err, "marshal request")
+	res, err := http.Post(srv.URL+withDeviceID("/api/draft/rooms", deviceID), "application/json", bytes.NewReader(raw))
+	require.NoError(t, err, "create room")
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode, "create status")
+	var created createDraftRoomResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&created), "decode create response")
+	return created.RoomID
+}
+
+func TestLobbyEventsSendDiffsAndResume(t *testing.T) {
+	hub, srv := newLobbyTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream := openTestSSE(t, ctx, srv.URL+withDeviceID("/api/draft/lobby/events", "device-a"), "")
+	initial := stream.next()
+	assert.Empty(t, initial.event, "initial list should be an unnamed event")
+	assert.JSONEq(t, `{"rooms":[]}`, initial.data, "initial list")
+
+	roomID := createTestRoomOverHTTP(t, srv, "device-a", createDraftRoomRequest{
+		Deck: []string{"A", "B"}, DeckSlug: "tempo", SeatCount: 2, PackCount: 1, PackSize: 1, HostName: "  Ada   L ",
+	})
+	created := stream.next()
+	assert.Equal(t, lobbyEventRoomCreated, created.event, "create event")
+	var createdPayload lobbyRoomEvent
+	require.NoError(t, json.Unmarshal([]byte(created.data), &createdPayload), "decode created")
+	assert.Equal(t, roomID, createdPayload.Room.RoomID, "created room id")
+	assert.Equal(t, "Ada L", createdPayload.Room.HostName, "host name")
+	assert.False(t, createdPayload.Room.CreatedAt.IsZero(), "created_at set")
+
+	hub.notifyLobbySubscribers(roomID)
+	updated := stream.next()
+	assert.Equal(t, lobbyEventRoomUpdated, updated.event, "update event")
+	stream.close()
+
+	require.NoError(t, hub.deleteRoom(ctx, roomID, "device-a"), "delete room")
+
+	resumed := openTestSSE(t, ctx, srv.URL+withDeviceID("/api/draft/lobby/events", "device-a"), updated.id)
+	deleted := resumed.next()
+	assert.Equal(t, lobbyEventRoomDeleted, deleted.event, "resume should replay the deletion")
+	assert.JSONEq(t, `{"room_id":"`+roomID+`"}`, deleted.data, "deleted payload")
+	resumed.close()
+
+	fresh := openTestSSE(t, ctx, srv.URL+withDeviceID("/api/draft/lobby/events", "device-a"), "other-epoch-3")
+	assert.Empty(t, fresh.next().event, "unknown event ids should fall back to the full list")
+	fresh.close()
+}
+
+func TestLobbyEventsFilterRooms(t *testing.T) {
+	hub, srv := newLobbyTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tempoID := createTestRoomOverHTTP(t, srv, "device-a", createDraftRoomRequest{Deck: []string{"A", "B"}, DeckSlug: "tempo", SeatCount: 2, PackCount: 1, PackSize: 1})
+	createTestRoomOverHTTP(t, srv, "device-b", createDraftRoomRequest{Deck: []string{"A", "B"}, DeckSlug: "legacy", SeatCount: 2, PackCount: 1, PackSize: 1})
+
+	listRes, err := http.Get(srv.URL + withDeviceID("/api/draft/rooms?owned=false", "device-a"))
+	require.NoError(t, err, "list rooms")
+	var list listDraftRoomsResponse
+	require.NoError(t, json.NewDecoder(listRes.Body).Decode(&list), "decode list")
+	listRes.Body.Close()
+	require.Len(t, list.Rooms, 1, "owned=false should hide device-a's room")
+	assert.Equal(t, "legacy", list.Rooms[0].DeckSlug, "filtered room")
+
+	badRes, err := http.Get(srv.URL + withDeviceID("/api/draft/rooms?state=paused", "device-a"))
+	require.NoError(t, err, "list rooms with bad filter")
+	badRes.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, badRes.StatusCode, "invalid state filter")
+
+	stream := openTestSSE(t, ctx, srv.URL+withDeviceID("/api/draft/lobby/events?deck_slug=tempo&state=drafting", "device-a"), "")
+	defer stream.close()
+	var initial listDraftRoomsResponse
+	require.NoError(t, json.Unmarshal([]byte(stream.next().data), &initial), "decode initial")
+	require.Len(t, initial.Rooms, 1, "deck filter on initial list")
+
+	room := hub.room(tempoID)
+	room.mu.Lock()
+	for seat := 0; seat < room.draft.Config.SeatCount; seat++ {
+		_, err := room.draft.randomPickBatchForSeat(seat, PickZoneMainboard)
+		require.NoError(t, err, "bot pick")
+	}
+	room.mu.Unlock()
+	hub.notifyLobbySubscribers(tempoID)
+
+	left := stream.next()
+	assert.Equal(t, lobbyEventRoomDeleted, left.event, "a room leaving the state filter is reported as deleted")
+}
@@ -346,3 +346,39 @@ func TestDraftRoomHandleBotPickAdvancesAfterRequesterPicked(t *testing.T) {
 	assert.Len(t, room.draft.Seats[0].Picks.Mainboard, 1, "requester seat should keep its pick")
 	assert.Len(t, room.draft.Seats[1].Picks.Mainboard, 1, "bot seat should receive one pick")
 }
+
+func TestDraftRoomSeedRevealedToOwnerAfterDraft(t *testing.T) {
+	hub := newDraftHub()
+	seed := uint64(1234)
+	createBody := createDraftRoomRequest{
+		Deck:      []string{"A", "B"},
+		DeckSlug:  "tempo",
+		SeatCount: 2,
+		PackCount: 1,
+		PackSize:  1,
+		Seed:      &seed,
+	}
+	raw, err := json.Marshal(createBody)
+	require.NoError(t, err, "marshal request")
+
+	createReq := httptest.NewRequest(http.MethodPost, withDeviceID("/api/draft/rooms", "owner-device"), bytes.NewReader(raw))
+	createRes := httptest.NewRecorder()
+	hub.handleCreateRoom(createRes, createReq)
+	require.Equal(t, http.StatusOK, createRes.Code, "create status mismatch")
+
+	rooms := hub.listRoomSummaries("owner-device")
+	require.Len(t, rooms, 1, "rooms length mismatch")
+	assert.Nil(t, rooms[0].Seed, "seed should stay hidden while drafting")
+
+	room := hub.rooms[rooms[0].RoomID]
+	assert.Equal(t, seed, room.draft.Config.Seed, "requested seed should be used")
+	for seat := 0; seat < 2; seat++ {
+		_, err := room.draft.randomPickBatchForSeat(seat, PickZoneMainboard)
+		require.NoErrorf(t, err, "bot pick seat %d", seat)
+	}
+
+	rooms = hub.listRoomSummaries("owner-device")
+	require.NotNil(t, rooms[0].Seed, "seed should be revealed to the owner after the draft")
+	assert.Equal(t, seed, *rooms[0].Seed, "revealed seed mismatch")
+	assert.Nil(t, hub.listRoomSummaries("other-device")[0].Seed, "seed should stay hidden from other devices")
+}
@@ -91,10 +91,59 @@ func main() {
 	}
 	log.Printf("Loaded %d draft room(s) from %s", loadedRooms, draftStorePath)
 
+	events, err := draftStore.LoadEvents(context.Background())
+	if err != nil {
+		log.Printf("Failed to load draft events from %s: %v", draftStorePath, err)
+	} else {
+		draftHub.restoreEvents(events)
+		log.Printf("Loaded %d draft event(s) from %s", len(events), draftStorePath)
+	}
+
+	schedules, err := draftStore.LoadSchedules(context.Background())
+	if err != nil {
+		log.Printf("Failed to load draft schedules from %s: %v", draftStorePath, err)
+	} else {
+		draftHub.restoreSchedules(schedules)
+		log.Printf("Loaded %d draft schedule(s) from %s", len(schedules), draftStorePath)
+	}
+
+	gameHub := newGameHub()
+	gameHub.setStore(draftStore)
+	gameSessions, err := draftStore.LoadGameSessions(context.Background())
+	if err != nil {
+		log.Printf("Failed to load game sessions from %s: %v", draftStorePath, err)
+	} else if err := gameHub.restoreSessions(gameSessions); err != nil {
+		log.Printf("Failed to restore game sessions from %s, starting with none: %v", draftStorePath, err)
+		gameHub = newGameHub()
+		gameHub.setStore(draftStore)
+	} else {
+		log.Printf("Loaded %d game session(s) from %s", len(gameSessions), draftStorePath)
+	}
+	librarySessions, err := draftStore.LoadLibrarySessions(context.Background())
+	if err != nil {
+		log.Printf("Failed to load library sessions from %s: %v", draftStorePath, err)
+	} else if err := gameHub.restoreLibraries(librarySessions); err != nil {
+		log.Printf("Failed to restore library sessions from %s: %v", draftStorePath, err)
+	} else {
+		log.Printf("Loaded %d library session(s) from %s", len(librarySessions), draftStorePath)
+	}
+
+	webhooks, err := webhooksFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load webhooks: %v", err)
+	}
+	if webhooks != nil {
+		draftHub.setWebhooks(webhooks)
+		log.Printf("Loaded %d webhook(s)", len(webhooks.hooks))
+	}
+
 	go func() {
 		ticker := time.NewTicker(10 * time.Second)
 		defer ticker.Stop()
 		for range ticker.C {
+			if started := draftHub.startDueSchedules(context.Background(), time.Now()); len(started) > 0 {
+				log.Printf("opened %d scheduled room(s)", len(started))
+			}
 			snapshottedCount, err := draftHub.snapshotAndSaveRooms(context.Background())
 			if err != nil {
 				log.Printf("Failed to snapshot draft rooms: %v", err)
@@ -103,6 +152,15 @@ func main() {
 			if snapshottedCount > 0 {
 				log.Printf("snapshotted %d rooms", snapshottedCount)
 			}
+			if _, err := draftHub.savePickStats(context.Background()); err != nil {
+				log.Printf("Failed to record draft pick stats: %v", err)
+			}
+			if _, err := gameHub.snapshotAndSaveSessions(context.Background()); err != nil {
+				log.Printf("Failed to snapshot game sessions: %v", err)
+			}
+			if _, err := gameHub.snapshotAndSaveLibraries(context.Background()); err != nil {
+				log.Printf("Failed to snapshot library sessions: %v", err)
+			}
 		}
 	}()
 
@@ -113,9 +171,32 @@ func main() {
 		mux.HandleFunc("/api/source-primer", handleSourcePrimer)
 	}
 	mux.HandleFunc("/api/draft/rooms", draftHub.handleCreateRoom)
+	mux.HandleFunc("/api/draft/rooms/start", draftHub.handleStartRoom)
+	mux.HandleFunc("/api/draft/rooms/progress", draftHub.handleRoomProgress)
+	mux.HandleFunc("/api/draft/rooms/pools", draftHub.handleRoomPools)
 	mux.HandleFunc("/api/draft/lobby/events", draftHub.handleLobbyEvents)
+	mux.HandleFunc("/api/draft/overlay/events", draftHub.handleOverlayEvents)
+	mux.HandleFunc("/api/draft/overlay/token", draftHub.handleOverlayToken)
 	mux.HandleFunc("/api/draft/shared", draftHub.handleStartOrJoinSharedRoom)
 	mux.HandleFunc("/api/draft/ws", draftHub.handleWS)
+	mux.HandleFunc("/api/draft/events", draftHub.handleEvents)
+	mux.HandleFunc("/api/draft/events/pods", draftHub.handleEventPods)
+	mux.HandleFunc("/api/draft/events/players", draftHub.handleEventPlayers)
+	mux.HandleFunc("/api/draft/events/matches", draftHub.handleEventMatches)
+	mux.HandleFunc("/api/draft/stats", draftHub.handleDraftStats)
+	mux.HandleFunc("/api/draft/schedules", draftHub.handleSchedules)
+	mux.HandleFunc("/api/draft/schedules/rsvp", draftHub.handleScheduleRSVP)
+	mux.HandleFunc("/api/draft/schedules/ics", draftHub.handleScheduleICS)
+	mux.HandleFunc("/api/game/sessions", gameHub.handleGameSessions)
+	mux.HandleFunc("/api/game/ws", gameHub.handleGameWS)
+	mux.HandleFunc("/api/game/libraries", gameHub.handleLibrarySessions)
+	mux.HandleFunc("/api/game/library/ws", gameHub.handleLibraryWS)
+	mux.HandleFunc("/api/game/matches", gameHub.handleMatches)
+	mux.HandleFunc("/api/game/matches/summary", gameHub.handleMatchSummary)
+	mux.HandleFunc("/api/game/matches/matrix", gameHub.handleMatchMatrix)
+	mux.HandleFunc("/api/game/roll", gameHub.handleMatchupRoll)
+	mux.HandleFunc("/api/game/ratings", gameHub.handleRatings)
+	mux.HandleFunc("/api/game/ratings/recompute", gameHub.handleRatings)
 
 	// Serve static files (SPA shell)
 	fileServer := http.FileServer(http.Dir(staticRoot))
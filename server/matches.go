@@ -0,0 +1,363 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/lxing/battlebox/internal/buildtool"
+)
+
+// Match results record battlebox games played in person, so they can be summed
+// per deck. A match is one pairing of two decks; game wins decide its winner.
+
+const (
+	matchGameWinsMax      = 9
+	matchListDefaultLimit = 100
+	matchListMaxLimit     = 500
+)
+
+var errMatchResultNotFound = errors.New("match result not found")
+
+type matchResult struct {
+	MatchID   int64  `json:"match_id"`
+	Battlebox string `json:"battlebox"`
+	P1Deck    string `json:"p1_deck"`
+	P2Deck    string `json:"p2_deck"`
+	P1Wins    int    `json:"p1_wins"`
+	P2Wins    int    `json:"p2_wins"`
+	// OnPlay is the player on the play in game one.
+	OnPlay string `json:"on_play"`
+	P1Name string `json:"p1_name,omitempty"`
+	P2Name string `json:"p2_name,omitempty"`
+	// Guide is the deck slug whose matchup guide was followed, if any.
+	Guide            string    `json:"guide,omitempty"`
+	PlayedAt         time.Time `json:"played_at"`
+	RecorderDeviceID string    `json:"-"`
+	OwnedByRequest   bool      `json:"owned_by_requester"`
+}
+
+// normalize cleans a submitted result and checks it against the battlebox's decks.
+func (m *matchResult) normalize() error {
+	m.Battlebox = normalizeSlug(m.Battlebox)
+	m.P1Deck = normalizeSlug(m.P1Deck)
+	m.P2Deck = normalizeSlug(m.P2Deck)
+	m.P1Name = normalizeHostName(m.P1Name)
+	m.P2Name = normalizeHostName(m.P2Name)
+	if m.Battlebox == "" {
+		return errors.New("battlebox required")
+	}
+	if m.P1Deck == "" || m.P2Deck == "" {
+		return errors.New("p1_deck and p2_deck required")
+	}
+	if m.P1Deck == m.P2Deck {
+		return errors.New("p1_deck and p2_deck must differ")
+	}
+	decks, err := matchDecks.BattleboxDecks(m.Battlebox)
+	if err != nil {
+		return err
+	}
+	for _, deck := range []string{m.P1Deck, m.P2Deck} {
+		if !slices.Contains(decks, deck) {
+			return fmt.Errorf("deck %q not found in battlebox %q", deck, m.Battlebox)
+		}
+	}
+	if m.P1Wins < 0 || m.P2Wins < 0 || m.P1Wins > matchGameWinsMax || m.P2Wins > matchGameWinsMax {
+		return fmt.Errorf("game wins must be between 0 and %d", matchGameWinsMax)
+	}
+	if m.P1Wins+m.P2Wins == 0 {
+		return errors.New("at least one game required")
+	}
+	if m.OnPlay != "p1" && m.OnPlay != "p2" {
+		return errors.New("on_play must be p1 or p2")
+	}
+	if m.Guide != "" {
+		m.Guide = normalizeSlug(m.Guide)
+		if m.Guide != m.P1Deck && m.Guide != m.P2Deck {
+			return errors.New("guide must be one of the match decks")
+		}
+	}
+	return nil
+}
+
+type listMatchResultsResponse struct {
+	Battlebox string        `json:"battlebox"`
+	Matches   []matchResult `json:"matches"`
+}
+
+// matchTally counts matches and games from one deck's side.
+type matchTally struct {
+	Matches   int `json:"matches"`
+	Wins      int `json:"wins"`
+	Losses    int `json:"losses"`
+	Draws     int `json:"draws"`
+	GamesWon  int `json:"games_won"`
+	GamesLost int `json:"games_lost"`
+}
+
+func (t *matchTally) add(gamesWon, gamesLost int) {
+	t.Matches++
+	t.GamesWon += gamesWon
+	t.GamesLost += gamesLost
+	switch {
+	case gamesWon > gamesLost:
+		t.Wins++
+	case gamesWon < gamesLost:
+		t.Losses++
+	default:
+		t.Draws++
+	}
+}
+
+type opponentMatchSummary struct {
+	Opponent string `json:"opponent"`
+	matchTally
+}
+
+type deckMatchSummary struct {
+	Deck string `json:"deck"`
+	matchTally
+	// WinRate is match wins over decided matches; drawn matches are left out.
+	WinRate   float64                `json:"wr"`
+	OnPlay    matchTally             `json:"on_play"`
+	OnDraw    matchTally             `json:"on_draw"`
+	Opponents []opponentMatchSummary `json:"opponents"`
+}
+
+type matchSummary struct {
+	Battlebox string             `json:"battlebox"`
+	Matches   int                `json:"matches"`
+	Decks     []deckMatchSummary `json:"decks"`
+}
+
+// summarizeMatchResults tallies every result from both decks' sides. Decks and
+// opponents are sorted by slug.
+func summarizeMatchResults(battlebox string, results []matchResult) matchSummary {
+	byDeck := map[string]*deckMatchSummary{}
+	byOpponent := map[[2]string]*opponentMatchSummary{}
+	addSide := func(deck, opponent string, won, lost int, onPlay bool) {
+		summary := byDeck[deck]
+		if summary == nil {
+			summary = &deckMatchSummary{Deck: deck}
+			byDeck[deck] = summary
+		}
+		summary.add(won, lost)
+		if onPlay {
+			summary.OnPlay.add(won, lost)
+		} else {
+			summary.OnDraw.add(won, lost)
+		}
+		key := [2]string{deck, opponent}
+		if byOpponent[key] == nil {
+			byOpponent[key] = &opponentMatchSummary{Opponent: opponent}
+		}
+		byOpponent[key].add(won, lost)
+	}
+	for _, result := range results {
+		addSide(result.P1Deck, result.P2Deck, result.P1Wins, result.P2Wins, result.OnPlay == "p1")
+		addSide(result.P2Deck, result.P1Deck, result.P2Wins, result.P1Wins, result.OnPlay == "p2")
+	}
+
+	out := matchSummary{Battlebox: battlebox, Matches: len(results), Decks: make([]deckMatchSummary, 0, len(byDeck))}
+	for key, opponent := range byOpponent {
+		byDeck[key[0]].Opponents = append(byDeck[key[0]].Opponents, *opponent)
+	}
+	for _, summary := range byDeck {
+		if decided := summary.Wins + summary.Losses; decided > 0 {
+			summary.WinRate = float64(summary.Wins) / float64(decided)
+		}
+		sort.Slice(summary.Opponents, func(i, j int) bool {
+			return summary.Opponents[i].Opponent < summary.Opponents[j].Opponent
+		})
+		out.Decks = append(out.Decks, *summary)
+	}
+	sort.Slice(out.Decks, func(i, j int) bool { return out.Decks[i].Deck < out.Decks[j].Deck })
+	return out
+}
+
+// localMatchResults tallies results per matchup in the shape the build blends
+// into the winrate matrix.
+func localMatchResults(battlebox string, results []matchResult) buildtool.LocalMatchResults {
+	out := buildtool.LocalMatchResults{Battlebox: battlebox, Matchups: map[string]map[string]buildtool.LocalMatchRecord{}}
+	for _, summary := range summarizeMatchResults(battlebox, results).Decks {
+		row := make(map[string]buildtool.LocalMatchRecord, len(summary.Opponents))
+		for _, opponent := range summary.Opponents {
+			row[opponent.Opponent] = buildtool.LocalMatchRecord{
+				Matches: opponent.Matches,
+				Wins:    opponent.Wins,
+				Losses:  opponent.Losses,
+				Draws:   opponent.Draws,
+			}
+		}
+		out.Matchups[summary.Deck] = row
+	}
+	return out
+}
+
+func (h *gameHub) matchStore(w http.ResponseWriter) *draftRoomStore {
+	h.mu.RLock()
+	store := h.store
+	h.mu.RUnlock()
+	if store == nil {
+		http.Error(w, "match results unavailable", http.StatusServiceUnavailable)
+	}
+	return store
+}
+
+// handleMatches serves GET (list by battlebox, optionally one deck), POST
+// (record a result) and DELETE (recorder only) on /api/game/matches.
+func (h *gameHub) handleMatches(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodPost, http.MethodDelete:
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	requesterDeviceID, err := requesterDeviceIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	store := h.matchStore(w)
+	if store == nil {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		defer r.Body.Close()
+		var result matchResult
+		if err := json.NewDecoder(r.Body).Decode(&result); err != nil {
+			http.Error(w, "invalid json body", http.StatusBadRequest)
+			return
+		}
+		if err := result.normalize(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if result.PlayedAt.IsZero() {
+			result.PlayedAt = time.Now()
+		}
+		result.RecorderDeviceID = requesterDeviceID
+		matchID, err := store.SaveMatchResult(r.Context(), result)
+		if err != nil {
+			http.Error(w, "failed to record match result", http.StatusInternalServerError)
+			return
+		}
+		result.MatchID = matchID
+		result.OwnedByRequest = true
+		refreshRatings(r.Context(), store, result.Battlebox)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(result)
+
+	case http.MethodDelete:
+		matchID, err := strconv.ParseInt(r.URL.Query().Get("match_id"), 10, 64)
+		if err != nil || matchID <= 0 {
+			http.Error(w, "match_id query param required", http.StatusBadRequest)
+			return
+		}
+		battlebox, err := store.DeleteMatchResult(r.Context(), matchID, requesterDeviceID)
+		if err != nil {
+			switch {
+			case errors.Is(err, errMatchResultNotFound):
+				http.Error(w, "match result not found", http.StatusNotFound)
+			case errors.Is(err, errDraftRoomForbidden):
+				http.Error(w, "only the recorder may delete this match result", http.StatusForbidden)
+			default:
+				http.Error(w, "failed to delete match result", http.StatusInternalServerError)
+			}
+			return
+		}
+		refreshRatings(r.Context(), store, battlebox)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		battlebox := normalizeSlug(r.URL.Query().Get("battlebox"))
+		if battlebox == "" {
+			http.Error(w, "battlebox query param required", http.StatusBadRequest)
+			return
+		}
+		deck := normalizeSlug(r.URL.Query().Get("deck"))
+		limit := matchListDefaultLimit
+		if raw := r.URL.Query().Get("limit"); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed <= 0 {
+				http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+				return
+			}
+			limit = min(parsed, matchListMaxLimit)
+		}
+		results, err := store.LoadMatchResults(r.Context(), battlebox)
+		if err != nil {
+			http.Error(w, "failed to load match results", http.StatusInternalServerError)
+			return
+		}
+		matches := make([]matchResult, 0, min(len(results), limit))
+		for _, result := range results {
+			if len(matches) == limit {
+				break
+			}
+			if deck != "" && result.P1Deck != deck && result.P2Deck != deck {
+				continue
+			}
+			result.OwnedByRequest = result.RecorderDeviceID == requesterDeviceID
+			matches = append(matches, result)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(listMatchResultsResponse{Battlebox: battlebox, Matches: matches})
+	}
+}
+
+// battleboxMatchResults loads every recorded result of the battlebox named in a
+// GET request's query, writing the error response and returning false on failure.
+func (h *gameHub) battleboxMatchResults(w http.ResponseWriter, r *http.Request) (string, []matchResult, bool) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return "", nil, false
+	}
+	battlebox := normalizeSlug(r.URL.Query().Get("battlebox"))
+	if battlebox == "" {
+		http.Error(w, "battlebox query param required", http.StatusBadRequest)
+		return "", nil, false
+	}
+	store := h.matchStore(w)
+	if store == nil {
+		return "", nil, false
+	}
+	results, err := store.LoadMatchResults(r.Context(), battlebox)
+	if err != nil {
+		http.Error(w, "failed to load match results", http.StatusInternalServerError)
+		return "", nil, false
+	}
+	return battlebox, results, true
+}
+
+// handleMatchSummary serves GET /api/game/matches/summary?battlebox=<slug>.
+func (h *gameHub) handleMatchSummary(w http.ResponseWriter, r *http.Request) {
+	battlebox, results, ok := h.battleboxMatchResults(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(summarizeMatchResults(battlebox, results))
+}
+
+// handleMatchMatrix serves GET /api/game/matches/matrix?battlebox=<slug>. The
+// response can be saved as data/<battlebox>/local-match-results.json to blend it
+// into the build.
+func (h *gameHub) handleMatchMatrix(w http.ResponseWriter, r *http.Request) {
+	battlebox, results, ok := h.battleboxMatchResults(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(localMatchResults(battlebox, results))
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lxing/battlebox/internal/buildtool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMatchDecks struct{}
+
+func (fakeMatchDecks) BattleboxDecks(battleboxSlug string) ([]string, error) {
+	switch battleboxSlug {
+	case "pauper":
+		return []string{"affinity", "elves", "tron"}, nil
+	case "cube":
+		return []string{"cube"}, nil
+	}
+	return nil, fmt.Errorf("battlebox %q not found", battleboxSlug)
+}
+
+func (fakeMatchDecks) DeckBattlebox(deckSlug string) (string, error) {
+	switch deckSlug {
+	case "affinity", "elves", "tron":
+		return "pauper", nil
+	case "cube":
+		return "cube", nil
+	}
+	return "", fmt.Errorf("deck %q not found", deckSlug)
+}
+
+func useFakeMatchDecks(t *testing.T) {
+	t.Helper()
+	previous := matchDecks
+	matchDecks = fakeMatchDecks{}
+	t.Cleanup(func() { matchDecks = previous })
+}
+
+func TestMatchResultNormalize(t *testing.T) {
+	useFakeMatchDecks(t)
+	valid := matchResult{Battlebox: " Pauper ", P1Deck: "affinity", P2Deck: "Elves", P1Wins: 2, P2Wins: 1, OnPlay: "p2", P1Name: "  Ada  ", Guide: "elves"}
+	require.NoError(t, valid.normalize(), "valid result")
+	assert.Equal(t, "pauper", valid.Battlebox, "battlebox slug normalized")
+	assert.Equal(t, "elves", valid.P2Deck, "deck slug normalized")
+	assert.Equal(t, "Ada", valid.P1Name, "player name trimmed")
+
+	for name, result := range map[string]matchResult{
+		"unknown battlebox": {Battlebox: "modern", P1Deck: "affinity", P2Deck: "elves", P1Wins: 1, OnPlay: "p1"},
+		"unknown deck":      {Battlebox: "pauper", P1Deck: "affinity", P2Deck: "burn", P1Wins: 1, OnPlay: "p1"},
+		"mirror":            {Battlebox: "pauper", P1Deck: "affinity", P2Deck: "affinity", P1Wins: 1, OnPlay: "p1"},
+		"no games":          {Battlebox: "pauper", P1Deck: "affinity", P2Deck: "elves", OnPlay: "p1"},
+		"negative wins":     {Battlebox: "pauper", P1Deck: "affinity", P2Deck: "elves", P1Wins: -1, P2Wins: 2, OnPlay: "p1"},
+		"missing play":      {Battlebox: "pauper", P1Deck: "affinity", P2Deck: "elves", P1Wins: 1},
+		"foreign guide":     {Battlebox: "pauper", P1Deck: "affinity", P2Deck: "elves", P1Wins: 1, OnPlay: "p1", Guide: "tron"},
+	} {
+		assert.Error(t, result.normalize(), name)
+	}
+}
+
+func TestSummarizeMatchResults(t *testing.T) {
+	summary := summarizeMatchResults("pauper", []matchResult{
+		{P1Deck: "affinity", P2Deck: "elves", P1Wins: 2, P2Wins: 1, OnPlay: "p1"},
+		{P1Deck: "elves", P2Deck: "affinity", P1Wins: 2, P2Wins: 0, OnPlay: "p1"},
+		{P1Deck: "affinity", P2Deck: "tron", P1Wins: 1, P2Wins: 1, OnPlay: "p2"},
+	})
+	assert.Equal(t, 3, summary.Matches, "matches")
+	require.Len(t, summary.Decks, 3, "one summary per deck")
+
+	affinity := summary.Decks[0]
+	assert.Equal(t, "affinity", affinity.Deck, "decks sorted by slug")
+	assert.Equal(t, matchTally{Matches: 3, Wins: 1, Losses: 1, Draws: 1, GamesWon: 3, GamesLost: 4}, affinity.matchTally, "affinity totals")
+	assert.InDelta(t, 0.5, affinity.WinRate, 1e-9, "draws left out of the win rate")
+	assert.Equal(t, matchTally{Matches: 1, Wins: 1, GamesWon: 2, GamesLost: 1}, affinity.OnPlay, "on the play")
+	assert.Equal(t, 2, affinity.OnDraw.Matches, "on the draw")
+	require.Len(t, affinity.Opponents, 2, "opponents")
+	assert.Equal(t, "elves", affinity.Opponents[0].Opponent, "opponents sorted by slug")
+	assert.Equal(t, 1, affinity.Opponents[0].Wins, "affinity beat elves once")
+	assert.Equal(t, 1, affinity.Opponents[0].Losses, "elves beat affinity once")
+
+	tron := summary.Decks[2]
+	assert.Equal(t, matchTally{Matches: 1, Draws: 1, GamesWon: 1, GamesLost: 1}, tron.matchTally, "tron totals")
+	assert.Equal(t, 1, tron.OnPlay.Matches, "tron was on the play")
+}
+
+func doMatchRequest(t *testing.T, srv *httptest.Server, method, path, deviceID, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	require.NoError(t, err, "new request")
+	req.Header.Set("X-Device-ID", deviceID)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err, "%s %s", method, path)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+func TestMatchResultsAPI(t *testing.T) {
+	useFakeMatchDecks(t)
+	store, err := openDraftRoomStore(filepath.Join(t.TempDir(), "db.sqlite"))
+	require.NoError(t, err, "openDraftRoomStore")
+	defer func() {
+		_ = store.Close()
+	}()
+
+	hub := newGameHub()
+	hub.setStore(store)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/game/matches", hub.handleMatches)
+	mux.HandleFunc("/api/game/matches/summary", hub.handleMatchSummary)
+	mux.HandleFunc("/api/game/matches/matrix", hub.handleMatchMatrix)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	resp := doMatchRequest(t, srv, http.MethodPost, "/api/game/matches", "ada", `{"battlebox":"pauper","p1_deck":"affinity","p2_deck":"elves","p1_wins":2,"p2_wins":1,"on_play":"p1","played_at":"2026-10-17T19:00:00Z"}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, "record first match")
+	var first matchResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&first), "decode result")
+	assert.Positive(t, first.MatchID, "match id assigned")
+	resp = doMatchRequest(t, srv, http.MethodPost, "/api/game/matches", "bo", `{"battlebox":"pauper","p1_deck":"tron","p2_deck":"elves","p1_wins":0,"p2_wins":2,"on_play":"p2","guide":"tron","played_at":"2026-10-18T19:00:00Z"}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, "record second match")
+	resp = doMatchRequest(t, srv, http.MethodPost, "/api/game/matches", "bo", `{"battlebox":"pauper","p1_deck":"tron","p2_deck":"burn","p1_wins":1,"on_play":"p1"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "unknown deck rejected")
+
+	resp = doMatchRequest(t, srv, http.MethodGet, "/api/game/matches?battlebox=pauper", "ada", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, "list")
+	var listed listMatchResultsResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&listed), "decode list")
+	require.Len(t, listed.Matches, 2, "both matches listed")
+	assert.Equal(t, "tron", listed.Matches[0].P1Deck, "most recent first")
+	assert.Equal(t, "tron", listed.Matches[0].Guide, "guide stored")
+	assert.False(t, listed.Matches[0].OwnedByRequest, "recorded by someone else")
+	assert.True(t, listed.Matches[1].OwnedByRequest, "recorded by the requester")
+
+	resp = doMatchRequest(t, srv, http.MethodGet, "/api/game/matches?battlebox=pauper&deck=affinity", "ada", "")
+	listed = listMatchResultsResponse{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&listed), "decode filtered list")
+	assert.Len(t, listed.Matches, 1, "filtered by deck")
+
+	resp = doMatchRequest(t, srv, http.MethodGet, "/api/game/matches/summary?battlebox=pauper", "ada", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, "summary")
+	var summary matchSummary
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&summary), "decode summary")
+	require.Len(t, summary.Decks, 3, "deck summaries")
+	assert.Equal(t, "elves", summary.Decks[1].Deck, "elves summary")
+	assert.Equal(t, 1, summary.Decks[1].Wins, "elves beat tron")
+	assert.Equal(t, 1, summary.Decks[1].Losses, "elves lost to affinity")
+
+	resp = doMatchRequest(t, srv, http.MethodGet, "/api/game/matches/matrix?battlebox=pauper", "ada", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, "matrix export")
+	var exported buildtool.LocalMatchResults
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&exported), "decode matrix export")
+	assert.Equal(t, buildtool.LocalMatchRecord{Matches: 1, Wins: 1}, exported.Matchups["affinity"]["elves"], "affinity vs elves")
+	assert.Equal(t, buildtool.LocalMatchRecord{Matches: 1, Losses: 1}, exported.Matchups["elves"]["affinity"], "elves vs affinity")
+
+	path := fmt.Sprintf("/api/game/matches?match_id=%d", first.MatchID)
+	resp = doMatchRequest(t, srv, http.MethodDelete, path, "bo", "")
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode, "only the recorder may delete")
+	resp = doMatchRequest(t, srv, http.MethodDelete, path, "ada", "")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode, "recorder deletes")
+	resp = doMatchRequest(t, srv, http.MethodDelete, path, "ada", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "already deleted")
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/lxing/battlebox/draftproto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDraftTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	hub := newDraftHub()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/draft/rooms", hub.handleCreateRoom)
+	mux.HandleFunc("/api/draft/ws", hub.handleWS)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestHeadlessClientDraftsOverWebsocket(t *testing.T) {
+	srv := newDraftTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	deck := make([]string, 4)
+	for i := range deck {
+		deck[i] = fmt.Sprintf("C%03d", i)
+	}
+	client := draftproto.NewClient(srv.URL, "device-a")
+	created, err := client.CreateRoom(ctx, draftproto.CreateRoomRequest{Deck: deck, SeatCount: 2, PackCount: 1, PackSize: 2})
+	require.NoError(t, err, "CreateRoom")
+	require.True(t, created.Created, "room should be created")
+
+	seat0, err := client.Join(ctx, created.RoomID, 0)
+	require.NoError(t, err, "join seat 0")
+	defer seat0.Close()
+	seat1, err := client.Join(ctx, created.RoomID, 1)
+	require.NoError(t, err, "join seat 1")
+	defer seat1.Close()
+	assert.Equal(t, draftproto.ProtocolVersion, seat0.ServerProtocolVersion(), "server protocol version")
+
+	token, err := seat0.OverlayToken(ctx)
+	require.NoError(t, err, "OverlayToken")
+	assert.NotEmpty(t, token, "seat holder gets its overlay token")
+
+	_, err = client.Join(ctx, created.RoomID, 0)
+	assert.True(t, draftproto.IsCode(err, draftproto.CodeSeatOccupied), "second join should report seat_occupied, got %v", err)
+
+	card := seat0.State().Active.Cards[0]
+	st, err := seat0.Pick(ctx, draftproto.PickSelection{CardName: card, Zone: draftproto.ZoneMainboard})
+	require.NoError(t, err, "seat 0 pick")
+	assert.Equal(t, []string{card}, st.Picks.Mainboard, "seat 0 picks")
+
+	_, err = seat0.Pick(ctx, draftproto.PickSelection{CardName: st.Active.Cards[0], Zone: draftproto.ZoneMainboard})
+	assert.True(t, draftproto.IsCode(err, draftproto.CodeAlreadyPicked), "double pick should report already_picked, got %v", err)
+
+	resynced, err := seat0.Resync(ctx)
+	require.NoError(t, err, "Resync")
+	assert.Equal(t, []string{card}, resynced.Picks.Mainboard, "resync picks")
+	assert.False(t, resynced.CanPick, "seat 0 should wait for seat 1")
+
+	_, err = seat1.Pick(ctx, draftproto.PickSelection{CardName: seat1.State().Active.Cards[0], Zone: draftproto.ZoneMainboard})
+	require.NoError(t, err, "seat 1 pick")
+	for {
+		msg, err := seat0.Next(ctx)
+		require.NoError(t, err, "seat 0 waiting for round")
+		if _, ok := msg.(draftproto.RoundAdvanced); ok {
+			break
+		}
+	}
+	_, err = seat0.Next(ctx)
+	require.NoError(t, err, "seat 0 state after round")
+	assert.True(t, seat0.State().CanPick, "seat 0 should pick from the passed pack")
+}
+
+func TestHeadlessClientEditsPicksOverWebsocket(t *testing.T) {
+	srv := newDraftTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := draftproto.NewClient(srv.URL, "device-a")
+	created, err := client.CreateRoom(ctx, draftproto.CreateRoomRequest{Deck: []string{"C000", "C001", "C002", "C003"}, SeatCount: 2, PackCount: 1, PackSize: 2})
+	require.NoError(t, err, "CreateRoom")
+	seat0, err := client.Join(ctx, created.RoomID, 0)
+	require.NoError(t, err, "join seat 0")
+	defer seat0.Close()
+
+	card := seat0.State().Active.Cards[0]
+	_, err = seat0.Pick(ctx, draftproto.PickSelection{CardName: card, Zone: draftproto.ZoneMainboard})
+	require.NoError(t, err, "pick")
+
+	st, err := seat0.MovePick(ctx, card, draftproto.ZoneMainboard, draftproto.ZoneSideboard)
+	require.NoError(t, err, "MovePick")
+	assert.Equal(t, []string{card}, st.Picks.Sideboard, "card moved to sideboard")
+	assert.Empty(t, st.Picks.Mainboard, "mainboard after move")
+
+	st, err = seat0.RetractPick(ctx)
+	require.NoError(t, err, "RetractPick")
+	assert.True(t, st.CanPick, "seat can pick again after retracting")
+	assert.Empty(t, st.Picks.Sideboard, "retracted card leaves the pool")
+	msg, err := seat0.Next(ctx)
+	require.NoError(t, err, "wait for pick_retracted")
+	retracted, ok := msg.(draftproto.PickRetracted)
+	require.True(t, ok, "expected pick_retracted, got %T", msg)
+	assert.Equal(t, []string{card}, retracted.Cards, "retracted cards")
+
+	st, err = seat0.SetBasics(ctx, map[string]int{"plains": 0, "island": 2, "swamp": 0, "mountain": 0, "forest": 0})
+	require.NoError(t, err, "SetBasics")
+	assert.Equal(t, []string{"Island", "Island"}, st.Picks.Mainboard, "basics added to mainboard")
+
+	basics, err := seat0.SuggestBasics(ctx)
+	require.NoError(t, err, "SuggestBasics")
+	assert.Len(t, basics, 5, "one count per basic land")
+}
+
+func TestWebsocketRejectsUnsupportedProtocolVersion(t *testing.T) {
+	srv := newDraftTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := draftproto.NewClient(srv.URL, "device-a")
+	created, err := client.CreateRoom(ctx, draftproto.CreateRoomRequest{Deck: []string{"a", "b"}, SeatCount: 2, PackCount: 1, PackSize: 1})
+	require.NoError(t, err, "CreateRoom")
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/draft/ws?seat=0&room=" + created.RoomID
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, wsURL, nil)
+	require.NoError(t, err, "dial")
+	defer conn.Close()
+
+	var initial draftproto.Message
+	require.NoError(t, conn.ReadJSON(&initial), "read initial state")
+	require.NoError(t, conn.WriteJSON(draftproto.Hello{ProtocolVersion: draftproto.ProtocolVersion + 1}.Message()), "write hello")
+
+	var reply draftproto.Message
+	require.NoError(t, conn.ReadJSON(&reply), "read hello reply")
+	assert.Equal(t, draftproto.TypeError, reply.Type, "reply type")
+	assert.Equal(t, draftproto.CodeUnsupportedProtocol, reply.Code, "reply code")
+	assert.Equal(t, draftproto.ProtocolVersion, reply.ProtocolVersion, "server should advertise its version")
+}
+
+func TestSessionNextReturnsOnCancel(t *testing.T) {
+	srv := newDraftTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := draftproto.NewClient(srv.URL, "device-a")
+	created, err := client.CreateRoom(ctx, draftproto.CreateRoomRequest{Deck: []string{"a", "b"}, SeatCount: 2, PackCount: 1, PackSize: 1})
+	require.NoError(t, err, "CreateRoom")
+	seat, err := client.Join(ctx, created.RoomID, 0)
+	require.NoError(t, err, "join seat 0")
+	defer seat.Close()
+
+	// No deadline: only cancellation can end the wait for a message that never comes.
+	waitCtx, waitCancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, waitCancel)
+	done := make(chan error, 1)
+	go func() {
+		_, err := seat.Next(waitCtx)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled, "Next error")
+	case <-ctx.Done():
+		t.Fatal("Next did not return after cancel")
+	}
+}
+
+func TestRetractedCardsOnlyReachRetractingSeat(t *testing.T) {
+	srv := newDraftTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := draftproto.NewClient(srv.URL, "device-a")
+	created, err := client.CreateRoom(ctx, draftproto.CreateRoomRequest{Deck: []string{"a", "b", "c", "d", "e", "f"}, SeatCount: 2, PackCount: 1, PackSize: 3})
+	require.NoError(t, err, "CreateRoom")
+	seat1, err := client.Join(ctx, created.RoomID, 1)
+	require.NoError(t, err, "join seat 1")
+	defer seat1.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/draft/ws?seat=0&room=" + created.RoomID
+	seat0, _, err := websocket.DefaultDialer.DialContext(ctx, wsURL, nil)
+	require.NoError(t, err, "dial seat 0")
+	defer seat0.Close()
+	readUntil := func(msgType string) draftproto.Message {
+		t.Helper()
+		for {
+			var msg draftproto.Message
+			require.NoError(t, seat0.ReadJSON(&msg), "seat 0 waiting for %s", msgType)
+			if msg.Type == msgType {
+				return msg
+			}
+		}
+	}
+	initial := readUntil(draftproto.TypeState)
+	card := initial.State.Active.Cards[0]
+	pick := draftproto.Pick{Seq: initial.State.NextSeq, PackID: initial.State.Active.PackID, Picks: []draftproto.PickSelection{{CardName: card, Zone: draftproto.ZoneMainboard}}}
+	require.NoError(t, seat0.WriteJSON(pick.Message()), "write pick")
+	accepted := readUntil(draftproto.TypePickAccepted)
+	retract := draftproto.RetractPick{Seq: accepted.State.NextSeq, PackID: initial.State.Active.PackID}
+	require.NoError(t, seat0.WriteJSON(retract.Message()), "write retract")
+	retracted := readUntil(draftproto.TypePickRetracted)
+	assert.Equal(t, []string{card}, retracted.Cards, "retracting seat sees its cards")
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer waitCancel()
+	for {
+		msg, err := seat1.Next(waitCtx)
+		if err != nil {
+			break
+		}
+		_, leaked := msg.(draftproto.PickRetracted)
+		assert.False(t, leaked, "other seats must not see retracted cards")
+	}
+}
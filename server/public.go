@@ -0,0 +1,286 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/websocket"
+	"github.com/lxing/battlebox/draftproto"
+)
+
+// Read-only views of a room for spectators and stream overlays. They never
+// expose packs or pools that a seat could use mid-draft, except the streamer
+// seat's own pack in the overlay feed, which needs that seat's overlay token.
+// The seat holder gets the token over its websocket and the room owner over
+// GET /api/draft/overlay/token.
+
+type draftSeatProgress struct {
+	SeatID    int    `json:"seat_id"`
+	Name      string `json:"name"`
+	Team      int    `json:"team,omitempty"`
+	Picked    bool   `json:"picked"`
+	Connected bool   `json:"connected"`
+}
+
+type draftRoomProgress struct {
+	RoomID        string              `json:"room_id"`
+	DeckSlug      string              `json:"deck_slug,omitempty"`
+	State         string              `json:"state"`
+	SeatCount     int                 `json:"seat_count"`
+	PackCount     int                 `json:"pack_count"`
+	PackSize      int                 `json:"pack_size"`
+	PackNo        int                 `json:"pack_no"`
+	PickNo        int                 `json:"pick_no"`
+	ExpectedPicks int                 `json:"expected_picks"`
+	PendingSeats  []int               `json:"pending_seats"`
+	Seats         []draftSeatProgress `json:"seats"`
+}
+
+type draftSeatPool struct {
+	SeatID int        `json:"seat_id"`
+	Name   string     `json:"name"`
+	Team   int        `json:"team,omitempty"`
+	Picks  SeatPicks  `json:"picks"`
+	Deck   *SeatPicks `json:"deck,omitempty"`
+}
+
+type draftRoomPools struct {
+	RoomID   string          `json:"room_id"`
+	Seats    []draftSeatPool `json:"seats"`
+	Pairings []DraftPairing  `json:"pairings,omitempty"`
+}
+
+// draftOverlayFrame is one SSE frame of the stream overlay feed.
+type draftOverlayFrame struct {
+	Progress     draftRoomProgress `json:"progress"`
+	StreamerSeat int               `json:"streamer_seat"`
+	Pack         *PackView         `json:"pack,omitempty"`
+}
+
+type overlayTokenResponse struct {
+	Token string `json:"token"`
+}
+
+func (h *draftHub) room(roomID string) *draftRoom {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.rooms[roomID]
+}
+
+func (r *draftRoom) progress() draftRoomProgress {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.progressLocked()
+}
+
+func (r *draftRoom) progressLocked() draftRoomProgress {
+	pending := r.draft.PendingSeats()
+	pendingSet := make(map[int]bool, len(pending))
+	for _, seat := range pending {
+		pendingSet[seat] = true
+	}
+	seats := make([]draftSeatProgress, len(r.draft.Seats))
+	for i, seat := range r.draft.Seats {
+		seats[i] = draftSeatProgress{
+			SeatID:    i,
+			Name:      seat.Name,
+			Team:      r.draft.seatTeam(i),
+			Picked:    r.draft.State() != "done" && !pendingSet[i],
+			Connected: len(r.clients[i]) > 0,
+		}
+	}
+	return draftRoomProgress{
+		RoomID:        r.id,
+		DeckSlug:      r.deckSlug,
+		State:         r.draft.State(),
+		SeatCount:     r.draft.Config.SeatCount,
+		PackCount:     r.draft.Config.PackCount,
+		PackSize:      r.draft.Config.PackSize,
+		PackNo:        r.draft.Progress.PackNumber,
+		PickNo:        r.draft.currentPickNo(),
+		ExpectedPicks: r.draft.picksThisPass(),
+		PendingSeats:  pending,
+		Seats:         seats,
+	}
+}
+
+func (r *draftRoom) pools() (draftRoomPools, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.draft.State() != "done" {
+		return draftRoomPools{}, errDraftNotComplete
+	}
+	seats := make([]draftSeatPool, len(r.draft.Seats))
+	for i, seat := range r.draft.Seats {
+		seats[i] = draftSeatPool{
+			SeatID: i,
+			Name:   seat.Name,
+			Team:   r.draft.seatTeam(i),
+			Picks: SeatPicks{
+				Mainboard: append([]string{}, seat.Picks.Mainboard...),
+				Sideboard: append([]string{}, seat.Picks.Sideboard...),
+			},
+			Deck: cloneSeatPicks(seat.Deck),
+		}
+	}
+	return draftRoomPools{RoomID: r.id, Seats: seats, Pairings: r.draft.Pairings()}, nil
+}
+
+func (r *draftRoom) overlayFrame(streamerSeat int) (draftOverlayFrame, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return draftOverlayFrame{}, errDraftRoomNotFound
+	}
+	state, err := r.draft.PlayerState(streamerSeat)
+	if err != nil {
+		return draftOverlayFrame{}, err
+	}
+	return draftOverlayFrame{
+		Progress:     r.progressLocked(),
+		StreamerSeat: streamerSeat,
+		Pack:         state.Active,
+	}, nil
+}
+
+// overlayToken returns the seat's overlay token, issuing it on first use.
+func (r *draftRoom) overlayToken(seat int) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if token, ok := r.overlayTokens[seat]; ok {
+		return token
+	}
+	var raw [16]byte
+	_, _ = rand.Read(raw[:])
+	token := hex.EncodeToString(raw[:])
+	if r.overlayTokens == nil {
+		r.overlayTokens = make(map[int]string)
+	}
+	r.overlayTokens[seat] = token
+	return token
+}
+
+func (r *draftRoom) validOverlayToken(seat int, token string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	issued, ok := r.overlayTokens[seat]
+	return ok && token != "" && subtle.ConstantTimeCompare([]byte(issued), []byte(token)) == 1
+}
+
+// handleOverlayToken answers a seat's websocket request for its own token.
+func (r *draftRoom) handleOverlayToken(seat int, conn *websocket.Conn) {
+	token := r.overlayToken(seat)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.writeToConn(conn, draftWSMessage{Type: draftproto.TypeOverlayToken, Token: token})
+}
+
+func (h *draftHub) publicRoomFromRequest(w http.ResponseWriter, r *http.Request) *draftRoom {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil
+	}
+	roomID := r.URL.Query().Get("room_id")
+	if roomID == "" {
+		http.Error(w, "room_id query param required", http.StatusBadRequest)
+		return nil
+	}
+	room := h.room(roomID)
+	if room == nil {
+		http.Error(w, "room not found", http.StatusNotFound)
+		return nil
+	}
+	return room
+}
+
+// handleRoomProgress serves GET /api/draft/rooms/progress?room_id=.
+func (h *draftHub) handleRoomProgress(w http.ResponseWriter, r *http.Request) {
+	room := h.publicRoomFromRequest(w, r)
+	if room == nil {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(room.progress())
+}
+
+// handleRoomPools serves GET /api/draft/rooms/pools?room_id= once the draft is done.
+func (h *draftHub) handleRoomPools(w http.ResponseWriter, r *http.Request) {
+	room := h.publicRoomFromRequest(w, r)
+	if room == nil {
+		return
+	}
+	pools, err := room.pools()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(pools)
+}
+
+func overlaySeatFromRequest(w http.ResponseWriter, r *http.Request, room *draftRoom) (int, bool) {
+	seat, err := strconv.Atoi(r.URL.Query().Get("seat"))
+	if err != nil || seat < 0 || seat >= room.draft.Config.SeatCount {
+		http.Error(w, "invalid seat", http.StatusBadRequest)
+		return 0, false
+	}
+	return seat, true
+}
+
+// handleOverlayToken serves GET /api/draft/overlay/token?room_id=&seat= to the
+// room owner's device.
+func (h *draftHub) handleOverlayToken(w http.ResponseWriter, r *http.Request) {
+	room := h.publicRoomFromRequest(w, r)
+	if room == nil {
+		return
+	}
+	seat, ok := overlaySeatFromRequest(w, r, room)
+	if !ok {
+		return
+	}
+	requesterDeviceID, err := requesterDeviceIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if room.ownerDeviceID == "" || room.ownerDeviceID != requesterDeviceID {
+		http.Error(w, errDraftRoomForbidden.Error(), http.StatusForbidden)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(overlayTokenResponse{Token: room.overlayToken(seat)})
+}
+
+// handleOverlayEvents serves GET /api/draft/overlay/events?room_id=&seat=&token=,
+// an SSE feed of room progress plus the streamer seat's active pack.
+func (h *draftHub) handleOverlayEvents(w http.ResponseWriter, r *http.Request) {
+	room := h.publicRoomFromRequest(w, r)
+	if room == nil {
+		return
+	}
+	seat, ok := overlaySeatFromRequest(w, r, room)
+	if !ok {
+		return
+	}
+	if !room.validOverlayToken(seat, r.URL.Query().Get("token")) {
+		http.Error(w, "invalid overlay token", http.StatusForbidden)
+		return
+	}
+
+	h.serveEvents(w, r, func() ([]sseFrame, error) {
+		frame, err := room.overlayFrame(seat)
+		if err != nil {
+			return nil, err
+		}
+		return []sseFrame{{Data: frame}}, nil
+	})
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addTestRoom(t *testing.T, hub *draftHub, roomID string, cfg DraftConfig) *draftRoom {
+	t.Helper()
+	room := &draftRoom{
+		id:      roomID,
+		draft:   makeDraftWithConfig(t, cfg),
+		clients: make(map[int]map[*websocket.Conn]struct{}),
+	}
+	hub.mu.Lock()
+	hub.rooms[roomID] = room
+	hub.mu.Unlock()
+	return room
+}
+
+func TestDraftRoomProgressAndPools(t *testing.T) {
+	hub := newDraftHub()
+	room := addTestRoom(t, hub, "room-a", DraftConfig{PackCount: 1, PackSize: 1, SeatCount: 2})
+
+	st, err := room.draft.PlayerState(0)
+	require.NoError(t, err, "seat0 PlayerState")
+	_, err = room.draft.Pick(0, 1, st.Active.PackID, st.Active.Cards[0], PickZoneMainboard)
+	require.NoError(t, err, "seat0 pick")
+
+	progressRes := httptest.NewRecorder()
+	hub.handleRoomProgress(progressRes, httptest.NewRequest(http.MethodGet, "/api/draft/rooms/progress?room_id=room-a", nil))
+	require.Equal(t, http.StatusOK, progressRes.Code, "progress status")
+	var progress draftRoomProgress
+	require.NoError(t, json.Unmarshal(progressRes.Body.Bytes(), &progress), "decode progress")
+	assert.Equal(t, []int{1}, progress.PendingSeats, "pending seats")
+	require.Len(t, progress.Seats, 2, "seat progress length")
+	assert.True(t, progress.Seats[0].Picked, "seat 0 should be marked picked")
+	assert.NotContains(t, progressRes.Body.String(), st.Active.Cards[0], "progress must not leak picks")
+
+	poolsRes := httptest.NewRecorder()
+	hub.handleRoomPools(poolsRes, httptest.NewRequest(http.MethodGet, "/api/draft/rooms/pools?room_id=room-a", nil))
+	assert.Equal(t, http.StatusConflict, poolsRes.Code, "pools should wait for the draft to finish")
+
+	_, err = room.draft.randomPickBatchForSeat(1, PickZoneMainboard)
+	require.NoError(t, err, "seat1 pick")
+
+	poolsRes = httptest.NewRecorder()
+	hub.handleRoomPools(poolsRes, httptest.NewRequest(http.MethodGet, "/api/draft/rooms/pools?room_id=room-a", nil))
+	require.Equal(t, http.StatusOK, poolsRes.Code, "pools status")
+	var pools draftRoomPools
+	require.NoError(t, json.Unmarshal(poolsRes.Body.Bytes(), &pools), "decode pools")
+	require.Len(t, pools.Seats, 2, "pool seats")
+	assert.Equal(t, []string{st.Active.Cards[0]}, pools.Seats[0].Picks.Mainboard, "seat 0 pool")
+
+	missingRes := httptest.NewRecorder()
+	hub.handleRoomProgress(missingRes, httptest.NewRequest(http.MethodGet, "/api/draft/rooms/progress?room_id=nope", nil))
+	assert.Equal(t, http.StatusNotFound, missingRes.Code, "missing room status")
+}
+
+func TestDraftOverlayEventsStreamStreamerPack(t *testing.T) {
+	hub := newDraftHub()
+	room := addTestRoom(t, hub, "room-a", DraftConfig{PackCount: 1, PackSize: 2, SeatCount: 2})
+	room.ownerDeviceID = "host"
+	srv := httptest.NewServer(http.HandlerFunc(hub.handleOverlayEvents))
+	defer srv.Close()
+
+	tokenReq := httptest.NewRequest(http.MethodGet, "/api/draft/overlay/token?room_id=room-a&seat=1", nil)
+	tokenReq.Header.Set("X-Device-ID", "guest")
+	tokenRes := httptest.NewRecorder()
+	hub.handleOverlayToken(tokenRes, tokenReq)
+	assert.Equal(t, http.StatusForbidden, tokenRes.Code, "only the room owner fetches tokens over HTTP")
+	tokenReq.Header.Set("X-Device-ID", "host")
+	tokenRes = httptest.NewRecorder()
+	hub.handleOverlayToken(tokenRes, tokenReq)
+	require.Equal(t, http.StatusOK, tokenRes.Code, "owner token status")
+	var token overlayTokenResponse
+	require.NoError(t, json.Unmarshal(tokenRes.Body.Bytes(), &token), "decode token")
+	assert.Equal(t, room.overlayToken(1), token.Token, "token is stable per seat")
+	assert.NotEqual(t, room.overlayToken(0), token.Token, "tokens differ per seat")
+
+	for name, query := range map[string]string{
+		"missing token": "?room_id=room-a&seat=1",
+		"other seat":    "?room_id=room-a&seat=0&token=" + token.Token,
+		"wrong token":   "?room_id=room-a&seat=1&token=nope",
+	} {
+		res := httptest.NewRecorder()
+		hub.handleOverlayEvents(res, httptest.NewRequest(http.MethodGet, "/api/draft/overlay/events"+query, nil))
+		assert.Equal(t, http.StatusForbidden, res.Code, name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"?room_id=room-a&seat=1&token="+token.Token, nil)
+	require.NoError(t, err, "new request")
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err, "open overlay stream")
+	defer res.Body.Close()
+	require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"), "content type")
+
+	lines := bufio.NewScanner(res.Body)
+	nextFrame := func() draftOverlayFrame {
+		for lines.Scan() {
+			line := lines.Text()
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+			var frame draftOverlayFrame
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &frame), "decode frame")
+			return frame
+		}
+		require.FailNow(t, "stream ended", "%v", lines.Err())
+		return draftOverlayFrame{}
+	}
+
+	frame := nextFrame()
+	assert.Equal(t, 1, frame.StreamerSeat, "streamer seat")
+	require.NotNil(t, frame.Pack, "streamer pack")
+	assert.Len(t, frame.Pack.Cards, 2, "streamer pack size")
+	assert.Equal(t, []int{0, 1}, frame.Progress.PendingSeats, "pending seats before picks")
+
+	room.mu.Lock()
+	_, err = room.draft.randomPickBatchForSeat(0, PickZoneMainboard)
+	room.mu.Unlock()
+	require.NoError(t, err, "seat0 pick")
+	hub.notifyLobbySubscribers("room-a")
+
+	frame = nextFrame()
+	assert.Equal(t, []int{1}, frame.Progress.PendingSeats, "pending seats after seat 0 picks")
+}
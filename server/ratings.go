@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Ratings are Elo ratings per battlebox for player display names and deck
+// slugs. They are always derived from the raw log: every recorded battlebox
+// match and every draft event match whose cube belongs to the battlebox is
+// replayed in play order, and the result replaces the stored ratings. Writes to
+// the log trigger the replay, so ratings never drift from the matches.
+
+const (
+	ratingKindPlayer = "player"
+	ratingKindDeck   = "deck"
+	ratingInitial    = 1500
+	ratingK          = 32
+	// Ratings with fewer matches are marked provisional.
+	ratingProvisionalMatches = 5
+)
+
+type rating struct {
+	Kind string `json:"-"`
+	// Key is the lowercased name, so one player typed two ways shares a rating.
+	Key    string  `json:"-"`
+	Name   string  `json:"name"`
+	Rating float64 `json:"rating"`
+	matchTally
+	Provisional bool `json:"provisional,omitempty"`
+	Rank        int  `json:"rank"`
+}
+
+type ratingLeaderboard struct {
+	Battlebox string   `json:"battlebox"`
+	Players   []rating `json:"players"`
+	Decks     []rating `json:"decks"`
+}
+
+// ratedMatch is one match of the raw log, seen from the first player's side.
+type ratedMatch struct {
+	PlayedAt time.Time
+	Names    [2]string
+	Decks    [2]string
+	Wins     [2]int
+}
+
+// ratedMatchLog orders battlebox results (most recent first, as loaded) and
+// draft event matches by play time. Event matches count as played when the
+// event was created, in reporting order.
+func ratedMatchLog(results []matchResult, events []draftEventRecord) []ratedMatch {
+	matches := make([]ratedMatch, 0, len(results))
+	for i := len(results) - 1; i >= 0; i-- {
+		result := results[i]
+		matches = append(matches, ratedMatch{
+			PlayedAt: result.PlayedAt,
+			Names:    [2]string{result.P1Name, result.P2Name},
+			Decks:    [2]string{result.P1Deck, result.P2Deck},
+			Wins:     [2]int{result.P1Wins, result.P2Wins},
+		})
+	}
+	for _, event := range events {
+		for _, match := range event.Matches {
+			rated := ratedMatch{PlayedAt: event.CreatedAt, Wins: match.Wins}
+			for side, playerID := range match.Players {
+				if idx := eventPlayerIndex(event, playerID); idx >= 0 {
+					rated.Names[side] = event.Players[idx].Name
+				}
+				// Both players draft the same cube, so deck ratings skip these.
+				rated.Decks[side] = event.DeckSlug
+			}
+			matches = append(matches, rated)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].PlayedAt.Before(matches[j].PlayedAt)
+	})
+	return matches
+}
+
+// computeRatings replays matches in order. A side is skipped for a kind when
+// either name is missing or both name the same player or deck.
+func computeRatings(matches []ratedMatch) []rating {
+	byKey := map[string]*rating{}
+	update := func(kind string, names [2]string, wins [2]int) {
+		keys := [2]string{strings.ToLower(strings.TrimSpace(names[0])), strings.ToLower(strings.TrimSpace(names[1]))}
+		if keys[0] == "" || keys[1] == "" || keys[0] == keys[1] {
+			return
+		}
+		var sides [2]*rating
+		for i, key := range keys {
+			r := byKey[kind+"\x00"+key]
+			if r == nil {
+				r = &rating{Kind: kind, Key: key, Rating: ratingInitial}
+				byKey[kind+"\x00"+key] = r
+			}
+			r.Name = strings.TrimSpace(names[i])
+			sides[i] = r
+		}
+		expected := 1 / (1 + math.Pow(10, (sides[1].Rating-sides[0].Rating)/400))
+		score := 0.5
+		switch {
+		case wins[0] > wins[1]:
+			score = 1
+		case wins[0] < wins[1]:
+			score = 0
+		}
+		delta := ratingK * (score - expected)
+		sides[0].Rating += delta
+		sides[1].Rating -= delta
+		sides[0].add(wins[0], wins[1])
+		sides[1].add(wins[1], wins[0])
+	}
+	for _, match := range matches {
+		update(ratingKindPlayer, match.Names, match.Wins)
+		update(ratingKindDeck, match.Decks, match.Wins)
+	}
+
+	ratings := make([]rating, 0, len(byKey))
+	for _, r := range byKey {
+		r.Rating = math.Round(r.Rating*10) / 10
+		ratings = append(ratings, *r)
+	}
+	sort.Slice(ratings, func(i, j int) bool {
+		if ratings[i].Kind != ratings[j].Kind {
+			return ratings[i].Kind < ratings[j].Kind
+		}
+		if ratings[i].Rating != ratings[j].Rating {
+			return ratings[i].Rating > ratings[j].Rating
+		}
+		return ratings[i].Key < ratings[j].Key
+	})
+	return ratings
+}
+
+// recomputeRatings replays the battlebox's raw match log and stores the result.
+func recomputeRatings(ctx context.Context, store *draftRoomStore, battlebox string) ([]rating, error) {
+	results, err := store.LoadMatchResults(ctx, battlebox)
+	if err != nil {
+		return nil, err
+	}
+	allEvents, err := store.LoadEvents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Resolve the battlebox's decks once rather than per event; an unknown
+	// battlebox simply has no events.
+	decks, _ := matchDecks.BattleboxDecks(battlebox)
+	var events []draftEventRecord
+	for _, event := range allEvents {
+		if slices.Contains(decks, event.DeckSlug) {
+			events = append(events, event)
+		}
+	}
+	ratings := computeRatings(ratedMatchLog(results, events))
+	if err := store.ReplaceRatings(ctx, battlebox, ratings); err != nil {
+		return nil, err
+	}
+	return ratings, nil
+}
+
+// refreshRatings recomputes after a write to the match log. The write has
+// already succeeded, so failures are only logged.
+func refreshRatings(ctx context.Context, store *draftRoomStore, battlebox string) {
+	if _, err := recomputeRatings(ctx, store, battlebox); err != nil {
+		log.Printf("Failed to recompute ratings for %s: %v", battlebox, err)
+	}
+}
+
+// refreshEventRatings recomputes the ratings of the battlebox holding the
+// event's cube after a draft event match is reported.
+func (h *draftHub) refreshEventRatings(ctx context.Context, event draftEventRecord) {
+	h.mu.RLock()
+	store := h.roomStore
+	h.mu.RUnlock()
+	if store == nil {
+		return
+	}
+	battlebox, err := matchDecks.DeckBattlebox(event.DeckSlug)
+	if err != nil {
+		log.Printf("Failed to find the battlebox of event %s: %v", event.EventID, err)
+		return
+	}
+	refreshRatings(ctx, store, battlebox)
+}
+
+func newRatingLeaderboard(battlebox string, ratings []rating) ratingLeaderboard {
+	board := ratingLeaderboard{Battlebox: battlebox, Players: []rating{}, Decks: []rating{}}
+	for _, r := range ratings {
+		r.Provisional = r.Matches < ratingProvisionalMatches
+		switch r.Kind {
+		case ratingKindPlayer:
+			r.Rank = len(board.Players) + 1
+			board.Players = append(board.Players, r)
+		case ratingKindDeck:
+			r.Rank = len(board.Decks) + 1
+			board.Decks = append(board.Decks, r)
+		}
+	}
+	return board
+}
+
+// handleRatings serves GET /api/game/ratings?battlebox=<slug> (the stored
+// leaderboards) and POST /api/game/ratings/recompute?battlebox=<slug> (replay
+// the raw match log first).
+func (h *gameHub) handleRatings(w http.ResponseWriter, r *http.Request) {
+	recompute := r.URL.Path == "/api/game/ratings/recompute"
+	method := http.MethodGet
+	if recompute {
+		method = http.MethodPost
+	}
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	battlebox := normalizeSlug(r.URL.Query().Get("battlebox"))
+	if battlebox == "" {
+		http.Error(w, "battlebox query param required", http.StatusBadRequest)
+		return
+	}
+	store := h.matchStore(w)
+	if store == nil {
+		return
+	}
+	var ratings []rating
+	var err error
+	if recompute {
+		ratings, err = recomputeRatings(r.Context(), store, battlebox)
+	} else {
+		ratings, err = store.LoadRatings(r.Context(), battlebox)
+	}
+	if err != nil {
+		http.Error(w, "failed to load ratings", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(newRatingLeaderboard(battlebox, ratings))
+}
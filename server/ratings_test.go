@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeRatings(t *testing.T) {
+	start := time.Date(2026, 10, 1, 19, 0, 0, 0, time.UTC)
+	results := []matchResult{
+		{P1Deck: "elves", P2Deck: "tron", P1Wins: 1, P2Wins: 1, P1Name: "Bo", P2Name: "Cy", PlayedAt: start.Add(2 * time.Hour)},
+		{P1Deck: "affinity", P2Deck: "elves", P1Wins: 2, P2Wins: 0, P1Name: "Ada", P2Name: "bo", PlayedAt: start},
+	}
+	events := []draftEventRecord{{
+		DeckSlug:  "cube",
+		CreatedAt: start.Add(time.Hour),
+		Players:   []draftEventPlayer{{PlayerID: "ada", Name: "Ada"}, {PlayerID: "cy", Name: "Cy"}},
+		Matches:   []draftEventMatch{{Round: 1, Players: [2]string{"cy", "ada"}, Wins: [2]int{2, 1}}},
+	}}
+	matches := ratedMatchLog(results, events)
+	require.Len(t, matches, 3, "every match replayed")
+	assert.Equal(t, "affinity", matches[0].Decks[0], "oldest result first")
+	assert.Equal(t, "cube", matches[1].Decks[0], "event match between the results")
+
+	byKey := map[string]rating{}
+	var playerSum float64
+	for _, r := range computeRatings(matches) {
+		byKey[r.Kind+":"+r.Key] = r
+		if r.Kind == ratingKindPlayer {
+			playerSum += r.Rating
+		}
+	}
+	assert.InDelta(t, 3*ratingInitial, playerSum, 0.2, "ratings are zero-sum")
+	ada := byKey["player:ada"]
+	assert.Equal(t, 1499.3, ada.Rating, "ada gained 16 at even odds, then lost 16.7 as the favourite")
+	assert.Equal(t, matchTally{Matches: 2, Wins: 1, Losses: 1, GamesWon: 3, GamesLost: 2}, ada.matchTally, "ada tally")
+	assert.Equal(t, "Bo", byKey["player:bo"].Name, "names merge case-insensitively and keep the latest spelling")
+	assert.Equal(t, 1, byKey["player:bo"].Draws, "bo drew with cy")
+	assert.Equal(t, 1516.0, byKey["deck:affinity"].Rating, "affinity beat elves")
+	_, ok := byKey["deck:cube"]
+	assert.False(t, ok, "draft mirrors leave deck ratings alone")
+}
+
+func TestRatingsAPI(t *testing.T) {
+	useFakeMatchDecks(t)
+	store, err := openDraftRoomStore(filepath.Join(t.TempDir(), "db.sqlite"))
+	require.NoError(t, err, "openDraftRoomStore")
+	defer func() {
+		_ = store.Close()
+	}()
+
+	hub := newGameHub()
+	hub.setStore(store)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/game/matches", hub.handleMatches)
+	mux.HandleFunc("/api/game/ratings", hub.handleRatings)
+	mux.HandleFunc("/api/game/ratings/recompute", hub.handleRatings)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	getBoard := func(method, path string) ratingLeaderboard {
+		t.Helper()
+		resp := doMatchRequest(t, srv, method, path, "ada", "")
+		require.Equal(t, http.StatusOK, resp.StatusCode, "%s %s", method, path)
+		var board ratingLeaderboard
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&board), "decode leaderboard")
+		return board
+	}
+
+	resp := doMatchRequest(t, srv, http.MethodPost, "/api/game/matches", "ada", `{"battlebox":"pauper","p1_deck":"affinity","p2_deck":"elves","p1_wins":2,"on_play":"p1","p1_name":"Ada","p2_name":"Bo"}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, "record match")
+	var recorded matchResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&recorded), "decode result")
+
+	board := getBoard(http.MethodGet, "/api/game/ratings?battlebox=pauper")
+	require.Len(t, board.Players, 2, "both players rated")
+	assert.Equal(t, "Ada", board.Players[0].Name, "winner ranked first")
+	assert.Equal(t, 1, board.Players[0].Rank, "rank")
+	assert.True(t, board.Players[0].Provisional, "one match is provisional")
+	require.Len(t, board.Decks, 2, "both decks rated")
+	assert.Equal(t, "affinity", board.Decks[0].Name, "winning deck first")
+
+	require.NoError(t, store.SaveEvent(context.Background(), draftEventRecord{
+		EventID:   "event-1",
+		DeckSlug:  "cube",
+		CreatedAt: time.Now(),
+		Players:   []draftEventPlayer{{PlayerID: "ada", Name: "Ada"}, {PlayerID: "cy", Name: "Cy"}},
+		Matches:   []draftEventMatch{{Round: 1, Players: [2]string{"ada", "cy"}, Wins: [2]int{0, 2}}},
+	}), "SaveEvent")
+	assert.Empty(t, getBoard(http.MethodGet, "/api/game/ratings?battlebox=cube").Players, "nothing stored before a recompute")
+	board = getBoard(http.MethodPost, "/api/game/ratings/recompute?battlebox=cube")
+	require.Len(t, board.Players, 2, "draft event players rated under the cube's battlebox")
+	assert.Equal(t, "Cy", board.Players[0].Name, "cy won the draft match")
+	assert.Empty(t, board.Decks, "draft matches rate no decks")
+
+	resp = doMatchRequest(t, srv, http.MethodDelete, fmt.Sprintf("/api/game/matches?match_id=%d", recorded.MatchID), "ada", "")
+	require.Equal(t, http.StatusNoContent, resp.StatusCode, "delete match")
+	board = getBoard(http.MethodGet, "/api/game/ratings?battlebox=pauper")
+	assert.Empty(t, board.Players, "deleting the only match clears the ratings")
+
+	resp = doMatchRequest(t, srv, http.MethodGet, "/api/game/ratings/recompute?battlebox=pauper", "ada", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode, "recompute is POST only")
+	resp = doMatchRequest(t, srv, http.MethodGet, "/api/game/ratings", "ada", "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "battlebox required")
+}
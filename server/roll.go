@@ -0,0 +1,264 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lxing/battlebox/internal/buildtool"
+)
+
+// The matchup roller picks one deck or an ordered pair of decks for a battlebox
+// under tag, recency and winrate constraints. Candidates are enumerated in
+// battlebox order and drawn with a PRNG seeded from the request, so the same battlebox
+// build, constraints and seed always produce the same roll.
+
+const (
+	matchupRollMaxRecent = 20
+	// Roll PRNG stream, kept apart from draft and library streams.
+	matchupRollStream = 0x726f6c6c
+)
+
+var errMatchupRollNoCandidates = errors.New("no decks satisfy the roll constraints")
+
+type matchupRollRequest struct {
+	Battlebox string
+	Count     int
+	// A deck qualifies when it carries any of the listed difficulty tags and
+	// any of the listed archetype tags; empty lists allow every deck.
+	Difficulty []string
+	Tags       []string
+	Exclude    []string
+	// MinWR and MaxWR bound the first deck's matrix winrate against the second.
+	MinWR *float64
+	MaxWR *float64
+	Seed  uint64
+}
+
+type matchupRoll struct {
+	Battlebox string   `json:"battlebox"`
+	Seed      uint64   `json:"seed"`
+	Decks     []string `json:"decks"`
+	// WR and Matches are decks[0]'s matrix record against decks[1].
+	WR      *float64 `json:"wr,omitempty"`
+	Matches int      `json:"matches,omitempty"`
+	// Excluded lists every deck left out, including recently played ones. Rolling
+	// again with exclude=<excluded> and the same seed reproduces this roll.
+	Excluded   []string `json:"excluded,omitempty"`
+	Candidates int      `json:"candidates"`
+}
+
+func rollTagMatches(tags, wanted []string) bool {
+	if len(wanted) == 0 {
+		return true
+	}
+	for _, tag := range tags {
+		if slices.Contains(wanted, strings.ToLower(strings.TrimSpace(tag))) {
+			return true
+		}
+	}
+	return false
+}
+
+// rollMatchupCell returns a's matrix cell against b, falling back to the
+// complement of b's cell against a.
+func rollMatchupCell(matchups map[string]map[string]buildtool.WinrateCell, a, b string) (float64, int, bool) {
+	if cell, ok := matchups[a][b]; ok {
+		return cell.WR, cell.Matches, true
+	}
+	if cell, ok := matchups[b][a]; ok {
+		return 1 - cell.WR, cell.Matches, true
+	}
+	return 0, 0, false
+}
+
+// rollMatchup draws from pool under req.
+func rollMatchup(pool rollPool, req matchupRollRequest) (matchupRoll, error) {
+	roll := matchupRoll{Battlebox: req.Battlebox, Seed: req.Seed, Decks: []string{}}
+	var eligible []string
+	for _, deck := range pool.Decks {
+		if slices.Contains(req.Exclude, deck.Slug) {
+			roll.Excluded = append(roll.Excluded, deck.Slug)
+			continue
+		}
+		if rollTagMatches(deck.DifficultyTags, req.Difficulty) && rollTagMatches(deck.Tags, req.Tags) {
+			eligible = append(eligible, deck.Slug)
+		}
+	}
+
+	rng := newDraftRand(req.Seed, matchupRollStream)
+	if req.Count == 1 {
+		roll.Candidates = len(eligible)
+		if len(eligible) == 0 {
+			return roll, errMatchupRollNoCandidates
+		}
+		roll.Decks = append(roll.Decks, eligible[rng.IntN(len(eligible))])
+		return roll, nil
+	}
+
+	type pair struct {
+		a, b string
+	}
+	var pairs []pair
+	for _, a := range eligible {
+		for _, b := range eligible {
+			if a == b {
+				continue
+			}
+			if req.MinWR != nil || req.MaxWR != nil {
+				wr, _, ok := rollMatchupCell(pool.Matchups, a, b)
+				if !ok || (req.MinWR != nil && wr < *req.MinWR) || (req.MaxWR != nil && wr > *req.MaxWR) {
+					continue
+				}
+			}
+			pairs = append(pairs, pair{a, b})
+		}
+	}
+	roll.Candidates = len(pairs)
+	if len(pairs) == 0 {
+		return roll, errMatchupRollNoCandidates
+	}
+	picked := pairs[rng.IntN(len(pairs))]
+	roll.Decks = append(roll.Decks, picked.a, picked.b)
+	if wr, matches, ok := rollMatchupCell(pool.Matchups, picked.a, picked.b); ok {
+		roll.WR = &wr
+		roll.Matches = matches
+	}
+	return roll, nil
+}
+
+func parseRollList(raw string) []string {
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.ToLower(strings.TrimSpace(part)); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func parseRollWinrate(raw, name string) (*float64, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	wr, err := strconv.ParseFloat(raw, 64)
+	if err != nil || wr < 0 || wr > 1 {
+		return nil, fmt.Errorf("%s must be between 0 and 1", name)
+	}
+	return &wr, nil
+}
+
+// parseMatchupRollRequest reads the roll constraints from the query string.
+// exclude_recent is resolved by the handler since it needs the match store.
+func parseMatchupRollRequest(r *http.Request) (matchupRollRequest, int, error) {
+	query := r.URL.Query()
+	req := matchupRollRequest{
+		Battlebox:  normalizeSlug(query.Get("battlebox")),
+		Count:      2,
+		Difficulty: parseRollList(query.Get("difficulty")),
+		Tags:       parseRollList(query.Get("tag")),
+		Exclude:    parseRollList(query.Get("exclude")),
+		Seed:       randomDraftSeed(),
+	}
+	if req.Battlebox == "" {
+		return req, 0, errors.New("battlebox query param required")
+	}
+	if raw := query.Get("count"); raw != "" {
+		count, err := strconv.Atoi(raw)
+		if err != nil || (count != 1 && count != 2) {
+			return req, 0, errors.New("count must be 1 or 2")
+		}
+		req.Count = count
+	}
+	var err error
+	if req.MinWR, err = parseRollWinrate(query.Get("min_wr"), "min_wr"); err != nil {
+		return req, 0, err
+	}
+	if req.MaxWR, err = parseRollWinrate(query.Get("max_wr"), "max_wr"); err != nil {
+		return req, 0, err
+	}
+	if req.MinWR != nil && req.MaxWR != nil && *req.MinWR > *req.MaxWR {
+		return req, 0, errors.New("min_wr must not exceed max_wr")
+	}
+	if (req.MinWR != nil || req.MaxWR != nil) && req.Count != 2 {
+		return req, 0, errors.New("a winrate band needs count=2")
+	}
+	if raw := query.Get("seed"); raw != "" {
+		seed, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return req, 0, errors.New("seed must be a non-negative integer")
+		}
+		req.Seed = seed
+	}
+	recent := 0
+	if raw := query.Get("exclude_recent"); raw != "" {
+		recent, err = strconv.Atoi(raw)
+		if err != nil || recent < 0 || recent > matchupRollMaxRecent {
+			return req, 0, fmt.Errorf("exclude_recent must be between 0 and %d", matchupRollMaxRecent)
+		}
+	}
+	return req, recent, nil
+}
+
+// handleMatchupRoll serves GET /api/game/roll. exclude_recent=<n> leaves out
+// the decks in the requesting device's last n recorded matches.
+func (h *gameHub) handleMatchupRoll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	req, recent, err := parseMatchupRollRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	pool, err := rollDecks.RollPool(req.Battlebox)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if !pool.RandomRollEnabled || (req.Count == 2 && pool.DisableDoubleRandomRoll) {
+		http.Error(w, "random rolls are disabled for this battlebox", http.StatusBadRequest)
+		return
+	}
+
+	if recent > 0 {
+		requesterDeviceID, err := requesterDeviceIDFromRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		store := h.matchStore(w)
+		if store == nil {
+			return
+		}
+		results, err := store.LoadMatchResults(r.Context(), req.Battlebox)
+		if err != nil {
+			http.Error(w, "failed to load match results", http.StatusInternalServerError)
+			return
+		}
+		for _, result := range results {
+			if recent == 0 {
+				break
+			}
+			if result.RecorderDeviceID != requesterDeviceID {
+				continue
+			}
+			req.Exclude = append(req.Exclude, result.P1Deck, result.P2Deck)
+			recent--
+		}
+	}
+
+	roll, err := rollMatchup(pool, req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(roll)
+}
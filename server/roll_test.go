@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/lxing/battlebox/internal/buildtool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRollDecks struct{}
+
+func (fakeRollDecks) RollPool(battleboxSlug string) (rollPool, error) {
+	if battleboxSlug != "pauper" {
+		return rollPool{}, fmt.Errorf("battlebox %q not found", battleboxSlug)
+	}
+	return rollPool{
+		RandomRollEnabled: true,
+		Decks: []rollDeck{
+			{Slug: "affinity", Tags: []string{"aggro"}, DifficultyTags: []string{"intermediate"}},
+			{Slug: "elves", Tags: []string{"combo"}, DifficultyTags: []string{"beginner"}},
+			{Slug: "tron", Tags: []string{"control"}, DifficultyTags: []string{"expert"}},
+			{Slug: "burn", Tags: []string{"aggro"}, DifficultyTags: []string{"beginner"}},
+		},
+		Matchups: map[string]map[string]buildtool.WinrateCell{
+			"affinity": {"elves": {WR: 0.6, Matches: 40}, "tron": {WR: 0.48, Matches: 30}, "burn": {WR: 0.7, Matches: 25}},
+			"elves":    {"tron": {WR: 0.35, Matches: 22}, "burn": {WR: 0.52, Matches: 50}},
+			"tron":     {"burn": {WR: 0.4, Matches: 18}},
+		},
+	}, nil
+}
+
+func useFakeRollDecks(t *testing.T) {
+	t.Helper()
+	previous := rollDecks
+	rollDecks = fakeRollDecks{}
+	t.Cleanup(func() { rollDecks = previous })
+}
+
+func TestRollMatchup(t *testing.T) {
+	pool, err := fakeRollDecks{}.RollPool("pauper")
+	require.NoError(t, err, "RollPool")
+
+	first, err := rollMatchup(pool, matchupRollRequest{Battlebox: "pauper", Count: 2, Seed: 42})
+	require.NoError(t, err, "roll")
+	again, err := rollMatchup(pool, matchupRollRequest{Battlebox: "pauper", Count: 2, Seed: 42})
+	require.NoError(t, err, "repeat roll")
+	assert.Equal(t, first, again, "same seed rolls the same")
+	assert.Equal(t, 12, first.Candidates, "every ordered pair is a candidate")
+	require.Len(t, first.Decks, 2, "two decks rolled")
+	assert.NotEqual(t, first.Decks[0], first.Decks[1], "decks differ")
+
+	low, high := 0.45, 0.55
+	for seed := uint64(0); seed < 20; seed++ {
+		roll, err := rollMatchup(pool, matchupRollRequest{Battlebox: "pauper", Count: 2, MinWR: &low, MaxWR: &high, Seed: seed})
+		require.NoError(t, err, "banded roll")
+		assert.Equal(t, 4, roll.Candidates, "affinity-tron and elves-burn fall in the band both ways")
+		require.NotNil(t, roll.WR, "banded roll reports the winrate")
+		assert.GreaterOrEqual(t, *roll.WR, low, "winrate at least the band minimum")
+		assert.LessOrEqual(t, *roll.WR, high, "winrate at most the band maximum")
+	}
+
+	roll, err := rollMatchup(pool, matchupRollRequest{Battlebox: "pauper", Count: 1, Difficulty: []string{"beginner"}, Tags: []string{"aggro"}, Seed: 7})
+	require.NoError(t, err, "tagged roll")
+	assert.Equal(t, []string{"burn"}, roll.Decks, "only burn is a beginner aggro deck")
+
+	roll, err = rollMatchup(pool, matchupRollRequest{Battlebox: "pauper", Count: 2, Difficulty: []string{"beginner"}, Exclude: []string{"burn"}, Seed: 7})
+	assert.ErrorIs(t, err, errMatchupRollNoCandidates, "one beginner deck left cannot make a pair")
+	assert.Equal(t, []string{"burn"}, roll.Excluded, "excluded decks reported")
+}
+
+func TestMatchupRollAPI(t *testing.T) {
+	useFakeRollDecks(t)
+	useFakeMatchDecks(t)
+	store, err := openDraftRoomStore(filepath.Join(t.TempDir(), "db.sqlite"))
+	require.NoError(t, err, "openDraftRoomStore")
+	defer func() {
+		_ = store.Close()
+	}()
+
+	hub := newGameHub()
+	hub.setStore(store)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/game/matches", hub.handleMatches)
+	mux.HandleFunc("/api/game/roll", hub.handleMatchupRoll)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	resp := doMatchRequest(t, srv, http.MethodPost, "/api/game/matches", "ada", `{"battlebox":"pauper","p1_deck":"affinity","p2_deck":"elves","p1_wins":2,"on_play":"p1"}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, "record match")
+
+	resp = doMatchRequest(t, srv, http.MethodGet, "/api/game/roll?battlebox=pauper&exclude_recent=1&seed=9", "ada", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, "roll")
+	var roll matchupRoll
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&roll), "decode roll")
+	assert.Equal(t, uint64(9), roll.Seed, "seed echoed")
+	assert.Equal(t, []string{"affinity", "elves"}, roll.Excluded, "recently played decks excluded")
+	assert.ElementsMatch(t, []string{"tron", "burn"}, roll.Decks, "only tron and burn remain")
+
+	resp = doMatchRequest(t, srv, http.MethodGet, "/api/game/roll?battlebox=pauper&exclude=affinity,elves&seed=9", "bo", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, "confirming roll")
+	var confirmed matchupRoll
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&confirmed), "decode confirming roll")
+	assert.Equal(t, roll, confirmed, "another device reproduces the roll")
+
+	resp = doMatchRequest(t, srv, http.MethodGet, "/api/game/roll?battlebox=pauper&exclude_recent=1", "bo", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, "roll without history")
+	roll = matchupRoll{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&roll), "decode roll")
+	assert.Empty(t, roll.Excluded, "other devices' matches are not excluded")
+
+	for name, query := range map[string]string{
+		"band without pair": "battlebox=pauper&count=1&min_wr=0.4",
+		"inverted band":     "battlebox=pauper&min_wr=0.6&max_wr=0.4",
+		"bad count":         "battlebox=pauper&count=3",
+		"missing battlebox": "count=2",
+	} {
+		resp = doMatchRequest(t, srv, http.MethodGet, "/api/game/roll?"+query, "ada", "")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, name)
+	}
+	resp = doMatchRequest(t, srv, http.MethodGet, "/api/game/roll?battlebox=modern", "ada", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "unknown battlebox")
+	resp = doMatchRequest(t, srv, http.MethodGet, "/api/game/roll?battlebox=pauper&min_wr=0.9", "ada", "")
+	assert.Equal(t, http.StatusConflict, resp.StatusCode, "no pair in the band")
+}
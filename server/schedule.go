@@ -0,0 +1,568 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/websocket"
+)
+
+// A scheduled draft announces a cube draft ahead of time. Players RSVP with a
+// display name, and when the start time arrives the scheduler opens a room in
+// the waiting state for the organiser to start once everyone is seated.
+
+type draftScheduleRecord struct {
+	ScheduleID      string              `json:"schedule_id"`
+	Title           string              `json:"title"`
+	DeckSlug        string              `json:"deck_slug"`
+	Preset          string              `json:"preset"`
+	SeatCount       int                 `json:"seat_count"`
+	StartsAt        time.Time           `json:"starts_at"`
+	DurationMinutes int                 `json:"duration_minutes"`
+	OwnerDeviceID   string              `json:"owner_device_id"`
+	HostName        string              `json:"host_name,omitempty"`
+	CreatedAt       time.Time           `json:"created_at"`
+	RSVPs           []draftScheduleRSVP `json:"rsvps"`
+	RoomID          string              `json:"room_id,omitempty"`     // set once the room is opened
+	StartError      string              `json:"start_error,omitempty"` // set when the room could not be opened
+}
+
+type draftScheduleRSVP struct {
+	DeviceID    string    `json:"device_id"`
+	DisplayName string    `json:"display_name"`
+	RespondedAt time.Time `json:"responded_at"`
+}
+
+type draftScheduleAttendee struct {
+	DisplayName string    `json:"display_name"`
+	RespondedAt time.Time `json:"responded_at"`
+}
+
+// draftScheduleView is the public shape of a schedule. RSVP device ids stay on
+// the server; the requester only learns whether they are attending.
+type draftScheduleView struct {
+	ScheduleID         string                  `json:"schedule_id"`
+	Title              string                  `json:"title"`
+	DeckSlug           string                  `json:"deck_slug"`
+	Preset             string                  `json:"preset"`
+	SeatCount          int                     `json:"seat_count"`
+	StartsAt           time.Time               `json:"starts_at"`
+	DurationMinutes    int                     `json:"duration_minutes"`
+	HostName           string                  `json:"host_name,omitempty"`
+	CreatedAt          time.Time               `json:"created_at"`
+	State              string                  `json:"state"` // scheduled, open or failed
+	RoomID             string                  `json:"room_id,omitempty"`
+	StartError         string                  `json:"start_error,omitempty"`
+	Attendees          []draftScheduleAttendee `json:"attendees"`
+	OwnedByRequest     bool                    `json:"owned_by_requester"`
+	AttendingByRequest bool                    `json:"attending_by_requester"`
+}
+
+type listDraftSchedulesResponse struct {
+	Schedules []draftScheduleView `json:"schedules"`
+}
+
+type createDraftScheduleRequest struct {
+	Title           string    `json:"title"`
+	DeckSlug        string    `json:"deck_slug"`
+	Preset          string    `json:"preset"`
+	StartsAt        time.Time `json:"starts_at"`
+	DurationMinutes int       `json:"duration_minutes,omitempty"`
+	HostName        string    `json:"host_name,omitempty"`
+}
+
+type rsvpDraftScheduleRequest struct {
+	ScheduleID  string `json:"schedule_id"`
+	DisplayName string `json:"display_name"`
+	Attending   bool   `json:"attending"`
+}
+
+var (
+	errDraftScheduleNotFound = errors.New("schedule not found")
+	errDraftScheduleFull     = errors.New("schedule is full")
+)
+
+const (
+	defaultScheduleDurationMinutes = 180
+	maxScheduleDurationMinutes     = 24 * 60
+)
+
+func (h *draftHub) restoreSchedules(records []draftScheduleRecord) {
+	h.schedulesMu.Lock()
+	defer h.schedulesMu.Unlock()
+	for i := range records {
+		record := records[i]
+		h.schedules[record.ScheduleID] = &record
+	}
+}
+
+func (h *draftHub) saveSchedule(ctx context.Context, schedule draftScheduleRecord) error {
+	h.mu.RLock()
+	store := h.roomStore
+	h.mu.RUnlock()
+	if store == nil {
+		return nil
+	}
+	return store.SaveSchedule(ctx, schedule)
+}
+
+func cloneDraftSchedule(schedule draftScheduleRecord) draftScheduleRecord {
+	schedule.RSVPs = append([]draftScheduleRSVP(nil), schedule.RSVPs...)
+	return schedule
+}
+
+func (h *draftHub) createSchedule(ctx context.Context, requesterDeviceID string, req createDraftScheduleRequest, now time.Time) (draftScheduleRecord, error) {
+	deckSlug := normalizeSlug(req.DeckSlug)
+	if deckSlug == "" {
+		return draftScheduleRecord{}, errors.New("deck_slug required")
+	}
+	preset := strings.TrimSpace(req.Preset)
+	if preset == "" {
+		return draftScheduleRecord{}, errors.New("preset required")
+	}
+	if req.StartsAt.IsZero() || !req.StartsAt.After(now) {
+		return draftScheduleRecord{}, errors.New("starts_at must be in the future")
+	}
+	duration := req.DurationMinutes
+	if duration == 0 {
+		duration = defaultScheduleDurationMinutes
+	}
+	if duration < 0 || duration > maxScheduleDurationMinutes {
+		return draftScheduleRecord{}, fmt.Errorf("duration_minutes must be between 1 and %d", maxScheduleDurationMinutes)
+	}
+	cfg, _, err := draftDecks.DraftDeck(deckSlug, preset)
+	if err != nil {
+		return draftScheduleRecord{}, err
+	}
+	title := normalizeHostName(req.Title)
+	if title == "" {
+		title = deckSlug + " draft"
+	}
+
+	schedule := draftScheduleRecord{
+		Title:           title,
+		DeckSlug:        deckSlug,
+		Preset:          preset,
+		SeatCount:       cfg.SeatCount,
+		StartsAt:        req.StartsAt.UTC(),
+		DurationMinutes: duration,
+		OwnerDeviceID:   requesterDeviceID,
+		HostName:        normalizeHostName(req.HostName),
+		CreatedAt:       now,
+		RSVPs:           []draftScheduleRSVP{},
+	}
+
+	h.schedulesMu.Lock()
+	defer h.schedulesMu.Unlock()
+	schedule.ScheduleID = h.nextScheduleIDLocked()
+	if err := h.saveSchedule(ctx, schedule); err != nil {
+		return draftScheduleRecord{}, err
+	}
+	h.schedules[schedule.ScheduleID] = &schedule
+	return schedule, nil
+}
+
+func (h *draftHub) nextScheduleIDLocked() string {
+	for attempt := 0; attempt < 32; attempt++ {
+		candidate := "schedule-" + randomRoomID()
+		if _, exists := h.schedules[candidate]; !exists {
+			return candidate
+		}
+	}
+	return fmt.Sprintf("schedule-%d", time.Now().UnixNano())
+}
+
+func (h *draftHub) getSchedule(scheduleID string) (draftScheduleRecord, bool) {
+	h.schedulesMu.Lock()
+	defer h.schedulesMu.Unlock()
+	schedule := h.schedules[scheduleID]
+	if schedule == nil {
+		return draftScheduleRecord{}, false
+	}
+	return cloneDraftSchedule(*schedule), true
+}
+
+func (h *draftHub) listSchedules() []draftScheduleRecord {
+	h.schedulesMu.Lock()
+	defer h.schedulesMu.Unlock()
+	out := make([]draftScheduleRecord, 0, len(h.schedules))
+	for _, schedule := range h.schedules {
+		out = append(out, cloneDraftSchedule(*schedule))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].StartsAt.Equal(out[j].StartsAt) {
+			return out[i].StartsAt.Before(out[j].StartsAt)
+		}
+		return out[i].ScheduleID < out[j].ScheduleID
+	})
+	return out
+}
+
+// rsvpSchedule adds, renames or (when not attending) removes the requester's RSVP.
+func (h *draftHub) rsvpSchedule(ctx context.Context, requesterDeviceID string, req rsvpDraftScheduleRequest, now time.Time) (draftScheduleRecord, error) {
+	displayName := normalizeHostName(req.DisplayName)
+	if req.Attending && displayName == "" {
+		return draftScheduleRecord{}, errors.New("display_name required")
+	}
+
+	h.schedulesMu.Lock()
+	defer h.schedulesMu.Unlock()
+	current := h.schedules[req.ScheduleID]
+	if current == nil {
+		return draftScheduleRecord{}, errDraftScheduleNotFound
+	}
+	next := cloneDraftSchedule(*current)
+	existing := -1
+	for i, rsvp := range next.RSVPs {
+		if rsvp.DeviceID == requesterDeviceID {
+			existing = i
+			break
+		}
+	}
+	switch {
+	case !req.Attending && existing >= 0:
+		next.RSVPs = append(next.RSVPs[:existing], next.RSVPs[existing+1:]...)
+	case !req.Attending:
+		return next, nil
+	case existing >= 0:
+		next.RSVPs[existing].DisplayName = displayName
+	case len(next.RSVPs) >= next.SeatCount:
+		return draftScheduleRecord{}, errDraftScheduleFull
+	default:
+		next.RSVPs = append(next.RSVPs, draftScheduleRSVP{DeviceID: requesterDeviceID, DisplayName: displayName, RespondedAt: now})
+	}
+	if err := h.saveSchedule(ctx, next); err != nil {
+		return draftScheduleRecord{}, err
+	}
+	h.schedules[next.ScheduleID] = &next
+	return next, nil
+}
+
+// deleteSchedule removes a schedule. A room it already opened is left alone.
+func (h *draftHub) deleteSchedule(ctx context.Context, scheduleID, requesterDeviceID string) error {
+	h.schedulesMu.Lock()
+	defer h.schedulesMu.Unlock()
+	schedule := h.schedules[scheduleID]
+	if schedule == nil {
+		return errDraftScheduleNotFound
+	}
+	if schedule.OwnerDeviceID != requesterDeviceID {
+		return errDraftRoomForbidden
+	}
+	h.mu.RLock()
+	store := h.roomStore
+	h.mu.RUnlock()
+	if store != nil {
+		if err := store.DeleteSchedule(ctx, scheduleID); err != nil {
+			return err
+		}
+	}
+	delete(h.schedules, scheduleID)
+	return nil
+}
+
+// startDueSchedules opens a waiting room for every schedule whose start time has
+// passed and returns the new room ids. A schedule that cannot be opened, e.g.
+// because its cube was removed, records the error and is not retried.
+func (h *draftHub) startDueSchedules(ctx context.Context, now time.Time) []string {
+	h.schedulesMu.Lock()
+	started := make([]string, 0)
+	for _, scheduleID := range sortedScheduleIDsLocked(h.schedules) {
+		current := h.schedules[scheduleID]
+		if current.RoomID != "" || current.StartError != "" || current.StartsAt.After(now) {
+			continue
+		}
+		next := cloneDraftSchedule(*current)
+		if room, err := h.openScheduledRoom(next, now); err != nil {
+			next.StartError = err.Error()
+			log.Printf("Failed to open scheduled draft %s: %v", scheduleID, err)
+		} else {
+			next.RoomID = room.id
+			started = append(started, room.id)
+		}
+		if err := h.saveSchedule(ctx, next); err != nil {
+			log.Printf("Failed to save scheduled draft %s: %v", scheduleID, err)
+		}
+		h.schedules[scheduleID] = &next
+	}
+	h.schedulesMu.Unlock()
+
+	for _, roomID := range started {
+		h.notifyLobbySubscribers(roomID)
+	}
+	return started
+}
+
+func sortedScheduleIDsLocked(schedules map[string]*draftScheduleRecord) []string {
+	ids := make([]string, 0, len(schedules))
+	for id := range schedules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// openScheduledRoom creates the schedule's room in the waiting state. Scheduled
+// rooms are exempt from the one-room-per-device rule since the organiser may be
+// hosting another room when the start time arrives.
+func (h *draftHub) openScheduledRoom(schedule draftScheduleRecord, now time.Time) (*draftRoom, error) {
+	cfg, deckList, err := draftDecks.DraftDeck(schedule.DeckSlug, schedule.Preset)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Seed = randomDraftSeed()
+	draft, err := newWaitingDraft(cfg, deckList)
+	if err != nil {
+		return nil, err
+	}
+	room := &draftRoom{
+		deckSlug:      schedule.DeckSlug,
+		ownerDeviceID: schedule.OwnerDeviceID,
+		hostName:      schedule.HostName,
+		draft:         draft,
+		clients:       make(map[int]map[*websocket.Conn]struct{}),
+		createdAt:     now,
+		updatedAt:     now,
+	}
+	h.mu.Lock()
+	room.id = h.nextRoomIDLocked()
+	h.rooms[room.id] = room
+	h.mu.Unlock()
+	return room, nil
+}
+
+func scheduleView(schedule draftScheduleRecord, requesterDeviceID string) draftScheduleView {
+	view := draftScheduleView{
+		ScheduleID:      schedule.ScheduleID,
+		Title:           schedule.Title,
+		DeckSlug:        schedule.DeckSlug,
+		Preset:          schedule.Preset,
+		SeatCount:       schedule.SeatCount,
+		StartsAt:        schedule.StartsAt,
+		DurationMinutes: schedule.DurationMinutes,
+		HostName:        schedule.HostName,
+		CreatedAt:       schedule.CreatedAt,
+		State:           "scheduled",
+		RoomID:          schedule.RoomID,
+		StartError:      schedule.StartError,
+		Attendees:       make([]draftScheduleAttendee, 0, len(schedule.RSVPs)),
+		OwnedByRequest:  requesterDeviceID != "" && requesterDeviceID == schedule.OwnerDeviceID,
+	}
+	switch {
+	case schedule.RoomID != "":
+		view.State = "open"
+	case schedule.StartError != "":
+		view.State = "failed"
+	}
+	for _, rsvp := range schedule.RSVPs {
+		view.Attendees = append(view.Attendees, draftScheduleAttendee{DisplayName: rsvp.DisplayName, RespondedAt: rsvp.RespondedAt})
+		view.AttendingByRequest = view.AttendingByRequest || (requesterDeviceID != "" && rsvp.DeviceID == requesterDeviceID)
+	}
+	return view
+}
+
+// scheduleICS renders a schedule as an iCalendar (RFC 5545) file with one event.
+func scheduleICS(schedule draftScheduleRecord, now time.Time) string {
+	const icsTime = "20060102T150405Z"
+	description := fmt.Sprintf("Cube draft of %s (%s, %d seats).", schedule.DeckSlug, schedule.Preset, schedule.SeatCount)
+	if schedule.HostName != "" {
+		description += " Hosted by " + schedule.HostName + "."
+	}
+	if len(schedule.RSVPs) > 0 {
+		names := make([]string, 0, len(schedule.RSVPs))
+		for _, rsvp := range schedule.RSVPs {
+			names = append(names, rsvp.DisplayName)
+		}
+		description += "\nAttending: " + strings.Join(names, ", ")
+	}
+	end := schedule.StartsAt.Add(time.Duration(schedule.DurationMinutes) * time.Minute)
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//battlebox//draft schedule//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"BEGIN:VEVENT",
+		"UID:" + schedule.ScheduleID + "@battlebox",
+		"DTSTAMP:" + now.UTC().Format(icsTime),
+		"DTSTART:" + schedule.StartsAt.UTC().Format(icsTime),
+		"DTEND:" + end.UTC().Format(icsTime),
+		"SUMMARY:" + escapeICSText(schedule.Title),
+		"DESCRIPTION:" + escapeICSText(description),
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(foldICSLine(line))
+		b.WriteString("\r\n")
+	}
+	return b.String()
+}
+
+var icsTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeICSText(text string) string {
+	return icsTextEscaper.Replace(text)
+}
+
+// foldICSLine splits a content line into 75-octet chunks joined by CRLF and a
+// space, never splitting a UTF-8 sequence.
+func foldICSLine(line string) string {
+	const maxOctets = 75
+	if len(line) <= maxOctets {
+		return line
+	}
+	var b strings.Builder
+	limit := maxOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines lose one octet to the leading space.
+		limit = maxOctets - 1
+	}
+	b.WriteString(line)
+	return b.String()
+}
+
+func writeDraftScheduleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errDraftScheduleNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, errDraftRoomForbidden):
+		http.Error(w, "only the organiser may change this schedule", http.StatusForbidden)
+	case errors.Is(err, errDraftScheduleFull):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
+
+func writeScheduleView(w http.ResponseWriter, schedule draftScheduleRecord, requesterDeviceID string) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(scheduleView(schedule, requesterDeviceID))
+}
+
+// handleSchedules serves GET /api/draft/schedules (all schedules, or one with
+// schedule_id), POST to create and DELETE ?schedule_id= to cancel.
+func (h *draftHub) handleSchedules(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		requesterDeviceID, _ := requesterDeviceIDFromRequest(r)
+		if scheduleID := r.URL.Query().Get("schedule_id"); scheduleID != "" {
+			schedule, ok := h.getSchedule(scheduleID)
+			if !ok {
+				http.Error(w, "schedule not found", http.StatusNotFound)
+				return
+			}
+			writeScheduleView(w, schedule, requesterDeviceID)
+			return
+		}
+		schedules := h.listSchedules()
+		resp := listDraftSchedulesResponse{Schedules: make([]draftScheduleView, 0, len(schedules))}
+		for _, schedule := range schedules {
+			resp.Schedules = append(resp.Schedules, scheduleView(schedule, requesterDeviceID))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	case http.MethodPost:
+		requesterDeviceID, err := requesterDeviceIDFromRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+		var req createDraftScheduleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid json body", http.StatusBadRequest)
+			return
+		}
+		schedule, err := h.createSchedule(r.Context(), requesterDeviceID, req, time.Now())
+		if err != nil {
+			writeDraftScheduleError(w, err)
+			return
+		}
+		writeScheduleView(w, schedule, requesterDeviceID)
+	case http.MethodDelete:
+		scheduleID := r.URL.Query().Get("schedule_id")
+		if scheduleID == "" {
+			http.Error(w, "schedule_id query param required", http.StatusBadRequest)
+			return
+		}
+		requesterDeviceID, err := requesterDeviceIDFromRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := h.deleteSchedule(r.Context(), scheduleID, requesterDeviceID); err != nil {
+			writeDraftScheduleError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleScheduleRSVP serves POST /api/draft/schedules/rsvp.
+func (h *draftHub) handleScheduleRSVP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	requesterDeviceID, err := requesterDeviceIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+	var req rsvpDraftScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json body", http.StatusBadRequest)
+		return
+	}
+	schedule, err := h.rsvpSchedule(r.Context(), requesterDeviceID, req, time.Now())
+	if err != nil {
+		writeDraftScheduleError(w, err)
+		return
+	}
+	writeScheduleView(w, schedule, requesterDeviceID)
+}
+
+// handleScheduleICS serves GET /api/draft/schedules/ics?schedule_id= as a calendar file.
+func (h *draftHub) handleScheduleICS(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	scheduleID := r.URL.Query().Get("schedule_id")
+	if scheduleID == "" {
+		http.Error(w, "schedule_id query param required", http.StatusBadRequest)
+		return
+	}
+	schedule, ok := h.getSchedule(scheduleID)
+	if !ok {
+		http.Error(w, "schedule not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", schedule.ScheduleID+".ics"))
+	_, _ = w.Write([]byte(scheduleICS(schedule, time.Now())))
+}
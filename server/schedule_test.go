@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDraftDecks struct{}
+
+func (fakeDraftDecks) DraftDeck(deckSlug, presetID string) (DraftConfig, []string, error) {
+	if deckSlug != "cube" || presetID != "pod" {
+		return DraftConfig{}, nil, fmt.Errorf("deck %q not found", deckSlug)
+	}
+	deck := make([]string, 0, 8)
+	for i := 0; i < 8; i++ {
+		deck = append(deck, fmt.Sprintf("card-%d", i))
+	}
+	return DraftConfig{PackCount: 2, PackSize: 2, SeatCount: 2}, deck, nil
+}
+
+func useFakeDraftDecks(t *testing.T) {
+	t.Helper()
+	previous := draftDecks
+	draftDecks = fakeDraftDecks{}
+	t.Cleanup(func() { draftDecks = previous })
+}
+
+func TestDraftScheduleOpensWaitingRoomAndPersists(t *testing.T) {
+	useFakeDraftDecks(t)
+	store, err := openDraftRoomStore(filepath.Join(t.TempDir(), "db.sqlite"))
+	require.NoError(t, err, "openDraftRoomStore")
+	defer func() {
+		_ = store.Close()
+	}()
+
+	hub := newDraftHub()
+	hub.setRoomStore(store)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	startsAt := now.Add(time.Hour)
+
+	_, err = hub.createSchedule(context.Background(), "host", createDraftScheduleRequest{DeckSlug: "cube", Preset: "pod", StartsAt: now.Add(-time.Minute)}, now)
+	assert.Error(t, err, "start time must be in the future")
+	_, err = hub.createSchedule(context.Background(), "host", createDraftScheduleRequest{DeckSlug: "cube", Preset: "missing", StartsAt: startsAt}, now)
+	assert.Error(t, err, "unknown preset rejected")
+
+	schedule, err := hub.createSchedule(context.Background(), "host", createDraftScheduleRequest{Title: "Friday cube", DeckSlug: "cube", Preset: "pod", StartsAt: startsAt, HostName: "Ada"}, now)
+	require.NoError(t, err, "createSchedule")
+	assert.Equal(t, 2, schedule.SeatCount, "seat count taken from the preset")
+	assert.Equal(t, defaultScheduleDurationMinutes, schedule.DurationMinutes, "default duration")
+
+	_, err = hub.rsvpSchedule(context.Background(), "p1", rsvpDraftScheduleRequest{ScheduleID: schedule.ScheduleID, DisplayName: "Bo", Attending: true}, now)
+	require.NoError(t, err, "rsvp p1")
+	_, err = hub.rsvpSchedule(context.Background(), "p1", rsvpDraftScheduleRequest{ScheduleID: schedule.ScheduleID, DisplayName: "Bo B", Attending: true}, now)
+	require.NoError(t, err, "rsvp rename is keyed by device")
+	_, err = hub.rsvpSchedule(context.Background(), "p2", rsvpDraftScheduleRequest{ScheduleID: schedule.ScheduleID, DisplayName: "Cy", Attending: true}, now)
+	require.NoError(t, err, "rsvp p2")
+	_, err = hub.rsvpSchedule(context.Background(), "p3", rsvpDraftScheduleRequest{ScheduleID: schedule.ScheduleID, DisplayName: "Di", Attending: true}, now)
+	assert.ErrorIs(t, err, errDraftScheduleFull, "rsvps capped at seat count")
+	updated, err := hub.rsvpSchedule(context.Background(), "p2", rsvpDraftScheduleRequest{ScheduleID: schedule.ScheduleID}, now)
+	require.NoError(t, err, "withdraw rsvp")
+	require.Len(t, updated.RSVPs, 1, "one rsvp left")
+	assert.Equal(t, "Bo B", updated.RSVPs[0].DisplayName, "renamed rsvp kept")
+
+	assert.Empty(t, hub.startDueSchedules(context.Background(), now), "nothing due yet")
+	started := hub.startDueSchedules(context.Background(), startsAt)
+	require.Len(t, started, 1, "schedule opened at its start time")
+	assert.Empty(t, hub.startDueSchedules(context.Background(), startsAt.Add(time.Minute)), "schedule only opens once")
+
+	room := hub.room(started[0])
+	require.NotNil(t, room, "scheduled room registered")
+	summary := room.summary("host")
+	assert.Equal(t, "waiting", summary.State, "scheduled room waits for the host")
+	assert.Equal(t, "cube", summary.DeckSlug, "deck slug")
+	assert.Equal(t, "Ada", summary.HostName, "host name")
+	assert.True(t, summary.OwnedByRequest, "organiser owns the room")
+
+	pack := room.draft.Packs[0][0]
+	_, err = room.draft.PickBatch(0, 1, pack.ID, []PickSelection{{CardName: pack.Cards[0], Zone: PickZoneMainboard}})
+	assert.ErrorIs(t, err, errDraftNotStarted, "picks rejected while waiting")
+
+	assert.ErrorIs(t, hub.startRoom(started[0], "p1"), errDraftRoomForbidden, "only the owner starts the room")
+	require.NoError(t, hub.startRoom(started[0], "host"), "startRoom")
+	assert.Equal(t, "drafting", room.summary("host").State, "room drafting after start")
+	assert.Error(t, hub.startRoom(started[0], "host"), "room cannot start twice")
+
+	restored := newDraftHub()
+	records, err := store.LoadSchedules(context.Background())
+	require.NoError(t, err, "LoadSchedules")
+	restored.restoreSchedules(records)
+	got, ok := restored.getSchedule(schedule.ScheduleID)
+	require.True(t, ok, "schedule restored")
+	assert.Equal(t, started[0], got.RoomID, "room id persisted")
+	assert.Equal(t, "Bo B", got.RSVPs[0].DisplayName, "rsvps persisted")
+}
+
+func TestDraftScheduleRecordsStartError(t *testing.T) {
+	useFakeDraftDecks(t)
+	hub := newDraftHub()
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	hub.restoreSchedules([]draftScheduleRecord{{ScheduleID: "schedule-gone", DeckSlug: "retired", Preset: "pod", StartsAt: now, OwnerDeviceID: "host"}})
+
+	assert.Empty(t, hub.startDueSchedules(context.Background(), now), "missing cube opens no room")
+	got, ok := hub.getSchedule("schedule-gone")
+	require.True(t, ok, "schedule kept")
+	assert.NotEmpty(t, got.StartError, "start error recorded")
+	assert.Equal(t, "failed", scheduleView(got, "").State, "failed state")
+}
+
+func TestDraftScheduleViewHidesDeviceIDs(t *testing.T) {
+	useFakeDraftDecks(t)
+	hub := newDraftHub()
+	startsAt := time.Now().Add(time.Hour)
+	hub.restoreSchedules([]draftScheduleRecord{{
+		ScheduleID:    "schedule-1",
+		Title:         "Cube night",
+		DeckSlug:      "cube",
+		Preset:        "pod",
+		SeatCount:     2,
+		StartsAt:      startsAt,
+		OwnerDeviceID: "host",
+		RSVPs:         []draftScheduleRSVP{{DeviceID: "secret-device", DisplayName: "Bo"}},
+	}})
+
+	rr := httptest.NewRecorder()
+	hub.handleSchedules(rr, httptest.NewRequest(http.MethodGet, withDeviceID("/api/draft/schedules", "secret-device"), nil))
+	require.Equal(t, http.StatusOK, rr.Code, "status: %s", rr.Body.String())
+	assert.NotContains(t, rr.Body.String(), "secret-device", "device ids stay server-side")
+	var resp listDraftSchedulesResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp), "decode schedules")
+	require.Len(t, resp.Schedules, 1, "one schedule")
+	assert.True(t, resp.Schedules[0].AttendingByRequest, "requester sees their rsvp")
+	assert.Equal(t, "scheduled", resp.Schedules[0].State, "scheduled state")
+
+	del := httptest.NewRecorder()
+	hub.handleSchedules(del, httptest.NewRequest(http.MethodDelete, withDeviceID("/api/draft/schedules?schedule_id=schedule-1", "secret-device"), nil))
+	assert.Equal(t, http.StatusForbidden, del.Code, "only the organiser deletes")
+}
+
+func TestScheduleICS(t *testing.T) {
+	schedule := draftScheduleRecord{
+		ScheduleID:      "schedule-abc",
+		Title:           "Cube; night, v2",
+		DeckSlug:        "cube",
+		Preset:          "pod",
+		SeatCount:       8,
+		StartsAt:        time.Date(2026, 10, 23, 18, 30, 0, 0, time.UTC),
+		DurationMinutes: 90,
+		HostName:        "Ada",
+		RSVPs:           []draftScheduleRSVP{{DisplayName: "Bo"}, {DisplayName: "Ćy Ünïcødé " + strings.Repeat("é", 40)}},
+	}
+	ics := scheduleICS(schedule, time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC))
+
+	assert.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"), "calendar header")
+	assert.True(t, strings.HasSuffix(ics, "END:VEVENT\r\nEND:VCALENDAR\r\n"), "calendar footer")
+	assert.Contains(t, ics, "UID:schedule-abc@battlebox\r\n", "uid")
+	assert.Contains(t, ics, "DTSTART:20261023T183000Z\r\n", "start time")
+	assert.Contains(t, ics, "DTEND:20261023T200000Z\r\n", "end time")
+	assert.Contains(t, ics, `SUMMARY:Cube\; night\, v2`+"\r\n", "summary escaped")
+
+	for _, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, "line folded: %q", line)
+		assert.True(t, utf8.ValidString(line), "fold keeps utf-8 intact: %q", line)
+	}
+	unfolded := strings.ReplaceAll(ics, "\r\n ", "")
+	assert.Contains(t, unfolded, `Hosted by Ada.\nAttending: Bo\, Ćy Ünïcødé `, "description unfolds")
+}
+
+func TestBuiltDraftDecksReadsPresets(t *testing.T) {
+	dir := t.TempDir()
+	payload := `{"slug":"cube","presets":{"pod":{"seat_count":2,"pack_count":1,"pack_size":3}},` +
+		`"decks":[{"slug":"vintage","draft_presets":["pod"],"cards":[{"name":"Black Lotus","qty":1},{"name":"Island","qty":2}]}]}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cube.json"), []byte(payload), 0o644), "write cube.json")
+
+	source := &builtDraftDecks{built: newBuiltBattleboxes(dir)}
+	cfg, deck, err := source.DraftDeck("vintage", "pod")
+	require.NoError(t, err, "DraftDeck")
+	assert.Equal(t, DraftConfig{SeatCount: 2, PackCount: 1, PackSize: 3}, cfg, "config from preset")
+	assert.Equal(t, []string{"Black Lotus", "Island", "Island"}, deck, "deck expanded by qty")
+
+	_, _, err = source.DraftDeck("vintage", "other")
+	assert.Error(t, err, "preset must be enabled for the deck")
+	_, _, err = source.DraftDeck("missing", "pod")
+	assert.Error(t, err, "unknown deck")
+}
@@ -10,52 +10,59 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/lxing/battlebox/draftproto"
 )
 
-type createDraftRoomRequest struct {
-	Deck        []string `json:"deck"`
-	DeckSlug    string   `json:"deck_slug,omitempty"`
-	SeatCount   int      `json:"seat_count"`
-	PackCount   int      `json:"pack_count"`
-	PackSize    int      `json:"pack_size"`
-	PassPattern []int    `json:"pass_pattern,omitempty"`
-}
-
-type createDraftRoomResponse struct {
-	RoomID  string `json:"room_id"`
-	Created bool   `json:"created"`
-}
+// Room creation and websocket payloads are defined by the public draftproto package.
+type (
+	createDraftRoomRequest  = draftproto.CreateRoomRequest
+	createDraftRoomResponse = draftproto.CreateRoomResponse
+	draftWSMessage          = draftproto.Message
+)
 
 type deleteDraftRoomResponse struct {
 	RoomID  string `json:"room_id"`
 	Deleted bool   `json:"deleted"`
 }
 
-type draftWSMessage struct {
-	Type     string          `json:"type"`
-	Seq      uint64          `json:"seq,omitempty"`
-	PackID   string          `json:"pack_id,omitempty"`
-	CardName string          `json:"card_name,omitempty"`
-	Zone     string          `json:"zone,omitempty"`
-	FromZone string          `json:"from_zone,omitempty"`
-	ToZone   string          `json:"to_zone,omitempty"`
-	Basics   map[string]int  `json:"basics,omitempty"`
-	Picks    []PickSelection `json:"picks,omitempty"`
-	Error    string          `json:"error,omitempty"`
-	Redirect string          `json:"redirect,omitempty"`
-
-	State     *PlayerState `json:"state,omitempty"`
-	Duplicate bool         `json:"duplicate,omitempty"`
-	PackNo    int          `json:"pack_no,omitempty"`
-	PickNo    int          `json:"pick_no,omitempty"`
-}
-
 var wsUpgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool { return true },
 }
 
 var errInvalidDraftCreateJSON = errors.New("invalid json body")
 
+// wsErrorMessage converts a draft error to an error frame with a stable code.
+func wsErrorMessage(err error) draftWSMessage {
+	return draftWSMessage{Type: draftproto.TypeError, Error: err.Error(), Code: wsErrorCode(err)}
+}
+
+func wsErrorCode(err error) draftproto.ErrorCode {
+	switch {
+	case errors.Is(err, errInvalidSeat):
+		return draftproto.CodeInvalidSeat
+	case errors.Is(err, errInvalidSeq):
+		return draftproto.CodeInvalidSeq
+	case errors.Is(err, errStaleSeq):
+		return draftproto.CodeStaleSeq
+	case errors.Is(err, errSeqGap):
+		return draftproto.CodeSeqGap
+	case errors.Is(err, errPackMismatch):
+		return draftproto.CodePackMismatch
+	case errors.Is(err, errSeatAlreadyPicked):
+		return draftproto.CodeAlreadyPicked
+	case errors.Is(err, errDraftComplete):
+		return draftproto.CodeDraftComplete
+	case errors.Is(err, errDraftNotComplete):
+		return draftproto.CodeDraftNotComplete
+	case errors.Is(err, errDraftNotStarted):
+		return draftproto.CodeDraftNotStarted
+	case errors.Is(err, errDeckLocked):
+		return draftproto.CodeDeckLocked
+	default:
+		return draftproto.CodeRejected
+	}
+}
+
 func draftConfigFromRequest(req createDraftRoomRequest) (DraftConfig, error) {
 	if req.SeatCount <= 0 {
 		return DraftConfig{}, errors.New("seat_count must be > 0")
@@ -66,11 +73,21 @@ func draftConfigFromRequest(req createDraftRoomRequest) (DraftConfig, error) {
 	if req.PackSize <= 0 {
 		return DraftConfig{}, errors.New("pack_size must be > 0")
 	}
+	if req.DeckSize < 0 {
+		return DraftConfig{}, errors.New("deck_size must be >= 0")
+	}
+	seed := randomDraftSeed()
+	if req.Seed != nil {
+		seed = *req.Seed
+	}
 	return DraftConfig{
 		PackCount:   req.PackCount,
 		PackSize:    req.PackSize,
 		SeatCount:   req.SeatCount,
 		PassPattern: append([]int(nil), req.PassPattern...),
+		Seed:        seed,
+		TeamDraft:   req.TeamDraft,
+		DeckSize:    req.DeckSize,
 	}, nil
 }
 
@@ -94,16 +111,31 @@ func draftRoomFromRequest(r *http.Request, roomID string) (*draftRoom, string, e
 		return nil, "", err
 	}
 
+	now := time.Now()
 	room := &draftRoom{
 		id:            roomID,
 		deckSlug:      normalizeSlug(req.DeckSlug),
 		ownerDeviceID: requesterDeviceID,
+		hostName:      normalizeHostName(req.HostName),
 		draft:         draft,
 		clients:       make(map[int]map[*websocket.Conn]struct{}),
+		createdAt:     now,
+		updatedAt:     now,
 	}
 	return room, requesterDeviceID, nil
 }
 
+// maxHostNameRunes bounds the host display name shown in the lobby.
+const maxHostNameRunes = 32
+
+func normalizeHostName(raw string) string {
+	name := strings.Join(strings.Fields(raw), " ")
+	if runes := []rune(name); len(runes) > maxHostNameRunes {
+		name = string(runes[:maxHostNameRunes])
+	}
+	return name
+}
+
 func isValidDeviceID(value string) bool {
 	if value == "" || len(value) > 128 {
 		return false
@@ -170,7 +202,7 @@ func (h *draftHub) handleCreateRoom(w http.ResponseWriter, r *http.Request) {
 	room.id = h.nextRoomIDLocked()
 	h.rooms[room.id] = room
 	h.mu.Unlock()
-	h.notifyLobbySubscribers()
+	h.notifyLobbySubscribers(room.id)
 
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(createDraftRoomResponse{RoomID: room.id, Created: true})
@@ -205,6 +237,68 @@ func (h *draftHub) handleDeleteRoom(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(deleteDraftRoomResponse{RoomID: roomID, Deleted: true})
 }
 
+// startRoom opens a waiting room for picks. Only the room owner may start it.
+func (h *draftHub) startRoom(roomID, requesterDeviceID string) error {
+	room := h.room(roomID)
+	if room == nil {
+		return errDraftRoomNotFound
+	}
+	if room.ownerDeviceID == "" || room.ownerDeviceID != requesterDeviceID {
+		return errDraftRoomForbidden
+	}
+	room.mu.Lock()
+	if room.closed {
+		room.mu.Unlock()
+		return errDraftRoomNotFound
+	}
+	if err := room.draft.Start(); err != nil {
+		room.mu.Unlock()
+		return err
+	}
+	room.broadcastSeatStates()
+	room.mu.Unlock()
+	h.notifyLobbySubscribers(roomID)
+	return nil
+}
+
+// handleStartRoom serves POST /api/draft/rooms/start?room_id= for waiting rooms.
+func (h *draftHub) handleStartRoom(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	roomID := r.URL.Query().Get("room_id")
+	if roomID == "" {
+		http.Error(w, "room_id query param required", http.StatusBadRequest)
+		return
+	}
+	requesterDeviceID, err := requesterDeviceIDFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.startRoom(roomID, requesterDeviceID); err != nil {
+		switch {
+		case errors.Is(err, errDraftRoomNotFound):
+			http.Error(w, "room not found", http.StatusNotFound)
+		case errors.Is(err, errDraftRoomForbidden):
+			http.Error(w, "only the creator may start this room", http.StatusForbidden)
+		default:
+			http.Error(w, err.Error(), http.StatusConflict)
+		}
+		return
+	}
+
+	room := h.room(roomID)
+	if room == nil {
+		http.Error(w, "room not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(room.summary(requesterDeviceID))
+}
+
 func (h *draftHub) handleStartOrJoinSharedRoom(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		w.Header().Set("Allow", "POST")
@@ -242,7 +336,7 @@ func (h *draftHub) handleStartOrJoinSharedRoom(w http.ResponseWriter, r *http.Re
 	if h.rooms[sharedRoomID] == nil {
 		h.rooms[sharedRoomID] = room
 		h.mu.Unlock()
-		h.notifyLobbySubscribers()
+		h.notifyLobbySubscribers(sharedRoomID)
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(createDraftRoomResponse{RoomID: sharedRoomID, Created: true})
 		return
@@ -280,16 +374,18 @@ func (h *draftHub) handleWS(w http.ResponseWriter, r *http.Request) {
 	h.mu.RUnlock()
 	if roomForSeatCheck == nil {
 		_ = conn.WriteJSON(draftWSMessage{
-			Type:     "room_missing",
+			Type:     draftproto.TypeRoomMissing,
 			Error:    "Room not found",
+			Code:     draftproto.CodeRoomMissing,
 			Redirect: "#/cube",
 		})
 		return
 	}
 	if seat < 0 || seat >= seatCount {
 		_ = conn.WriteJSON(draftWSMessage{
-			Type:     "room_missing",
+			Type:     draftproto.TypeRoomMissing,
 			Error:    "Room not found",
+			Code:     draftproto.CodeRoomMissing,
 			Redirect: "#/cube",
 		})
 		return
@@ -298,24 +394,26 @@ func (h *draftHub) handleWS(w http.ResponseWriter, r *http.Request) {
 	room, accepted := h.addConnToRoomIfPresent(roomID, seat, conn)
 	if room == nil {
 		_ = conn.WriteJSON(draftWSMessage{
-			Type:     "room_missing",
+			Type:     draftproto.TypeRoomMissing,
 			Error:    "Room not found",
+			Code:     draftproto.CodeRoomMissing,
 			Redirect: "#/cube",
 		})
 		return
 	}
 	if !accepted {
 		room.writeToConn(conn, draftWSMessage{
-			Type:     "seat_occupied",
+			Type:     draftproto.TypeSeatOccupied,
 			Error:    "Seat already occupied",
+			Code:     draftproto.CodeSeatOccupied,
 			Redirect: "#/cube",
 		})
 		return
 	}
-	h.notifyLobbySubscribers()
+	h.notifyLobbySubscribers(roomID)
 	defer func() {
 		room.removeConn(seat, conn)
-		h.notifyLobbySubscribers()
+		h.notifyLobbySubscribers(roomID)
 	}()
 
 	room.sendSeatState(seat, conn)
@@ -326,20 +424,34 @@ func (h *draftHub) handleWS(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		switch msg.Type {
-		case "state":
+		case draftproto.TypeHello:
+			if !room.handleHello(conn, msg) {
+				return
+			}
+		case draftproto.TypeState:
 			room.sendSeatState(seat, conn)
-		case "pick":
+		case draftproto.TypePick:
 			if room.handlePick(seat, conn, msg) {
-				h.notifyLobbySubscribers()
+				h.notifyLobbySubscribers(roomID)
 			}
-		case "move_pick":
+		case draftproto.TypeMovePick:
 			room.handleMovePick(seat, conn, msg)
-		case "set_basics":
+		case draftproto.TypeSetBasics:
 			room.handleSetBasics(seat, conn, msg)
-		case "bot_pick":
+		case draftproto.TypeRegisterDeck:
+			room.handleRegisterDeck(seat, conn, msg)
+		case draftproto.TypeSuggestBasics:
+			room.handleSuggestBasics(seat, conn)
+		case draftproto.TypeRetractPick:
+			if room.handleRetractPick(seat, conn, msg) {
+				h.notifyLobbySubscribers(roomID)
+			}
+		case draftproto.TypeBotPick:
 			if room.handleBotPick(seat) {
-				h.notifyLobbySubscribers()
+				h.notifyLobbySubscribers(roomID)
 			}
+		case draftproto.TypeOverlayToken:
+			room.handleOverlayToken(seat, conn)
 		default:
 			// Ignore unknown client messages to keep write paths serialized through room handlers.
 			// This avoids concurrent writes to the same websocket connection.
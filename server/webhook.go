@@ -0,0 +1,354 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Outgoing webhooks announce draft outcomes to chat integrations. Room lifecycle
+// events are derived in notifyLobbySubscribers from each room's state change;
+// results are sent when an event match is reported.
+
+const (
+	webhookRoomCreated     = "room_created"
+	webhookDraftStarted    = "draft_started"
+	webhookDraftCompleted  = "draft_completed"
+	webhookResultsReported = "results_reported"
+)
+
+var webhookEventTypes = []string{webhookRoomCreated, webhookDraftStarted, webhookDraftCompleted, webhookResultsReported}
+
+const (
+	webhookFormatJSON    = "json"
+	webhookFormatDiscord = "discord"
+
+	defaultWebhookMaxAttempts = 3
+	defaultWebhookBackoff     = time.Second
+	webhookRequestTimeout     = 10 * time.Second
+)
+
+// webhookConfig is one entry of the WEBHOOKS_PATH JSON file.
+type webhookConfig struct {
+	URL    string `json:"url"`
+	Format string `json:"format,omitempty"` // json (default) or discord
+	// Secret signs each delivery; receivers verify X-Battlebox-Signature.
+	Secret string `json:"secret,omitempty"`
+	// Events limits deliveries to these event types; empty sends every event.
+	Events      []string `json:"events,omitempty"`
+	MaxAttempts int      `json:"max_attempts,omitempty"`
+}
+
+type webhookRoom struct {
+	RoomID    string `json:"room_id"`
+	DeckSlug  string `json:"deck_slug,omitempty"`
+	HostName  string `json:"host_name,omitempty"`
+	State     string `json:"state"`
+	SeatCount int    `json:"seat_count"`
+	PackCount int    `json:"pack_count"`
+	PackSize  int    `json:"pack_size"`
+}
+
+type webhookResult struct {
+	EventID   string    `json:"event_id"`
+	EventName string    `json:"event_name"`
+	DeckSlug  string    `json:"deck_slug"`
+	Round     int       `json:"round"`
+	Players   [2]string `json:"players"` // display names
+	Wins      [2]int    `json:"wins"`
+	Draws     int       `json:"draws,omitempty"`
+}
+
+type webhookPayload struct {
+	Event      string         `json:"event"`
+	OccurredAt time.Time      `json:"occurred_at"`
+	Room       *webhookRoom   `json:"room,omitempty"`
+	Result     *webhookResult `json:"result,omitempty"`
+}
+
+type discordWebhookPayload struct {
+	Content string `json:"content"`
+}
+
+// webhookDispatcher delivers payloads asynchronously with retries.
+type webhookDispatcher struct {
+	hooks   []webhookConfig
+	client  *http.Client
+	backoff time.Duration // delay before the second attempt, doubled after each retry
+
+	wg sync.WaitGroup
+}
+
+func newWebhookDispatcher(hooks []webhookConfig) *webhookDispatcher {
+	return &webhookDispatcher{
+		hooks:   hooks,
+		client:  &http.Client{Timeout: webhookRequestTimeout},
+		backoff: defaultWebhookBackoff,
+	}
+}
+
+// loadWebhookConfigs reads and validates the webhook config file.
+func loadWebhookConfigs(path string) ([]webhookConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read webhooks: %w", err)
+	}
+	var hooks []webhookConfig
+	if err := json.Unmarshal(data, &hooks); err != nil {
+		return nil, fmt.Errorf("parse webhooks: %w", err)
+	}
+	for i := range hooks {
+		hook := &hooks[i]
+		parsed, err := url.Parse(hook.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("webhook %d: url must be http(s)", i)
+		}
+		switch hook.Format {
+		case "":
+			hook.Format = webhookFormatJSON
+		case webhookFormatJSON, webhookFormatDiscord:
+		default:
+			return nil, fmt.Errorf("webhook %d: unknown format %q", i, hook.Format)
+		}
+		for _, event := range hook.Events {
+			if !slices.Contains(webhookEventTypes, event) {
+				return nil, fmt.Errorf("webhook %d: unknown event %q", i, event)
+			}
+		}
+		if hook.MaxAttempts < 0 {
+			return nil, fmt.Errorf("webhook %d: max_attempts must be >= 0", i)
+		}
+		if hook.MaxAttempts == 0 {
+			hook.MaxAttempts = defaultWebhookMaxAttempts
+		}
+	}
+	return hooks, nil
+}
+
+func (h *draftHub) setWebhooks(dispatcher *webhookDispatcher) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.webhooks = dispatcher
+}
+
+// roomWebhookEventsLocked compares the room state with the last one announced
+// and returns the lifecycle events to send. Callers hold draftHub.mu.
+func (r *draftRoom) roomWebhookEventsLocked(now time.Time) []webhookPayload {
+	r.mu.Lock()
+	state := r.draft.State()
+	room := &webhookRoom{
+		RoomID:    r.id,
+		DeckSlug:  r.deckSlug,
+		HostName:  r.hostName,
+		State:     state,
+		SeatCount: r.draft.Config.SeatCount,
+		PackCount: r.draft.Config.PackCount,
+		PackSize:  r.draft.Config.PackSize,
+	}
+	r.mu.Unlock()
+
+	previous := r.webhookState
+	if previous == state {
+		return nil
+	}
+	r.webhookState = state
+	var events []webhookPayload
+	if previous == "" {
+		events = append(events, webhookPayload{Event: webhookRoomCreated, OccurredAt: now, Room: room})
+	}
+	if state != "waiting" && (previous == "" || previous == "waiting") {
+		events = append(events, webhookPayload{Event: webhookDraftStarted, OccurredAt: now, Room: room})
+	}
+	if state == "done" {
+		events = append(events, webhookPayload{Event: webhookDraftCompleted, OccurredAt: now, Room: room})
+	}
+	return events
+}
+
+// announceMatchResult sends the most recently reported match of an event.
+func (h *draftHub) announceMatchResult(event draftEventRecord) {
+	h.mu.RLock()
+	dispatcher := h.webhooks
+	h.mu.RUnlock()
+	if dispatcher == nil || len(event.Matches) == 0 {
+		return
+	}
+	match := event.Matches[len(event.Matches)-1]
+	result := &webhookResult{
+		EventID:   event.EventID,
+		EventName: event.Name,
+		DeckSlug:  event.DeckSlug,
+		Round:     match.Round,
+		Wins:      match.Wins,
+		Draws:     match.Draws,
+	}
+	for i, playerID := range match.Players {
+		result.Players[i] = playerID
+		if idx := eventPlayerIndex(event, playerID); idx >= 0 {
+			result.Players[i] = event.Players[idx].Name
+		}
+	}
+	dispatcher.dispatch(webhookPayload{Event: webhookResultsReported, OccurredAt: time.Now(), Result: result})
+}
+
+// dispatch queues payload for every subscribed hook and returns immediately.
+func (d *webhookDispatcher) dispatch(payload webhookPayload) {
+	if d == nil {
+		return
+	}
+	for _, hook := range d.hooks {
+		if len(hook.Events) > 0 && !slices.Contains(hook.Events, payload.Event) {
+			continue
+		}
+		body, err := webhookBody(hook.Format, payload)
+		if err != nil {
+			log.Printf("Failed to encode %s webhook: %v", payload.Event, err)
+			continue
+		}
+		d.wg.Add(1)
+		go func(hook webhookConfig) {
+			defer d.wg.Done()
+			if err := d.deliver(context.Background(), hook, payload.Event, body); err != nil {
+				log.Printf("Failed to deliver %s webhook to %s: %v", payload.Event, hook.URL, err)
+			}
+		}(hook)
+	}
+}
+
+// wait blocks until in-flight deliveries finish.
+func (d *webhookDispatcher) wait() {
+	d.wg.Wait()
+}
+
+// deliver posts body, retrying transport errors, 429s and 5xx responses with
+// exponential backoff. Other 4xx responses are not retried.
+func (d *webhookDispatcher) deliver(ctx context.Context, hook webhookConfig, event string, body []byte) error {
+	attempts := hook.MaxAttempts
+	if attempts <= 0 {
+		attempts = defaultWebhookMaxAttempts
+	}
+	delay := d.backoff
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+		retry, err := d.post(ctx, hook, event, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return lastErr
+}
+
+func (d *webhookDispatcher) post(ctx context.Context, hook webhookConfig, event string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Battlebox-Event", event)
+	req.Header.Set("X-Battlebox-Timestamp", timestamp)
+	if hook.Secret != "" {
+		req.Header.Set("X-Battlebox-Signature", "sha256="+signWebhook(hook.Secret, timestamp, body))
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	_ = resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("status %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("status %d", resp.StatusCode)
+	}
+}
+
+// signWebhook returns the hex HMAC-SHA256 of "<timestamp>.<body>". Including the
+// timestamp lets receivers reject replayed deliveries.
+func signWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func webhookBody(format string, payload webhookPayload) ([]byte, error) {
+	if format == webhookFormatDiscord {
+		return json.Marshal(discordWebhookPayload{Content: discordWebhookContent(payload)})
+	}
+	return json.Marshal(payload)
+}
+
+func discordWebhookContent(payload webhookPayload) string {
+	if payload.Result != nil {
+		r := payload.Result
+		line := fmt.Sprintf("**%s** round %d: %s %d-%d %s", r.EventName, r.Round, r.Players[0], r.Wins[0], r.Wins[1], r.Players[1])
+		if r.Draws > 0 {
+			line += fmt.Sprintf("-%d", r.Draws)
+		}
+		return line
+	}
+	if payload.Room == nil {
+		return payload.Event
+	}
+	room := payload.Room
+	subject := fmt.Sprintf("Draft room **%s**", room.RoomID)
+	if room.DeckSlug != "" {
+		subject += " (" + room.DeckSlug + ")"
+	}
+	switch payload.Event {
+	case webhookRoomCreated:
+		line := fmt.Sprintf("%s opened with %d seats, %d packs of %d", subject, room.SeatCount, room.PackCount, room.PackSize)
+		if room.HostName != "" {
+			line += ", hosted by " + room.HostName
+		}
+		return line + "."
+	case webhookDraftStarted:
+		return subject + " started drafting."
+	case webhookDraftCompleted:
+		return subject + " finished drafting."
+	default:
+		return strings.TrimSpace(subject + " " + payload.Event)
+	}
+}
+
+// webhooksFromEnv loads WEBHOOKS_PATH. It returns nil when webhooks are not configured.
+func webhooksFromEnv() (*webhookDispatcher, error) {
+	path := strings.TrimSpace(os.Getenv("WEBHOOKS_PATH"))
+	if path == "" {
+		return nil, nil
+	}
+	hooks, err := loadWebhookConfigs(path)
+	if err != nil {
+		return nil, err
+	}
+	return newWebhookDispatcher(hooks), nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+// webhookReceiver is a local stand-in for a chat webhook endpoint. statuses are
+// returned in order for successive requests, then 204.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []recordedWebhook
+}
+
+func (rec *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rec.mu.Lock()
+	rec.requests = append(rec.requests, recordedWebhook{header: r.Header.Clone(), body: body})
+	status := http.StatusNoContent
+	if len(rec.statuses) > 0 {
+		status = rec.statuses[0]
+		rec.statuses = rec.statuses[1:]
+	}
+	rec.mu.Unlock()
+	w.WriteHeader(status)
+}
+
+func (rec *webhookReceiver) received() []recordedWebhook {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append([]recordedWebhook(nil), rec.requests...)
+}
+
+func newTestWebhookDispatcher(hooks ...webhookConfig) *webhookDispatcher {
+	dispatcher := newWebhookDispatcher(hooks)
+	dispatcher.backoff = time.Millisecond
+	return dispatcher
+}
+
+func TestWebhooksFollowRoomLifecycle(t *testing.T) {
+	receiver := &webhookReceiver{}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	hub := newDraftHub()
+	dispatcher := newTestWebhookDispatcher(webhookConfig{URL: srv.URL, Format: webhookFormatJSON, Secret: "s3cret", MaxAttempts: 1})
+	hub.setWebhooks(dispatcher)
+
+	room := addTestRoom(t, hub, "room-a", DraftConfig{PackCount: 1, PackSize: 1, SeatCount: 2})
+	room.deckSlug = "cube"
+	hub.notifyLobbySubscribers("room-a")
+	hub.notifyLobbySubscribers("room-a")
+	for seat := 0; seat < 2; seat++ {
+		st, err := room.draft.PlayerState(seat)
+		require.NoError(t, err, "PlayerState")
+		_, err = room.draft.Pick(seat, 1, st.Active.PackID, st.Active.Cards[0], PickZoneMainboard)
+		require.NoError(t, err, "pick")
+	}
+	hub.notifyLobbySubscribers("room-a")
+	dispatcher.wait()
+
+	requests := receiver.received()
+	events := make([]string, 0, len(requests))
+	for _, req := range requests {
+		var payload webhookPayload
+		require.NoError(t, json.Unmarshal(req.body, &payload), "decode payload")
+		require.NotNil(t, payload.Room, "room payload")
+		assert.Equal(t, "room-a", payload.Room.RoomID, "room id")
+		assert.Equal(t, "cube", payload.Room.DeckSlug, "deck slug")
+		events = append(events, payload.Event)
+
+		timestamp := req.header.Get("X-Battlebox-Timestamp")
+		assert.Equal(t, payload.Event, req.header.Get("X-Battlebox-Event"), "event header")
+		assert.Equal(t, "sha256="+signWebhook("s3cret", timestamp, req.body), req.header.Get("X-Battlebox-Signature"), "signature")
+	}
+	sort.Strings(events)
+	assert.Equal(t, []string{webhookDraftCompleted, webhookDraftStarted, webhookRoomCreated}, events, "each lifecycle event sent once")
+}
+
+func TestWebhooksStartWaitingRoomOnce(t *testing.T) {
+	receiver := &webhookReceiver{}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	hub := newDraftHub()
+	dispatcher := newTestWebhookDispatcher(webhookConfig{URL: srv.URL, Events: []string{webhookDraftStarted}, MaxAttempts: 1})
+	hub.setWebhooks(dispatcher)
+
+	room := addTestRoom(t, hub, "room-a", DraftConfig{PackCount: 1, PackSize: 1, SeatCount: 2})
+	room.ownerDeviceID = "host"
+	room.draft.waiting = true
+	hub.notifyLobbySubscribers("room-a")
+	dispatcher.wait()
+	assert.Empty(t, receiver.received(), "waiting room has not started")
+
+	require.NoError(t, hub.startRoom("room-a", "host"), "startRoom")
+	dispatcher.wait()
+	require.Len(t, receiver.received(), 1, "draft_started sent when the host starts")
+}
+
+func TestWebhookRetriesAndDiscordFormat(t *testing.T) {
+	receiver := &webhookReceiver{statuses: []int{http.StatusBadGateway, http.StatusTooManyRequests}}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	hub := newDraftHub()
+	dispatcher := newTestWebhookDispatcher(webhookConfig{URL: srv.URL, Format: webhookFormatDiscord, MaxAttempts: 3})
+	hub.setWebhooks(dispatcher)
+
+	hub.announceMatchResult(draftEventRecord{
+		EventID: "event-1",
+		Name:    "Friday",
+		Players: []draftEventPlayer{{PlayerID: "ada", Name: "Ada"}, {PlayerID: "bo", Name: "Bo"}},
+		Matches: []draftEventMatch{{Round: 2, Players: [2]string{"ada", "bo"}, Wins: [2]int{2, 1}}},
+	})
+	dispatcher.wait()
+
+	requests := receiver.received()
+	require.Len(t, requests, 3, "retried until success")
+	var payload discordWebhookPayload
+	require.NoError(t, json.Unmarshal(requests[2].body, &payload), "decode discord payload")
+	assert.Equal(t, "**Friday** round 2: Ada 2-1 Bo", payload.Content, "discord content")
+	assert.Empty(t, requests[2].header.Get("X-Battlebox-Signature"), "unsigned without a secret")
+}
+
+func TestWebhookDoesNotRetryClientErrors(t *testing.T) {
+	receiver := &webhookReceiver{statuses: []int{http.StatusBadRequest}}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	dispatcher := newTestWebhookDispatcher(webhookConfig{URL: srv.URL, Format: webhookFormatJSON, MaxAttempts: 3})
+	dispatcher.dispatch(webhookPayload{Event: webhookRoomCreated, Room: &webhookRoom{RoomID: "room-a"}})
+	dispatcher.wait()
+	assert.Len(t, receiver.received(), 1, "4xx is not retried")
+}
+
+func TestLoadWebhookConfigs(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, raw string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(raw), 0o644), "write %s", name)
+		return path
+	}
+
+	hooks, err := loadWebhookConfigs(write("ok.json", `[{"url":"https://chat.example/hook","events":["draft_completed"]},{"url":"http://localhost:9000","format":"discord","max_attempts":5}]`))
+	require.NoError(t, err, "loadWebhookConfigs")
+	require.Len(t, hooks, 2, "hooks")
+	assert.Equal(t, webhookFormatJSON, hooks[0].Format, "json is the default format")
+	assert.Equal(t, defaultWebhookMaxAttempts, hooks[0].MaxAttempts, "default attempts")
+	assert.Equal(t, 5, hooks[1].MaxAttempts, "configured attempts")
+
+	_, err = loadWebhookConfigs(write("scheme.json", `[{"url":"ftp://chat.example"}]`))
+	assert.Error(t, err, "non-http url rejected")
+	_, err = loadWebhookConfigs(write("format.json", `[{"url":"https://chat.example","format":"slack"}]`))
+	assert.Error(t, err, "unknown format rejected")
+	_, err = loadWebhookConfigs(write("event.json", `[{"url":"https://chat.example","events":["room_deleted"]}]`))
+	assert.Error(t, err, "unknown event rejected")
+}
@@ -1097,7 +1097,7 @@ export function createDraftController({
       basicsButton.disabled = draftUi.pendingDeckMutation || !draftUi.connected;
     }
     if (botButton) {
-      botButton.disabled = !draftUi.connected || state.state === 'done';
+      botButton.disabled = !draftUi.connected || state.state !== 'drafting';
     }
     syncBasicsDialogUi();
 
@@ -1108,9 +1108,13 @@ export function createDraftController({
       draftUi.selectedPackZones.clear();
       if (packEmptyEl) {
         packEmptyEl.hidden = false;
-        packEmptyEl.textContent = state.state === 'done'
-          ? 'Draft complete.'
-          : 'Waiting for next pack...';
+        if (state.state === 'done') {
+          packEmptyEl.textContent = 'Draft complete.';
+        } else if (state.state === 'waiting') {
+          packEmptyEl.textContent = 'Waiting for the host to start the draft...';
+        } else {
+          packEmptyEl.textContent = 'Waiting for next pack...';
+        }
       }
       if (packContentEl) packContentEl.hidden = true;
       updatePackScrollIndicators();
@@ -1119,7 +1123,7 @@ export function createDraftController({
         pickButton.disabled = true;
       }
       if (botButton) {
-        botButton.disabled = !draftUi.connected || state.state === 'done';
+        botButton.disabled = !draftUi.connected || state.state !== 'drafting';
       }
       syncSideboardModeUi();
       return;
@@ -1148,7 +1152,7 @@ export function createDraftController({
       pickButton.textContent = formatPickButtonLabel(ctx?.expectedPicks);
     }
     if (botButton) {
-      botButton.disabled = !draftUi.connected || state.state === 'done';
+      botButton.disabled = !draftUi.connected || state.state !== 'drafting';
     }
     if (packEmptyEl) packEmptyEl.hidden = true;
     if (packContentEl) packContentEl.hidden = false;
@@ -1246,6 +1250,8 @@ export function createDraftController({
         || msg.type === 'pick_accepted'
         || msg.type === 'move_accepted'
         || msg.type === 'set_basics_accepted'
+        || msg.type === 'retract_accepted'
+        || msg.type === 'register_deck_accepted'
       ) {
         if (msg.state) {
           draftUi.state = msg.state;
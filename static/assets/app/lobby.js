@@ -315,6 +315,25 @@ export function createLobbyController({
         // ignore malformed events
       }
     };
+    const applyRoomEvent = (event, remove) => {
+      try {
+        const payload = JSON.parse(event.data);
+        const roomID = String((remove ? payload?.room_id : payload?.room?.room_id) || '').trim();
+        if (!roomID) return;
+        const rooms = new Map(state.roomByID);
+        if (remove) {
+          rooms.delete(roomID);
+        } else {
+          rooms.set(roomID, payload.room);
+        }
+        renderRooms([...rooms.values()].sort((a, b) => String(a.room_id).localeCompare(String(b.room_id))));
+      } catch (_) {
+        // ignore malformed events
+      }
+    };
+    source.addEventListener('room_created', (event) => applyRoomEvent(event, false));
+    source.addEventListener('room_updated', (event) => applyRoomEvent(event, false));
+    source.addEventListener('room_deleted', (event) => applyRoomEvent(event, true));
     source.onerror = () => {
       // Browser will auto-reconnect EventSource.
     };